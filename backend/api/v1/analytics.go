@@ -0,0 +1,88 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/myideascope/HomeGenie/backend/internal/analytics"
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+	"github.com/myideascope/HomeGenie/backend/pkg/openapi"
+)
+
+// AnalyticsHandler serves dashboard, task and property statistics
+type AnalyticsHandler struct {
+	service *analytics.Service
+}
+
+// NewAnalyticsHandler creates an analytics handler
+func NewAnalyticsHandler(service *analytics.Service) *AnalyticsHandler {
+	return &AnalyticsHandler{service: service}
+}
+
+// RegisterRoutes mounts the analytics endpoints on an authenticated group
+func (h *AnalyticsHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	group := rg.Group("/analytics")
+	group.GET("/dashboard", h.Dashboard)
+	group.GET("/tasks", h.Tasks)
+	group.GET("/properties", h.Properties)
+}
+
+// analyticsOperations documents the analytics endpoints in the OpenAPI document
+var analyticsOperations = []openapi.Operation{
+	{Method: http.MethodGet, Path: "/analytics/dashboard", Summary: "Get dashboard statistics", Tag: "Analytics",
+		Auth: true, Response: database.APIResponse[database.DashboardStats]{}},
+	{Method: http.MethodGet, Path: "/analytics/tasks", Summary: "Get task statistics for a period", Tag: "Analytics",
+		Auth: true, Query: database.AnalyticsFilters{}, Response: database.APIResponse[database.TaskAnalytics]{}},
+	{Method: http.MethodGet, Path: "/analytics/properties", Summary: "Get maintenance spend and task statistics",
+		Tag: "Analytics", Auth: true, Response: database.APIResponse[database.PropertyAnalytics]{}},
+}
+
+// Dashboard returns the current user's summary statistics
+func (h *AnalyticsHandler) Dashboard(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.service.Dashboard(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respond(c, http.StatusOK, stats, "")
+}
+
+// Tasks returns task statistics for the requested period, a month by default
+func (h *AnalyticsHandler) Tasks(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var filters database.AnalyticsFilters
+	if !bindQuery(c, &filters) {
+		return
+	}
+
+	stats, err := h.service.Tasks(c.Request.Context(), userID, filters.Period)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respond(c, http.StatusOK, stats, "")
+}
+
+// Properties returns maintenance spend and task statistics per property
+func (h *AnalyticsHandler) Properties(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.service.Properties(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respond(c, http.StatusOK, stats, "")
+}
@@ -0,0 +1,238 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/myideascope/HomeGenie/backend/internal/files"
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+	"github.com/myideascope/HomeGenie/backend/pkg/middleware"
+	"github.com/myideascope/HomeGenie/backend/pkg/openapi"
+)
+
+// AttachmentHandler serves the attachments of tasks, properties, rooms and
+// maintenance records
+type AttachmentHandler struct {
+	service *files.Service
+}
+
+// NewAttachmentHandler creates an attachment handler
+func NewAttachmentHandler(service *files.Service) *AttachmentHandler {
+	return &AttachmentHandler{service: service}
+}
+
+// parentResolver reads the attachment parent from the path, or aborts with 400
+type parentResolver func(c *gin.Context) (files.Parent, bool)
+
+// attachmentParents maps each attachment collection path to its parent
+var attachmentParents = []struct {
+	path    string
+	tag     string
+	resolve parentResolver
+}{
+	{"/tasks/:id/attachments", "Tasks", func(c *gin.Context) (files.Parent, bool) {
+		id, ok := idParam(c, "id")
+		return files.Parent{Kind: files.ParentTask, ID: id}, ok
+	}},
+	{"/properties/:id/attachments", "Properties", func(c *gin.Context) (files.Parent, bool) {
+		id, ok := idParam(c, "id")
+		return files.Parent{Kind: files.ParentProperty, ID: id}, ok
+	}},
+	{"/properties/:id/rooms/:roomId/attachments", "Properties", func(c *gin.Context) (files.Parent, bool) {
+		return nestedParent(c, files.ParentRoom, "roomId")
+	}},
+	{"/properties/:id/maintenance-history/:recordId/attachments", "Properties", func(c *gin.Context) (files.Parent, bool) {
+		return nestedParent(c, files.ParentMaintenance, "recordId")
+	}},
+}
+
+// nestedParent resolves a parent addressed within the property in :id
+func nestedParent(c *gin.Context, kind files.ParentKind, param string) (files.Parent, bool) {
+	propertyID, ok := idParam(c, "id")
+	if !ok {
+		return files.Parent{}, false
+	}
+	id, ok := idParam(c, param)
+	return files.Parent{Kind: kind, ID: id, PropertyID: propertyID}, ok
+}
+
+// RegisterRoutes mounts the attachment endpoints of every parent on an
+// authenticated group
+func (h *AttachmentHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	for _, p := range attachmentParents {
+		group := rg.Group(p.path)
+		group.GET("", h.List(p.resolve))
+		group.POST("", h.Attach(p.resolve))
+		group.PUT("/order", h.Reorder(p.resolve))
+		group.PATCH("/:attachmentId", h.Update(p.resolve))
+		group.DELETE("/:attachmentId", h.Detach(p.resolve))
+	}
+}
+
+// attachmentOperations documents the attachment endpoints in the OpenAPI document
+var attachmentOperations = func() []openapi.Operation {
+	var ops []openapi.Operation
+	for _, p := range attachmentParents {
+		ops = append(ops,
+			openapi.Operation{Method: http.MethodGet, Path: p.path, Summary: "List attachments in order", Tag: p.tag,
+				Auth: true, Response: database.APIResponse[[]database.Attachment]{}},
+			openapi.Operation{Method: http.MethodPost, Path: p.path, Summary: "Attach an uploaded file", Tag: p.tag,
+				Auth: true, Request: database.AttachFileRequest{}, Response: database.APIResponse[database.Attachment]{},
+				Status: http.StatusCreated},
+			openapi.Operation{Method: http.MethodPut, Path: p.path + "/order", Summary: "Reorder attachments",
+				Tag: p.tag, Auth: true, Request: database.ReorderAttachmentsRequest{},
+				Response: database.APIResponse[[]database.Attachment]{}},
+			openapi.Operation{Method: http.MethodPatch, Path: p.path + "/:attachmentId",
+				Summary: "Update an attachment's caption", Tag: p.tag, Auth: true,
+				Request: database.UpdateAttachmentRequest{}, Response: database.APIResponse[database.Attachment]{}},
+			openapi.Operation{Method: http.MethodDelete, Path: p.path + "/:attachmentId",
+				Summary: "Detach a file, deleting it if it is attached nowhere else", Tag: p.tag, Auth: true,
+				Status: http.StatusNoContent},
+		)
+	}
+	return ops
+}()
+
+// List returns the parent's attachments in order
+func (h *AttachmentHandler) List(resolve parentResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		parent, ok := resolve(c)
+		if !ok {
+			return
+		}
+
+		attachments, err := h.service.Attachments(c.Request.Context(), userID, parent)
+		if err != nil {
+			h.fail(c, err)
+			return
+		}
+		respond(c, http.StatusOK, attachments, "")
+	}
+}
+
+// Attach attaches one of the current user's uploaded files to the parent
+func (h *AttachmentHandler) Attach(resolve parentResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		parent, ok := resolve(c)
+		if !ok {
+			return
+		}
+
+		var req database.AttachFileRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+
+		attachment, err := h.service.Attach(c.Request.Context(), userID, parent, req)
+		if err != nil {
+			h.fail(c, err)
+			return
+		}
+		respond(c, http.StatusCreated, attachment, "File attached successfully")
+	}
+}
+
+// Reorder sets the order of the parent's attachments
+func (h *AttachmentHandler) Reorder(resolve parentResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		parent, ok := resolve(c)
+		if !ok {
+			return
+		}
+
+		var req database.ReorderAttachmentsRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+
+		attachments, err := h.service.ReorderAttachments(c.Request.Context(), userID, parent, req.AttachmentIDs)
+		if err != nil {
+			h.fail(c, err)
+			return
+		}
+		respond(c, http.StatusOK, attachments, "")
+	}
+}
+
+// Update changes an attachment's caption
+func (h *AttachmentHandler) Update(resolve parentResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		parent, ok := resolve(c)
+		if !ok {
+			return
+		}
+		id, ok := idParam(c, "attachmentId")
+		if !ok {
+			return
+		}
+
+		var req database.UpdateAttachmentRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+
+		attachment, err := h.service.UpdateAttachment(c.Request.Context(), userID, parent, id, req)
+		if err != nil {
+			h.fail(c, err)
+			return
+		}
+		respond(c, http.StatusOK, attachment, "")
+	}
+}
+
+// Detach removes an attachment from the parent
+func (h *AttachmentHandler) Detach(resolve parentResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		parent, ok := resolve(c)
+		if !ok {
+			return
+		}
+		id, ok := idParam(c, "attachmentId")
+		if !ok {
+			return
+		}
+
+		if err := h.service.Detach(c.Request.Context(), userID, parent, id); err != nil {
+			h.fail(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func (h *AttachmentHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, files.ErrParentNotFound), errors.Is(err, files.ErrAttachmentNotFound):
+		respondError(c, middleware.NotFoundError(err.Error()).WithCause(err))
+	case errors.Is(err, files.ErrNotFound), errors.Is(err, files.ErrInvalidOrder):
+		respondError(c, middleware.ValidationError(err.Error()).WithCause(err))
+	case errors.Is(err, files.ErrAlreadyAttached):
+		respondError(c, middleware.ConflictError(err.Error()).WithCause(err))
+	case errors.Is(err, files.ErrQuarantined):
+		respondError(c, middleware.NewAPIError(http.StatusUnprocessableEntity, middleware.CodeValidation,
+			err.Error()).WithCause(err))
+	default:
+		respondError(c, err)
+	}
+}
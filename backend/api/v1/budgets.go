@@ -0,0 +1,157 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/myideascope/HomeGenie/backend/internal/budgets"
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+	"github.com/myideascope/HomeGenie/backend/pkg/middleware"
+	"github.com/myideascope/HomeGenie/backend/pkg/openapi"
+)
+
+// BudgetHandler serves the annual budgets of properties
+type BudgetHandler struct {
+	service *budgets.Service
+}
+
+// NewBudgetHandler creates a budget handler
+func NewBudgetHandler(service *budgets.Service) *BudgetHandler {
+	return &BudgetHandler{service: service}
+}
+
+// RegisterRoutes mounts the budget endpoints on an authenticated group
+func (h *BudgetHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	group := rg.Group("/properties/:id/budgets")
+	group.GET("", h.List)
+	group.POST("", h.Create)
+	group.PUT("/:budgetId", h.Update)
+	group.DELETE("/:budgetId", h.Delete)
+}
+
+// budgetOperations documents the budget endpoints in the OpenAPI document
+var budgetOperations = []openapi.Operation{
+	{Method: http.MethodGet, Path: "/properties/:id/budgets", Summary: "List a property's budgets with their spend",
+		Tag: "Budgets", Auth: true, Query: database.BudgetFilters{},
+		Response: database.APIResponse[[]database.Budget]{}},
+	{Method: http.MethodPost, Path: "/properties/:id/budgets", Summary: "Set an annual budget for a cost category",
+		Tag: "Budgets", Auth: true, Request: database.CreateBudgetRequest{},
+		Response: database.APIResponse[database.Budget]{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/properties/:id/budgets/:budgetId", Summary: "Update a budget",
+		Tag: "Budgets", Auth: true, Request: database.UpdateBudgetRequest{},
+		Response: database.APIResponse[database.Budget]{}},
+	{Method: http.MethodDelete, Path: "/properties/:id/budgets/:budgetId", Summary: "Delete a budget",
+		Tag: "Budgets", Auth: true, Status: http.StatusNoContent},
+}
+
+// List returns the property's budgets, optionally for one year
+func (h *BudgetHandler) List(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	propertyID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var filters database.BudgetFilters
+	if !bindQuery(c, &filters) {
+		return
+	}
+
+	list, err := h.service.List(c.Request.Context(), userID, propertyID, filters)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, list, "")
+}
+
+// Create sets a budget for the property
+func (h *BudgetHandler) Create(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	propertyID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req database.CreateBudgetRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	budget, err := h.service.Create(c.Request.Context(), userID, propertyID, req)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusCreated, budget, "Budget created successfully")
+}
+
+// Update changes a budget's amount, currency or alert thresholds
+func (h *BudgetHandler) Update(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	propertyID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "budgetId")
+	if !ok {
+		return
+	}
+
+	var req database.UpdateBudgetRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	budget, err := h.service.Update(c.Request.Context(), userID, propertyID, id, req)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, budget, "Budget updated successfully")
+}
+
+// Delete removes a budget from the property
+func (h *BudgetHandler) Delete(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	propertyID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "budgetId")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), userID, propertyID, id); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *BudgetHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, budgets.ErrPropertyNotFound), errors.Is(err, budgets.ErrNotFound):
+		respondError(c, middleware.NotFoundError(err.Error()).WithCause(err))
+	case errors.Is(err, budgets.ErrExists):
+		respondError(c, middleware.ConflictError(err.Error()).WithCause(err))
+	case errors.Is(err, budgets.ErrFractionalAmount):
+		respondError(c, middleware.ValidationError(err.Error()).WithCause(err))
+	default:
+		respondError(c, err)
+	}
+}
@@ -0,0 +1,254 @@
+package v1
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/myideascope/HomeGenie/backend/internal/files"
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+	"github.com/myideascope/HomeGenie/backend/pkg/middleware"
+	"github.com/myideascope/HomeGenie/backend/pkg/openapi"
+	"github.com/myideascope/HomeGenie/backend/pkg/storage"
+)
+
+// multipartOverhead is the allowance for multipart headers and the
+// category field on top of the maximum file size
+const multipartOverhead = 64 << 10
+
+// FileHandler serves file uploads and, for local storage, signed downloads
+type FileHandler struct {
+	service *files.Service
+	maxSize int64
+	// local is set when files are stored on disk and downloads are served
+	// by this API rather than the storage provider
+	local *storage.LocalStorage
+}
+
+// NewFileHandler creates a file handler. maxSize must match the service's
+// configured maximum.
+func NewFileHandler(service *files.Service, store storage.Storage, maxSize int64) *FileHandler {
+	local, _ := store.(*storage.LocalStorage)
+	return &FileHandler{service: service, maxSize: maxSize, local: local}
+}
+
+// RegisterRoutes mounts the file endpoints on an authenticated group
+func (h *FileHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	group := rg.Group("/files")
+	group.POST("/upload", h.Upload)
+	group.GET("/:filename", h.Get)
+	group.GET("/:filename/download", h.Redirect)
+	group.DELETE("/:filename", h.Delete)
+}
+
+// RegisterDownloadRoutes mounts the signed download endpoint on an
+// unauthenticated group. It is only mounted for local storage; other
+// backends serve their signed URLs themselves.
+func (h *FileHandler) RegisterDownloadRoutes(rg *gin.RouterGroup) {
+	if h.local != nil {
+		rg.GET("/files/download/*key", h.Download)
+	}
+}
+
+// fileOperations documents the file endpoints in the OpenAPI document
+var fileOperations = []openapi.Operation{
+	{Method: http.MethodPost, Path: "/files/upload",
+		Summary: "Upload a file as multipart/form-data with file and category fields; " +
+			"202 means it is pending a malware scan", Tag: "Files", Auth: true,
+		Response: database.APIResponse[database.File]{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/files/:filename", Summary: "Get a file with a fresh download URL", Tag: "Files",
+		Auth: true, Response: database.APIResponse[database.File]{}},
+	{Method: http.MethodGet, Path: "/files/:filename/download",
+		Summary: "Redirect to a signed download URL for the original or a resized variant", Tag: "Files", Auth: true,
+		Query: database.FileDownloadFilters{}, Status: http.StatusFound},
+	{Method: http.MethodDelete, Path: "/files/:filename", Summary: "Delete a file", Tag: "Files", Auth: true,
+		Status: http.StatusNoContent},
+}
+
+// Upload streams a multipart upload into storage. The file part may come
+// before or after the category field, so it is spooled first and checked
+// against the category once the whole form has been read.
+func (h *FileHandler) Upload(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxSize+multipartOverhead)
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		respondError(c, middleware.ValidationError("Expected a multipart/form-data body").WithCause(err))
+		return
+	}
+
+	var (
+		upload   *files.Upload
+		category database.FileCategory
+	)
+	defer func() {
+		if upload != nil {
+			upload.Close()
+		}
+	}()
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			h.fail(c, err)
+			return
+		}
+
+		switch part.FormName() {
+		case "file":
+			if upload != nil {
+				respondError(c, middleware.ValidationError("Only one file may be uploaded at a time"))
+				return
+			}
+			if upload, err = h.service.Spool(part, part.FileName()); err != nil {
+				h.fail(c, err)
+				return
+			}
+		case "category":
+			value, err := io.ReadAll(io.LimitReader(part, 64))
+			if err != nil {
+				h.fail(c, err)
+				return
+			}
+			category = database.FileCategory(strings.TrimSpace(string(value)))
+		}
+	}
+
+	if upload == nil {
+		respondError(c, middleware.ValidationError("file is required"))
+		return
+	}
+	if !category.Valid() {
+		respondError(c, middleware.ValidationError("category must be one of: "+
+			strings.Join(category.EnumValues(), ", ")))
+		return
+	}
+
+	file, err := h.service.Save(c.Request.Context(), userID, upload, category)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	if file.Status == database.FileStatusPending {
+		respond(c, http.StatusAccepted, file, "File uploaded and waiting for its malware scan")
+		return
+	}
+	respond(c, http.StatusCreated, file, "File uploaded successfully")
+}
+
+// Get returns one of the current user's files with a fresh download URL
+func (h *FileHandler) Get(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	file, err := h.service.Get(c.Request.Context(), userID, c.Param("filename"))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, file, "")
+}
+
+// Redirect sends the client to a signed download URL for the requested size
+func (h *FileHandler) Redirect(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var filters database.FileDownloadFilters
+	if !bindQuery(c, &filters) {
+		return
+	}
+
+	url, err := h.service.DownloadURL(c.Request.Context(), userID, c.Param("filename"), filters.Size)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, url)
+}
+
+// Delete removes one of the current user's files
+func (h *FileHandler) Delete(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), userID, c.Param("filename")); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Download serves a locally stored file to the holder of a signed URL
+func (h *FileHandler) Download(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	opts, err := h.local.Verify(key, c.Request.URL.Query())
+	if err != nil {
+		respondError(c, middleware.AuthorizationError("Download link is invalid or has expired").WithCause(err))
+		return
+	}
+
+	f, err := h.local.OpenFile(key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			err = middleware.NotFoundError(files.ErrNotFound.Error()).WithCause(err)
+		}
+		respondError(c, err)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if opts.ContentType != "" {
+		c.Header("Content-Type", opts.ContentType)
+	}
+	if cd := opts.ContentDisposition(); cd != "" {
+		c.Header("Content-Disposition", cd)
+	}
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "private, max-age=0")
+	http.ServeContent(c.Writer, c.Request, "", info.ModTime(), f)
+}
+
+func (h *FileHandler) fail(c *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.Is(err, files.ErrTooLarge), errors.As(err, &tooLarge):
+		respondError(c, middleware.NewAPIError(http.StatusRequestEntityTooLarge, middleware.CodeValidation,
+			files.ErrTooLarge.Error()).WithCause(err))
+	case errors.Is(err, files.ErrUnsupportedType), errors.Is(err, files.ErrExtensionMismatch):
+		respondError(c, middleware.NewAPIError(http.StatusUnsupportedMediaType, middleware.CodeValidation,
+			err.Error()).WithCause(err))
+	case errors.Is(err, files.ErrUnsafeContent), errors.Is(err, files.ErrQuarantined):
+		respondError(c, middleware.NewAPIError(http.StatusUnprocessableEntity, middleware.CodeValidation,
+			err.Error()).WithCause(err))
+	case errors.Is(err, files.ErrNotAvailable):
+		respondError(c, middleware.ConflictError(err.Error()).WithCause(err))
+	case errors.Is(err, files.ErrEmpty):
+		respondError(c, middleware.ValidationError(err.Error()).WithCause(err))
+	case errors.Is(err, files.ErrNotFound):
+		respondError(c, middleware.NotFoundError(err.Error()).WithCause(err))
+	default:
+		respondError(c, err)
+	}
+}
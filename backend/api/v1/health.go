@@ -0,0 +1,99 @@
+package v1
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+	"github.com/myideascope/HomeGenie/backend/pkg/health"
+	"github.com/myideascope/HomeGenie/backend/pkg/middleware"
+	"github.com/myideascope/HomeGenie/backend/pkg/openapi"
+)
+
+// DatabaseHealth is the body of GET /health/database
+type DatabaseHealth struct {
+	Status string `json:"status"`
+	// Latency of a ping in milliseconds
+	Latency    int64         `json:"latency"`
+	Error      string        `json:"error,omitempty"`
+	Migrations health.Result `json:"migrations"`
+}
+
+// HealthHandler serves liveness and readiness endpoints
+type HealthHandler struct {
+	checker *health.Checker
+	db      *sql.DB
+}
+
+// NewHealthHandler creates a health handler. The checker should have the
+// database, migration, Redis and mail checks registered as configured.
+func NewHealthHandler(checker *health.Checker, db *sql.DB) *HealthHandler {
+	return &HealthHandler{checker: checker, db: db}
+}
+
+// RegisterRoutes mounts the health endpoints on an unauthenticated group.
+// /health and /health/ready are the same readiness report; /health/live
+// only reports that the process is serving requests.
+func (h *HealthHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	group := rg.Group("/health")
+	group.GET("", h.Ready)
+	group.GET("/ready", h.Ready)
+	group.GET("/live", h.Live)
+	group.GET("/database", h.Database)
+}
+
+// healthOperations documents the health endpoints in the OpenAPI document
+var healthOperations = []openapi.Operation{
+	{Method: http.MethodGet, Path: "/health", Summary: "Readiness report", Tag: "Health",
+		Response: database.APIResponse[health.Report]{}},
+	{Method: http.MethodGet, Path: "/health/ready", Summary: "Readiness report", Tag: "Health",
+		Response: database.APIResponse[health.Report]{}},
+	{Method: http.MethodGet, Path: "/health/live", Summary: "Liveness probe", Tag: "Health",
+		Response: database.APIResponse[map[string]string]{}},
+	{Method: http.MethodGet, Path: "/health/database", Summary: "Database connectivity and migrations", Tag: "Health",
+		Response: database.APIResponse[DatabaseHealth]{}},
+}
+
+// Live reports that the process is up. It never checks dependencies, so an
+// outage elsewhere does not get every replica restarted.
+func (h *HealthHandler) Live(c *gin.Context) {
+	respond(c, http.StatusOK, gin.H{"status": health.Healthy}, "")
+}
+
+// Ready runs every dependency check and returns 503 if a critical one fails
+// or the server is shutting down
+func (h *HealthHandler) Ready(c *gin.Context) {
+	report := h.checker.Run(c.Request.Context())
+	if !report.Ready() {
+		h.unavailable(c, "Service unavailable", report)
+		return
+	}
+	respond(c, http.StatusOK, report, "")
+}
+
+// Database reports Postgres connectivity and latency plus migration state
+func (h *HealthHandler) Database(c *gin.Context) {
+	ctx := c.Request.Context()
+	ping := health.Probe(ctx, health.Database(h.db))
+
+	result := DatabaseHealth{
+		Status:  "connected",
+		Latency: ping.Latency,
+		Error:   ping.Error,
+	}
+	if ping.Status == health.StatusDown {
+		result.Status = "disconnected"
+		h.unavailable(c, "Database unavailable", result)
+		return
+	}
+
+	result.Migrations = health.Probe(ctx, health.Migrations(h.db))
+	respond(c, http.StatusOK, result, "")
+}
+
+// unavailable writes a 503 error body carrying the report as details
+func (h *HealthHandler) unavailable(c *gin.Context, message string, details interface{}) {
+	respondError(c, middleware.NewAPIError(http.StatusServiceUnavailable, middleware.CodeServiceUnavailable, message).
+		WithDetails(details))
+}
@@ -0,0 +1,14 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/myideascope/HomeGenie/backend/pkg/metrics"
+)
+
+// RegisterMetricsRoutes serves Prometheus metrics at /metrics. Mount it on
+// the root router, outside BasePath and authentication, where the scrape
+// config in deploy/observability/prometheus/prometheus.yml expects it, and
+// keep it off the public ingress.
+func RegisterMetricsRoutes(rg *gin.RouterGroup) {
+	rg.GET("/metrics", gin.WrapH(metrics.Handler()))
+}
@@ -0,0 +1,27 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMetricsRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	RegisterMetricsRoutes(&router.RouterGroup)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /metrics = %d, want 200", w.Code)
+	}
+	for _, name := range []string{"homegenie_websocket_connections", "go_goroutines"} {
+		if !strings.Contains(w.Body.String(), name) {
+			t.Errorf("GET /metrics does not expose %s", name)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/myideascope/HomeGenie/backend/internal/notifications"
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+	"github.com/myideascope/HomeGenie/backend/pkg/middleware"
+	"github.com/myideascope/HomeGenie/backend/pkg/openapi"
+)
+
+// NotificationHandler serves notification and notification settings endpoints
+type NotificationHandler struct {
+	service *notifications.Service
+}
+
+// NewNotificationHandler creates a notification handler
+func NewNotificationHandler(service *notifications.Service) *NotificationHandler {
+	return &NotificationHandler{service: service}
+}
+
+// RegisterRoutes mounts the notification endpoints on an authenticated group
+func (h *NotificationHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	group := rg.Group("/notifications")
+	group.GET("", h.List)
+	group.DELETE("", h.Clear)
+	group.PATCH("/read-all", h.MarkAllRead)
+	group.GET("/settings", h.Settings)
+	group.PUT("/settings", h.UpdateSettings)
+	group.PATCH("/settings", h.UpdateSettings)
+	group.POST("/test", h.Test)
+	group.PATCH("/:id/read", h.MarkRead)
+	group.DELETE("/:id", h.Delete)
+}
+
+// notificationOperations documents the notification endpoints in the OpenAPI document
+var notificationOperations = []openapi.Operation{
+	{Method: http.MethodGet, Path: "/notifications", Summary: "List notifications with the unread count",
+		Tag: "Notifications", Auth: true, Query: database.NotificationFilters{},
+		Response: database.APIResponse[database.NotificationList]{}},
+	{Method: http.MethodDelete, Path: "/notifications", Summary: "Clear all notifications", Tag: "Notifications",
+		Auth: true, Status: http.StatusNoContent},
+	{Method: http.MethodPatch, Path: "/notifications/read-all", Summary: "Mark all notifications read",
+		Tag: "Notifications", Auth: true, Response: database.APIResponse[database.MarkAllReadResponse]{}},
+	{Method: http.MethodGet, Path: "/notifications/settings", Summary: "Get notification settings",
+		Tag: "Notifications", Auth: true, Response: database.APIResponse[database.NotificationSettings]{}},
+	{Method: http.MethodPut, Path: "/notifications/settings", Summary: "Update notification settings",
+		Tag: "Notifications", Auth: true, Request: database.UpdateNotificationSettingsRequest{},
+		Response: database.APIResponse[database.NotificationSettings]{}},
+	{Method: http.MethodPatch, Path: "/notifications/settings", Summary: "Update notification settings",
+		Tag: "Notifications", Auth: true, Request: database.UpdateNotificationSettingsRequest{},
+		Response: database.APIResponse[database.NotificationSettings]{}},
+	{Method: http.MethodPost, Path: "/notifications/test", Summary: "Send a test notification on every enabled channel",
+		Tag: "Notifications", Auth: true, Request: database.TestNotificationRequest{},
+		Response: database.APIResponse[database.TestNotificationResponse]{}},
+	{Method: http.MethodPatch, Path: "/notifications/:id/read", Summary: "Mark a notification read",
+		Tag: "Notifications", Auth: true, Response: database.APIResponse[database.Notification]{}},
+	{Method: http.MethodDelete, Path: "/notifications/:id", Summary: "Delete a notification", Tag: "Notifications",
+		Auth: true, Status: http.StatusNoContent},
+}
+
+// List returns a page of the current user's notifications and their unread count
+func (h *NotificationHandler) List(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var filters database.NotificationFilters
+	if !bindQuery(c, &filters) {
+		return
+	}
+	filters.Page, filters.Limit = normalizePage(filters.Page, filters.Limit)
+
+	list, total, unread, err := h.service.List(c.Request.Context(), userID, filters)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, database.NotificationList{
+		PaginatedResponse: paginated(list, total, filters.Page, filters.Limit),
+		UnreadCount:       unread,
+	}, "")
+}
+
+// MarkRead marks a notification read
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	notification, err := h.service.MarkRead(c.Request.Context(), userID, id)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, notification, "")
+}
+
+// MarkAllRead marks every notification read
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	updated, err := h.service.MarkAllRead(c.Request.Context(), userID)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, database.MarkAllReadResponse{Updated: updated}, "")
+}
+
+// Delete removes a notification
+func (h *NotificationHandler) Delete(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), userID, id); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Clear removes all of the current user's notifications
+func (h *NotificationHandler) Clear(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Clear(c.Request.Context(), userID); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Settings returns the current user's notification settings
+func (h *NotificationHandler) Settings(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	settings, err := h.service.Settings(c.Request.Context(), userID)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, settings, "")
+}
+
+// UpdateSettings applies a partial update to the notification settings
+func (h *NotificationHandler) UpdateSettings(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req database.UpdateNotificationSettingsRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(c.Request.Context(), userID, req)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, settings, "Notification settings updated")
+}
+
+// Test sends a test notification through every enabled channel and reports
+// the outcome on each
+func (h *NotificationHandler) Test(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req database.TestNotificationRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	result, err := h.service.Test(c.Request.Context(), userID, req.Type)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, result, "Test notification sent")
+}
+
+// fail maps service errors to HTTP responses
+func (h *NotificationHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, notifications.ErrNotFound):
+		respondError(c, middleware.NotFoundError(err.Error()).WithCause(err))
+	default:
+		respondError(c, err)
+	}
+}
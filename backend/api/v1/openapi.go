@@ -0,0 +1,74 @@
+package v1
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/myideascope/HomeGenie/backend/pkg/middleware"
+	"github.com/myideascope/HomeGenie/backend/pkg/openapi"
+)
+
+// BasePath is where the v1 API is mounted
+const BasePath = "/api/v1"
+
+// operationGroups lists every handler's operations. A handler's operations
+// are declared next to its RegisterRoutes and must be added here.
+var operationGroups = [][]openapi.Operation{
+	healthOperations,
+	analyticsOperations,
+	attachmentOperations,
+	budgetOperations,
+	fileOperations,
+	notificationOperations,
+	propertyOperations,
+	taskOperations,
+	webhookOperations,
+}
+
+var (
+	specOnce sync.Once
+	spec     *openapi.Document
+)
+
+// Spec returns the OpenAPI document for the v1 API, built from the request
+// and response types of each endpoint
+func Spec() *openapi.Document {
+	specOnce.Do(func() {
+		spec = openapi.New(openapi.Info{
+			Title:   "HomeGenie API",
+			Version: "1.0.0",
+		}, middleware.ErrorBody{}, openapi.Server{URL: BasePath})
+
+		for _, ops := range operationGroups {
+			spec.Add(ops...)
+		}
+	})
+	return spec
+}
+
+// RegisterOpenAPIRoutes serves the OpenAPI document at /openapi.json and an
+// interactive reference at /docs on an unauthenticated group
+func RegisterOpenAPIRoutes(rg *gin.RouterGroup) {
+	rg.GET("/openapi.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, Spec())
+	})
+	rg.GET("/docs", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+	})
+}
+
+// docsPage renders the document with Scalar's API reference
+var docsPage = []byte(`<!doctype html>
+<html>
+  <head>
+    <title>HomeGenie API</title>
+    <meta charset="utf-8" />
+    <meta name="viewport" content="width=device-width, initial-scale=1" />
+  </head>
+  <body>
+    <script id="api-reference" data-url="openapi.json"></script>
+    <script src="https://cdn.jsdelivr.net/npm/@scalar/api-reference"></script>
+  </body>
+</html>
+`)
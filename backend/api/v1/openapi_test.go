@@ -0,0 +1,68 @@
+package v1
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// routeParam matches gin path parameters such as ":id"
+var routeParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// apiRouter mounts every v1 handler the way the server does. Services are
+// nil because routes are only registered, never served.
+func apiRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api := router.Group(BasePath)
+
+	NewHealthHandler(nil, nil).RegisterRoutes(api)
+	NewAnalyticsHandler(nil).RegisterRoutes(api)
+	NewAttachmentHandler(nil).RegisterRoutes(api)
+	NewBudgetHandler(nil).RegisterRoutes(api)
+	NewFileHandler(nil, nil, 0).RegisterRoutes(api)
+	NewNotificationHandler(nil).RegisterRoutes(api)
+	NewPropertyHandler(nil, nil).RegisterRoutes(api)
+	NewTaskHandler(nil).RegisterRoutes(api)
+	NewWebhookHandler(nil).RegisterRoutes(api)
+	return router
+}
+
+// TestSpecMatchesRoutes fails when a route is registered without being
+// documented, or documented without being registered
+func TestSpecMatchesRoutes(t *testing.T) {
+	routes := make(map[string]bool)
+	for _, r := range apiRouter().Routes() {
+		path := routeParam.ReplaceAllString(strings.TrimPrefix(r.Path, BasePath), "{$1}")
+		routes[r.Method+" "+path] = true
+	}
+
+	documented := make(map[string]bool)
+	for path, item := range Spec().Paths {
+		for method := range *item {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	if missing := difference(routes, documented); len(missing) > 0 {
+		t.Errorf("routes missing from the OpenAPI document:\n%s", strings.Join(missing, "\n"))
+	}
+	if missing := difference(documented, routes); len(missing) > 0 {
+		t.Errorf("documented operations without a route:\n%s", strings.Join(missing, "\n"))
+	}
+}
+
+// difference returns the sorted keys of a that are not in b
+func difference(a, b map[string]bool) []string {
+	var keys []string
+	for key := range a {
+		if !b[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
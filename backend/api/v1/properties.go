@@ -0,0 +1,393 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/myideascope/HomeGenie/backend/internal/properties"
+	"github.com/myideascope/HomeGenie/backend/internal/tasks"
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+	"github.com/myideascope/HomeGenie/backend/pkg/middleware"
+	"github.com/myideascope/HomeGenie/backend/pkg/openapi"
+)
+
+// PropertyHandler serves property, room and maintenance history endpoints
+type PropertyHandler struct {
+	service *properties.Service
+	tasks   *tasks.Service
+}
+
+// NewPropertyHandler creates a property handler. Task listing for a property
+// is served by the task service.
+func NewPropertyHandler(service *properties.Service, tasks *tasks.Service) *PropertyHandler {
+	return &PropertyHandler{service: service, tasks: tasks}
+}
+
+// RegisterRoutes mounts the property endpoints on an authenticated group
+func (h *PropertyHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	group := rg.Group("/properties")
+	group.GET("", h.List)
+	group.POST("", h.Create)
+	group.GET("/:id", h.Get)
+	group.PUT("/:id", h.Update)
+	group.PATCH("/:id", h.Update)
+	group.DELETE("/:id", h.Delete)
+	group.GET("/:id/dependents", h.Dependents)
+	group.GET("/:id/tasks", h.Tasks)
+	group.GET("/:id/maintenance-history", h.MaintenanceHistory)
+	group.POST("/:id/maintenance-history", h.AddMaintenance)
+	group.GET("/:id/rooms", h.Rooms)
+	group.POST("/:id/rooms", h.CreateRoom)
+	group.PUT("/:id/rooms/:roomId", h.UpdateRoom)
+	group.PATCH("/:id/rooms/:roomId", h.UpdateRoom)
+	group.DELETE("/:id/rooms/:roomId", h.DeleteRoom)
+}
+
+// propertyOperations documents the property endpoints in the OpenAPI document
+var propertyOperations = []openapi.Operation{
+	{Method: http.MethodGet, Path: "/properties", Summary: "List properties", Tag: "Properties", Auth: true,
+		Query: database.PropertyFilters{}, Response: database.APIResponse[database.PaginatedResponse[database.Property]]{}},
+	{Method: http.MethodPost, Path: "/properties", Summary: "Create a property", Tag: "Properties", Auth: true,
+		Request: database.CreatePropertyRequest{}, Response: database.APIResponse[database.Property]{},
+		Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/properties/:id", Summary: "Get a property with rooms and maintenance history",
+		Tag: "Properties", Auth: true, Response: database.APIResponse[database.Property]{}},
+	{Method: http.MethodPut, Path: "/properties/:id", Summary: "Update a property", Tag: "Properties", Auth: true,
+		Request: database.UpdatePropertyRequest{}, Response: database.APIResponse[database.Property]{}},
+	{Method: http.MethodPatch, Path: "/properties/:id", Summary: "Update a property", Tag: "Properties", Auth: true,
+		Request: database.UpdatePropertyRequest{}, Response: database.APIResponse[database.Property]{}},
+	{Method: http.MethodDelete, Path: "/properties/:id", Summary: "Delete a property and its related records",
+		Tag: "Properties", Auth: true, Query: database.DeletePropertyFilters{}, Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/properties/:id/dependents", Summary: "Count records deleted with a property",
+		Tag: "Properties", Auth: true, Response: database.APIResponse[database.PropertyDependents]{}},
+	{Method: http.MethodGet, Path: "/properties/:id/tasks", Summary: "List a property's tasks", Tag: "Properties",
+		Auth: true, Query: database.TaskFilters{},
+		Response: database.APIResponse[database.PaginatedResponse[database.Task]]{}},
+	{Method: http.MethodGet, Path: "/properties/:id/maintenance-history", Summary: "List maintenance history",
+		Tag: "Properties", Auth: true, Response: database.APIResponse[[]database.MaintenanceRecord]{}},
+	{Method: http.MethodPost, Path: "/properties/:id/maintenance-history", Summary: "Record maintenance",
+		Tag: "Properties", Auth: true, Request: database.CreateMaintenanceRecordRequest{},
+		Response: database.APIResponse[database.MaintenanceRecord]{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/properties/:id/rooms", Summary: "List rooms", Tag: "Rooms", Auth: true,
+		Response: database.APIResponse[[]database.Room]{}},
+	{Method: http.MethodPost, Path: "/properties/:id/rooms", Summary: "Add a room", Tag: "Rooms", Auth: true,
+		Request: database.CreateRoomRequest{}, Response: database.APIResponse[database.Room]{},
+		Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/properties/:id/rooms/:roomId", Summary: "Update a room", Tag: "Rooms",
+		Auth: true, Request: database.UpdateRoomRequest{}, Response: database.APIResponse[database.Room]{}},
+	{Method: http.MethodPatch, Path: "/properties/:id/rooms/:roomId", Summary: "Update a room", Tag: "Rooms",
+		Auth: true, Request: database.UpdateRoomRequest{}, Response: database.APIResponse[database.Room]{}},
+	{Method: http.MethodDelete, Path: "/properties/:id/rooms/:roomId", Summary: "Delete a room", Tag: "Rooms",
+		Auth: true, Status: http.StatusNoContent},
+}
+
+// List returns a page of the current user's properties
+func (h *PropertyHandler) List(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var filters database.PropertyFilters
+	if !bindQuery(c, &filters) {
+		return
+	}
+	filters.Page, filters.Limit = normalizePage(filters.Page, filters.Limit)
+
+	list, total, err := h.service.List(c.Request.Context(), userID, filters)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, paginated(list, total, filters.Page, filters.Limit), "")
+}
+
+// Create adds a property
+func (h *PropertyHandler) Create(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req database.CreatePropertyRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	property, err := h.service.Create(c.Request.Context(), userID, req)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusCreated, property, "Property created")
+}
+
+// Get returns a property with its rooms and maintenance history
+func (h *PropertyHandler) Get(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	property, err := h.service.Get(c.Request.Context(), userID, id)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, property, "")
+}
+
+// Update applies a partial update to a property
+func (h *PropertyHandler) Update(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req database.UpdatePropertyRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	property, err := h.service.Update(c.Request.Context(), userID, id, req)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, property, "Property updated")
+}
+
+// Delete removes a property. One with rooms, tasks or maintenance history is
+// only deleted with ?confirm=true; otherwise 409 lists what would be lost.
+func (h *PropertyHandler) Delete(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var filters database.DeletePropertyFilters
+	if !bindQuery(c, &filters) {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), userID, id, filters.Confirm); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Dependents counts the records that deleting a property would also delete,
+// for the confirmation prompt
+func (h *PropertyHandler) Dependents(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	dependents, err := h.service.Dependents(c.Request.Context(), userID, id)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, dependents, "")
+}
+
+// Tasks returns a page of a property's tasks
+func (h *PropertyHandler) Tasks(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var filters database.TaskFilters
+	if !bindQuery(c, &filters) {
+		return
+	}
+	filters.Page, filters.Limit = normalizePage(filters.Page, filters.Limit)
+	filters.PropertyID = &id
+
+	if err := h.service.Check(c.Request.Context(), userID, id); err != nil {
+		h.fail(c, err)
+		return
+	}
+	list, total, err := h.tasks.List(c.Request.Context(), userID, filters)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, paginated(list, total, filters.Page, filters.Limit), "")
+}
+
+// MaintenanceHistory returns a property's maintenance records, most recent first
+func (h *PropertyHandler) MaintenanceHistory(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	records, err := h.service.MaintenanceHistory(c.Request.Context(), userID, id)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, records, "")
+}
+
+// AddMaintenance records maintenance done on a property
+func (h *PropertyHandler) AddMaintenance(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req database.CreateMaintenanceRecordRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	record, err := h.service.AddMaintenance(c.Request.Context(), userID, id, req)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusCreated, record, "Maintenance recorded")
+}
+
+// Rooms returns a property's rooms
+func (h *PropertyHandler) Rooms(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	rooms, err := h.service.Rooms(c.Request.Context(), userID, id)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, rooms, "")
+}
+
+// CreateRoom adds a room to a property
+func (h *PropertyHandler) CreateRoom(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req database.CreateRoomRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	room, err := h.service.CreateRoom(c.Request.Context(), userID, id, req)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusCreated, room, "Room created")
+}
+
+// UpdateRoom applies a partial update to a room
+func (h *PropertyHandler) UpdateRoom(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	roomID, ok := idParam(c, "roomId")
+	if !ok {
+		return
+	}
+
+	var req database.UpdateRoomRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	room, err := h.service.UpdateRoom(c.Request.Context(), userID, id, roomID, req)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, room, "Room updated")
+}
+
+// DeleteRoom removes a room from a property
+func (h *PropertyHandler) DeleteRoom(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	roomID, ok := idParam(c, "roomId")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteRoom(c.Request.Context(), userID, id, roomID); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// fail maps service errors to HTTP responses
+func (h *PropertyHandler) fail(c *gin.Context, err error) {
+	var dependents *properties.DependentsError
+	switch {
+	case errors.As(err, &dependents):
+		respondError(c, middleware.ConflictError("Property has related records. Repeat with confirm=true to delete them.").
+			WithDetails(dependents.Dependents).WithCause(err))
+	case errors.Is(err, properties.ErrNotFound), errors.Is(err, properties.ErrRoomNotFound):
+		respondError(c, middleware.NotFoundError(err.Error()).WithCause(err))
+	case errors.Is(err, properties.ErrTaskNotFound):
+		respondError(c, middleware.ValidationError(err.Error()).WithCause(err))
+	default:
+		respondError(c, err)
+	}
+}
@@ -0,0 +1,117 @@
+package v1
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+	"github.com/myideascope/HomeGenie/backend/pkg/middleware"
+)
+
+// Pagination defaults, matching the frontend's DEFAULT_PAGE_SIZE and MAX_PAGE_SIZE
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// respond writes data wrapped in the standard APIResponse envelope
+func respond[T any](c *gin.Context, status int, data T, message string) {
+	c.JSON(status, database.APIResponse[T]{
+		Data:      data,
+		Message:   message,
+		Status:    "success",
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// respondError writes err as a standard error body. Errors that are not
+// already an APIError are translated, so service and database errors can be
+// passed straight through.
+func respondError(c *gin.Context, err error) {
+	middleware.AbortWithError(c, err)
+}
+
+// bindJSON binds the request body into obj, or aborts with a 400 listing
+// the invalid fields
+func bindJSON(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		respondError(c, middleware.BindError(c, err))
+		return false
+	}
+	return true
+}
+
+// bindOptionalJSON is bindJSON for endpoints whose body may be omitted. An
+// empty body, including an empty chunked one, leaves obj unchanged.
+func bindOptionalJSON(c *gin.Context, obj interface{}) bool {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return true
+	}
+	if err := c.ShouldBindJSON(obj); err != nil {
+		if errors.Is(err, io.EOF) {
+			return true
+		}
+		respondError(c, middleware.BindError(c, err))
+		return false
+	}
+	return true
+}
+
+// bindQuery binds query parameters into obj, or aborts with a 400 listing
+// the invalid fields
+func bindQuery(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindQuery(obj); err != nil {
+		respondError(c, middleware.BindError(c, err))
+		return false
+	}
+	return true
+}
+
+// requireUserID returns the authenticated user ID or aborts with 401
+func requireUserID(c *gin.Context) (int, bool) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, middleware.AuthenticationError("Authorization token required"))
+	}
+	return userID, ok
+}
+
+// idParam parses a positive integer path parameter or aborts with 400
+func idParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil || id <= 0 {
+		respondError(c, middleware.ValidationError("Invalid "+name))
+		return 0, false
+	}
+	return id, true
+}
+
+// normalizePage applies pagination defaults and bounds
+func normalizePage(page, limit int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	return page, limit
+}
+
+// paginated builds a PaginatedResponse for one page of items
+func paginated[T any](items []T, total, page, limit int) database.PaginatedResponse[T] {
+	return database.PaginatedResponse[T]{
+		Items:   items,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+		HasNext: page*limit < total,
+		HasPrev: page > 1,
+	}
+}
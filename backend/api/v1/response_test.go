@@ -0,0 +1,52 @@
+package v1
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// chunked hides a reader's length, as a client streaming its body does
+type chunked struct{ io.Reader }
+
+func TestBindOptionalJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	type request struct {
+		Notes string `json:"notes" binding:"max=5"`
+	}
+
+	tests := []struct {
+		name      string
+		body      io.Reader
+		ok        bool
+		wantNotes string
+	}{
+		{"no body", nil, true, ""},
+		{"empty body", strings.NewReader(""), true, ""},
+		{"empty chunked body", chunked{strings.NewReader("")}, true, ""},
+		{"chunked body", chunked{strings.NewReader(`{"notes":"done"}`)}, true, "done"},
+		{"body", strings.NewReader(`{"notes":"done"}`), true, "done"},
+		{"malformed", strings.NewReader(`{"notes":`), false, ""},
+		{"invalid", chunked{strings.NewReader(`{"notes":"too long"}`)}, false, "too long"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/tasks/1/complete", tt.body)
+		if _, ok := tt.body.(chunked); ok && c.Request.ContentLength != -1 {
+			t.Fatalf("%s: ContentLength = %d, want -1", tt.name, c.Request.ContentLength)
+		}
+
+		var req request
+		if ok := bindOptionalJSON(c, &req); ok != tt.ok || req.Notes != tt.wantNotes {
+			t.Errorf("bindOptionalJSON(%s) = %v with notes %q, want %v with %q", tt.name, ok, req.Notes, tt.ok, tt.wantNotes)
+		}
+		if !tt.ok && w.Code != http.StatusBadRequest {
+			t.Errorf("bindOptionalJSON(%s) responded %d, want 400", tt.name, w.Code)
+		}
+	}
+}
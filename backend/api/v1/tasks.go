@@ -0,0 +1,241 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/myideascope/HomeGenie/backend/internal/tasks"
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+	"github.com/myideascope/HomeGenie/backend/pkg/middleware"
+	"github.com/myideascope/HomeGenie/backend/pkg/openapi"
+)
+
+// defaultUpcomingDays is the window for upcoming tasks when none is given
+const defaultUpcomingDays = 7
+
+// TaskHandler serves task endpoints
+type TaskHandler struct {
+	service *tasks.Service
+}
+
+// NewTaskHandler creates a task handler
+func NewTaskHandler(service *tasks.Service) *TaskHandler {
+	return &TaskHandler{service: service}
+}
+
+// RegisterRoutes mounts the task endpoints on an authenticated group
+func (h *TaskHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	group := rg.Group("/tasks")
+	group.GET("", h.List)
+	group.POST("", h.Create)
+	group.GET("/upcoming", h.Upcoming)
+	group.GET("/overdue", h.Overdue)
+	group.GET("/:id", h.Get)
+	group.PUT("/:id", h.Update)
+	group.PATCH("/:id", h.Update)
+	group.DELETE("/:id", h.Delete)
+	group.PATCH("/:id/complete", h.Complete)
+}
+
+// taskOperations documents the task endpoints in the OpenAPI document
+var taskOperations = []openapi.Operation{
+	{Method: http.MethodGet, Path: "/tasks", Summary: "List tasks", Tag: "Tasks", Auth: true,
+		Query: database.TaskFilters{}, Response: database.APIResponse[database.PaginatedResponse[database.Task]]{}},
+	{Method: http.MethodPost, Path: "/tasks", Summary: "Create a task", Tag: "Tasks", Auth: true,
+		Request: database.CreateTaskRequest{}, Response: database.APIResponse[database.Task]{},
+		Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/tasks/upcoming", Summary: "List open tasks due soon", Tag: "Tasks",
+		Auth: true, Query: database.UpcomingTaskFilters{}, Response: database.APIResponse[[]database.Task]{}},
+	{Method: http.MethodGet, Path: "/tasks/overdue", Summary: "List overdue tasks", Tag: "Tasks", Auth: true,
+		Response: database.APIResponse[[]database.Task]{}},
+	{Method: http.MethodGet, Path: "/tasks/:id", Summary: "Get a task", Tag: "Tasks", Auth: true,
+		Response: database.APIResponse[database.Task]{}},
+	{Method: http.MethodPut, Path: "/tasks/:id", Summary: "Update a task", Tag: "Tasks", Auth: true,
+		Request: database.UpdateTaskRequest{}, Response: database.APIResponse[database.Task]{}},
+	{Method: http.MethodPatch, Path: "/tasks/:id", Summary: "Update a task", Tag: "Tasks", Auth: true,
+		Request: database.UpdateTaskRequest{}, Response: database.APIResponse[database.Task]{}},
+	{Method: http.MethodDelete, Path: "/tasks/:id", Summary: "Delete a task", Tag: "Tasks", Auth: true,
+		Status: http.StatusNoContent},
+	{Method: http.MethodPatch, Path: "/tasks/:id/complete", Summary: "Complete a task", Tag: "Tasks", Auth: true,
+		Request: database.CompleteTaskRequest{}, Response: database.APIResponse[database.CompleteTaskResponse]{}},
+}
+
+// List returns a page of the current user's tasks
+func (h *TaskHandler) List(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var filters database.TaskFilters
+	if !bindQuery(c, &filters) {
+		return
+	}
+	filters.Page, filters.Limit = normalizePage(filters.Page, filters.Limit)
+
+	list, total, err := h.service.List(c.Request.Context(), userID, filters)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, paginated(list, total, filters.Page, filters.Limit), "")
+}
+
+// Create adds a task to one of the current user's properties
+func (h *TaskHandler) Create(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req database.CreateTaskRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	task, err := h.service.Create(c.Request.Context(), userID, req)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusCreated, task, "Task created")
+}
+
+// Upcoming returns open tasks due within the requested number of days
+func (h *TaskHandler) Upcoming(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var filters database.UpcomingTaskFilters
+	if !bindQuery(c, &filters) {
+		return
+	}
+	if filters.Days == 0 {
+		filters.Days = defaultUpcomingDays
+	}
+
+	list, err := h.service.Upcoming(c.Request.Context(), userID, filters.Days)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, list, "")
+}
+
+// Overdue returns open tasks past their due date
+func (h *TaskHandler) Overdue(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	list, err := h.service.Overdue(c.Request.Context(), userID)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, list, "")
+}
+
+// Get returns a single task
+func (h *TaskHandler) Get(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	task, err := h.service.Get(c.Request.Context(), userID, id)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, task, "")
+}
+
+// Update applies a partial update to a task
+func (h *TaskHandler) Update(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req database.UpdateTaskRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	task, err := h.service.Update(c.Request.Context(), userID, id, req)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, task, "Task updated")
+}
+
+// Delete removes a task
+func (h *TaskHandler) Delete(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), userID, id); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Complete marks a task completed. The body is optional; when it includes a
+// maintenance record, the work is added to the property's history.
+func (h *TaskHandler) Complete(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req database.CompleteTaskRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	resp, err := h.service.Complete(c.Request.Context(), userID, id, req)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, resp, "Task completed")
+}
+
+// fail maps service errors to HTTP responses
+func (h *TaskHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, tasks.ErrNotFound):
+		respondError(c, middleware.NotFoundError(err.Error()).WithCause(err))
+	case errors.Is(err, tasks.ErrPropertyNotFound):
+		respondError(c, middleware.ValidationError(err.Error()).WithCause(err))
+	case errors.Is(err, tasks.ErrAlreadyCompleted):
+		respondError(c, middleware.ConflictError(err.Error()).WithCause(err))
+	default:
+		respondError(c, err)
+	}
+}
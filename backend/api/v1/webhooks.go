@@ -0,0 +1,185 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/myideascope/HomeGenie/backend/internal/webhooks"
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+	"github.com/myideascope/HomeGenie/backend/pkg/middleware"
+	"github.com/myideascope/HomeGenie/backend/pkg/openapi"
+)
+
+// WebhookHandler serves webhook registration and delivery log endpoints
+type WebhookHandler struct {
+	service *webhooks.Service
+}
+
+// NewWebhookHandler creates a webhook handler
+func NewWebhookHandler(service *webhooks.Service) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+// RegisterRoutes mounts the webhook endpoints on an authenticated group
+func (h *WebhookHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	group := rg.Group("/webhooks")
+	group.GET("", h.List)
+	group.POST("", h.Create)
+	group.GET("/:id", h.Get)
+	group.PATCH("/:id", h.Update)
+	group.DELETE("/:id", h.Delete)
+	group.GET("/:id/deliveries", h.Deliveries)
+}
+
+// webhookOperations documents the webhook endpoints in the OpenAPI document
+var webhookOperations = []openapi.Operation{
+	{Method: http.MethodGet, Path: "/webhooks", Summary: "List webhooks", Tag: "Webhooks", Auth: true,
+		Response: database.APIResponse[[]database.Webhook]{}},
+	{Method: http.MethodPost, Path: "/webhooks", Summary: "Register a webhook", Tag: "Webhooks", Auth: true,
+		Request: database.CreateWebhookRequest{}, Response: database.APIResponse[database.CreateWebhookResponse]{},
+		Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/webhooks/:id", Summary: "Get a webhook", Tag: "Webhooks", Auth: true,
+		Response: database.APIResponse[database.Webhook]{}},
+	{Method: http.MethodPatch, Path: "/webhooks/:id", Summary: "Update a webhook", Tag: "Webhooks", Auth: true,
+		Request: database.UpdateWebhookRequest{}, Response: database.APIResponse[database.Webhook]{}},
+	{Method: http.MethodDelete, Path: "/webhooks/:id", Summary: "Delete a webhook", Tag: "Webhooks", Auth: true,
+		Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/webhooks/:id/deliveries", Summary: "List delivery attempts", Tag: "Webhooks",
+		Auth: true, Query: database.WebhookDeliveryFilters{},
+		Response: database.APIResponse[database.PaginatedResponse[database.WebhookDelivery]]{}},
+}
+
+// List returns the current user's webhooks
+func (h *WebhookHandler) List(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	list, err := h.service.List(c.Request.Context(), userID)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, list, "")
+}
+
+// Create registers a webhook and returns its signing secret once
+func (h *WebhookHandler) Create(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req database.CreateWebhookRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	webhook, err := h.service.Create(c.Request.Context(), userID, req)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusCreated, database.CreateWebhookResponse{Webhook: *webhook, Secret: webhook.Secret},
+		"Webhook created. Store the secret now; it will not be shown again.")
+}
+
+// Get returns a single webhook
+func (h *WebhookHandler) Get(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	webhook, err := h.service.Get(c.Request.Context(), userID, id)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, webhook, "")
+}
+
+// Update changes a webhook's URL, filters or active state
+func (h *WebhookHandler) Update(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req database.UpdateWebhookRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	webhook, err := h.service.Update(c.Request.Context(), userID, id, req)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, webhook, "Webhook updated")
+}
+
+// Delete removes a webhook
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), userID, id); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Deliveries returns a page of a webhook's delivery log
+func (h *WebhookHandler) Deliveries(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var filters database.WebhookDeliveryFilters
+	if !bindQuery(c, &filters) {
+		return
+	}
+	filters.Page, filters.Limit = normalizePage(filters.Page, filters.Limit)
+
+	deliveries, total, err := h.service.Deliveries(c.Request.Context(), userID, id, filters)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, paginated(deliveries, total, filters.Page, filters.Limit), "")
+}
+
+// fail maps service errors to HTTP responses
+func (h *WebhookHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, webhooks.ErrNotFound):
+		respondError(c, middleware.NotFoundError(err.Error()).WithCause(err))
+	case errors.Is(err, webhooks.ErrInvalidURL), errors.Is(err, webhooks.ErrInvalidEventType):
+		respondError(c, middleware.ValidationError(err.Error()).WithCause(err))
+	default:
+		respondError(c, err)
+	}
+}
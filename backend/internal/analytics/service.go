@@ -0,0 +1,393 @@
+package analytics
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/myideascope/HomeGenie/backend/pkg/cache"
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+)
+
+// DefaultPeriod is used for task analytics when no period is given
+const DefaultPeriod = "month"
+
+// window describes how a period is bucketed: count buckets of one unit,
+// ending with the bucket containing now
+type window struct {
+	unit  string // a date_trunc field
+	count int
+}
+
+var windows = map[string]window{
+	"week":    {unit: "day", count: 7},
+	"month":   {unit: "day", count: 30},
+	"quarter": {unit: "week", count: 13},
+	"year":    {unit: "month", count: 12},
+}
+
+// overdue matches open tasks past their due date, whether or not the
+// scheduler has flagged them yet
+const overdue = `(status = 'overdue' OR (status IN ('pending', 'in_progress') AND due_date < NOW()))`
+
+// Service computes dashboard, task and property statistics. All aggregation
+// happens in SQL; buckets follow the user's time zone so a task completed at
+// 11pm local time counts towards that day.
+type Service struct {
+	db    *sql.DB
+	cache *cache.Loader
+}
+
+// NewService creates an analytics service. Dashboards are read through
+// loader and invalidated by the cache's event handler.
+func NewService(db *sql.DB, loader *cache.Loader) *Service {
+	return &Service{db: db, cache: loader}
+}
+
+// timezone returns userID's time zone if Postgres knows it, UTC otherwise
+func (s *Service) timezone(ctx context.Context, userID int) (string, error) {
+	var tz string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COALESCE((
+			SELECT z.name FROM users u JOIN pg_timezone_names z ON z.name = u.timezone WHERE u.id = $1
+		), 'UTC')
+	`, userID).Scan(&tz)
+	return tz, err
+}
+
+// Dashboard returns userID's summary statistics and recent activity
+func (s *Service) Dashboard(ctx context.Context, userID int) (*database.DashboardStats, error) {
+	return cache.Load(ctx, s.cache, cache.DashboardKey(userID), cache.DashboardTTL,
+		func(ctx context.Context) (*database.DashboardStats, error) {
+			return s.dashboard(ctx, userID)
+		})
+}
+
+func (s *Service) dashboard(ctx context.Context, userID int) (*database.DashboardStats, error) {
+	var stats database.DashboardStats
+	err := s.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'completed'),
+			COUNT(*) FILTER (WHERE status IN ('pending', 'in_progress') AND NOT `+overdue+`),
+			COUNT(*) FILTER (WHERE `+overdue+`),
+			(SELECT COUNT(*) FROM properties WHERE user_id = $1)
+		FROM tasks
+		WHERE user_id = $1
+	`, userID).Scan(&stats.TotalTasks, &stats.CompletedTasks, &stats.PendingTasks, &stats.OverdueTasks,
+		&stats.TotalProperties)
+	if err != nil {
+		return nil, err
+	}
+	if stats.TotalTasks > 0 {
+		stats.CompletionRate = float64(stats.CompletedTasks) / float64(stats.TotalTasks)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, 'task', title, updated_at FROM tasks WHERE user_id = $1
+		UNION ALL
+		SELECT id, 'property', name, updated_at FROM properties WHERE user_id = $1
+		UNION ALL
+		SELECT m.id, 'maintenance', m.title, m.created_at
+		FROM maintenance_records m JOIN properties p ON p.id = m.property_id
+		WHERE p.user_id = $1
+		ORDER BY 4 DESC
+		LIMIT 10
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats.RecentActivity = []database.ActivityItem{}
+	for rows.Next() {
+		var item database.ActivityItem
+		if err := rows.Scan(&item.ID, &item.Type, &item.Title, &item.Timestamp); err != nil {
+			return nil, err
+		}
+		stats.RecentActivity = append(stats.RecentActivity, item)
+	}
+	return &stats, rows.Err()
+}
+
+// Tasks returns userID's task statistics for period, one of week, month,
+// quarter or year
+func (s *Service) Tasks(ctx context.Context, userID int, period string) (*database.TaskAnalytics, error) {
+	if period == "" {
+		period = DefaultPeriod
+	}
+	w, ok := windows[period]
+	if !ok {
+		return nil, fmt.Errorf("unknown analytics period %q", period)
+	}
+	tz, err := s.timezone(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &database.TaskAnalytics{Period: period, Timezone: tz}
+	if result.TasksByStatus, err = s.countBy(ctx, "SELECT status, COUNT(*) FROM tasks WHERE user_id = $1 GROUP BY 1", userID); err != nil {
+		return nil, err
+	}
+	if result.TasksByPriority, err = s.countBy(ctx, "SELECT priority, COUNT(*) FROM tasks WHERE user_id = $1 GROUP BY 1", userID); err != nil {
+		return nil, err
+	}
+	if result.TasksByCategory, err = s.countBy(ctx, "SELECT category, COUNT(*) FROM tasks WHERE user_id = $1 GROUP BY 1", userID); err != nil {
+		return nil, err
+	}
+	zeroFill(result.TasksByStatus, database.TaskStatusValues)
+	zeroFill(result.TasksByPriority, database.TaskPriorityValues)
+
+	err = s.db.QueryRowContext(ctx, bucketsCTE+`
+		SELECT
+			COALESCE((COUNT(*) FILTER (WHERE status = 'completed'))::float / NULLIF(COUNT(*), 0), 0),
+			(SELECT AVG(EXTRACT(EPOCH FROM completed_at - created_at)) / 3600
+				FROM tasks
+				WHERE user_id = $1 AND status = 'completed' AND completed_at >= (SELECT start FROM bounds))
+		FROM tasks
+		WHERE user_id = $1 AND created_at >= (SELECT start FROM bounds)
+	`, userID, tz, w.unit, w.count).Scan(&result.CompletionRate, &result.MeanHoursToComplete)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, bucketsCTE+`,
+		created AS (
+			SELECT date_trunc($3, created_at AT TIME ZONE $2) AS bucket, COUNT(*) AS n
+			FROM tasks
+			WHERE user_id = $1 AND created_at >= (SELECT start FROM bounds)
+			GROUP BY 1
+		),
+		completed AS (
+			SELECT date_trunc($3, completed_at AT TIME ZONE $2) AS bucket, COUNT(*) AS n,
+				AVG(EXTRACT(EPOCH FROM completed_at - created_at)) / 3600 AS mean_hours
+			FROM tasks
+			WHERE user_id = $1 AND status = 'completed' AND completed_at >= (SELECT start FROM bounds)
+			GROUP BY 1
+		)
+		SELECT
+			to_char(b.bucket, 'YYYY-MM-DD'),
+			COALESCE(cr.n, 0),
+			COALESCE(co.n, 0),
+			co.mean_hours,
+			(SELECT COUNT(*)
+				FROM tasks t
+				WHERE t.user_id = $1
+					AND t.created_at < LEAST(b.bucket_end, NOW())
+					AND t.due_date < LEAST(b.bucket_end, NOW())
+					AND (t.completed_at IS NULL OR t.completed_at >= LEAST(b.bucket_end, NOW())))
+		FROM buckets b
+		LEFT JOIN created cr ON cr.bucket = b.bucket
+		LEFT JOIN completed co ON co.bucket = b.bucket
+		ORDER BY b.bucket
+	`, userID, tz, w.unit, w.count)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result.CompletionTrend = []database.TaskTrendPoint{}
+	result.OverdueTrend = []database.OverduePoint{}
+	for rows.Next() {
+		var point database.TaskTrendPoint
+		var overdueCount int
+		if err := rows.Scan(&point.Date, &point.Created, &point.Completed, &point.MeanHoursToComplete, &overdueCount); err != nil {
+			return nil, err
+		}
+		result.CompletionTrend = append(result.CompletionTrend, point)
+		result.OverdueTrend = append(result.OverdueTrend, database.OverduePoint{Date: point.Date, Overdue: overdueCount})
+	}
+	return result, rows.Err()
+}
+
+// bucketsCTE generates the buckets of a window as local timestamps. It takes
+// the time zone as $2, the date_trunc unit as $3 and the bucket count as $4,
+// and defines buckets(bucket, bucket_end) and bounds(start), where bucket_end
+// and start are instants: "local AT TIME ZONE tz" converts back.
+const bucketsCTE = `
+	WITH series AS (
+		SELECT generate_series(
+			date_trunc($3, NOW() AT TIME ZONE $2) - ($4::int - 1) * ('1 ' || $3)::interval,
+			date_trunc($3, NOW() AT TIME ZONE $2),
+			('1 ' || $3)::interval
+		) AS bucket
+	),
+	buckets AS (
+		SELECT bucket, (bucket + ('1 ' || $3)::interval) AT TIME ZONE $2 AS bucket_end FROM series
+	),
+	bounds AS (
+		SELECT MIN(bucket) AT TIME ZONE $2 AS start FROM series
+	)`
+
+// Properties returns maintenance spend and task statistics for userID's
+// properties. Monthly spend covers the last 12 months in the user's time zone.
+// Costs are reported per currency and never added across currencies.
+func (s *Service) Properties(ctx context.Context, userID int) (*database.PropertyAnalytics, error) {
+	tz, err := s.timezone(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &database.PropertyAnalytics{Timezone: tz}
+	if result.PropertiesByType, err = s.countBy(ctx, "SELECT type, COUNT(*) FROM properties WHERE user_id = $1 GROUP BY 1", userID); err != nil {
+		return nil, err
+	}
+	zeroFill(result.PropertiesByType, database.PropertyTypeValues)
+
+	if result.MaintenanceCosts, err = s.maintenanceCosts(ctx, userID); err != nil {
+		return nil, err
+	}
+	if result.MonthlySpend, err = s.monthlySpend(ctx, userID, tz); err != nil {
+		return nil, err
+	}
+	if result.TaskDistribution, err = s.taskDistribution(ctx, userID); err != nil {
+		return nil, err
+	}
+	if result.TopContractors, err = s.topContractors(ctx, userID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Service) maintenanceCosts(ctx context.Context, userID int) ([]database.PropertyMaintenanceCost, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT p.id, p.name, COALESCE(m.currency, $2), COALESCE(SUM(m.cost), 0), COALESCE(ROUND(AVG(m.cost), 2), 0),
+			COUNT(m.id)
+		FROM properties p
+		LEFT JOIN maintenance_records m ON m.property_id = p.id AND m.cost IS NOT NULL
+		WHERE p.user_id = $1
+		GROUP BY p.id, p.name, 3
+		ORDER BY 3, 4 DESC, p.name
+	`, userID, database.DefaultCurrency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	costs := []database.PropertyMaintenanceCost{}
+	for rows.Next() {
+		var c database.PropertyMaintenanceCost
+		err := rows.Scan(&c.PropertyID, &c.PropertyName, &c.Currency, &c.TotalCost, &c.AverageCost, &c.RecordCount)
+		if err != nil {
+			return nil, err
+		}
+		costs = append(costs, c)
+	}
+	return costs, rows.Err()
+}
+
+func (s *Service) monthlySpend(ctx context.Context, userID int, tz string) ([]database.MonthlySpend, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT p.id, p.name, to_char(date_trunc('month', m.completed_date AT TIME ZONE $2), 'YYYY-MM'), m.currency,
+			SUM(m.cost)
+		FROM maintenance_records m
+		JOIN properties p ON p.id = m.property_id
+		WHERE p.user_id = $1
+			AND m.cost IS NOT NULL
+			AND m.completed_date >= (date_trunc('month', NOW() AT TIME ZONE $2) - interval '11 months') AT TIME ZONE $2
+		GROUP BY p.id, p.name, 3, m.currency
+		ORDER BY 3, p.name, m.currency
+	`, userID, tz)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	spend := []database.MonthlySpend{}
+	for rows.Next() {
+		var m database.MonthlySpend
+		if err := rows.Scan(&m.PropertyID, &m.PropertyName, &m.Month, &m.Currency, &m.TotalCost); err != nil {
+			return nil, err
+		}
+		spend = append(spend, m)
+	}
+	return spend, rows.Err()
+}
+
+func (s *Service) taskDistribution(ctx context.Context, userID int) ([]database.PropertyTaskDistribution, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT p.id, p.name,
+			COUNT(t.id),
+			COUNT(t.id) FILTER (WHERE t.status = 'completed'),
+			COUNT(t.id) FILTER (WHERE t.status = 'overdue'
+				OR (t.status IN ('pending', 'in_progress') AND t.due_date < NOW()))
+		FROM properties p
+		LEFT JOIN tasks t ON t.property_id = p.id
+		WHERE p.user_id = $1
+		GROUP BY p.id, p.name
+		ORDER BY 3 DESC, p.name
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	distribution := []database.PropertyTaskDistribution{}
+	for rows.Next() {
+		var d database.PropertyTaskDistribution
+		if err := rows.Scan(&d.PropertyID, &d.PropertyName, &d.TaskCount, &d.CompletedTasks, &d.OverdueTasks); err != nil {
+			return nil, err
+		}
+		distribution = append(distribution, d)
+	}
+	return distribution, rows.Err()
+}
+
+// topContractorLimit bounds the contractors reported by Properties
+const topContractorLimit = 10
+
+func (s *Service) topContractors(ctx context.Context, userID int) ([]database.ContractorStats, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT MIN(TRIM(m.contractor)), m.currency, COUNT(*), COALESCE(SUM(m.cost), 0), MAX(m.completed_date)
+		FROM maintenance_records m
+		JOIN properties p ON p.id = m.property_id
+		WHERE p.user_id = $1 AND TRIM(COALESCE(m.contractor, '')) <> ''
+		GROUP BY LOWER(TRIM(m.contractor)), m.currency
+		ORDER BY 3 DESC, 4 DESC
+		LIMIT $2
+	`, userID, topContractorLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	contractors := []database.ContractorStats{}
+	for rows.Next() {
+		var c database.ContractorStats
+		if err := rows.Scan(&c.Contractor, &c.Currency, &c.Jobs, &c.TotalCost, &c.LastJobAt); err != nil {
+			return nil, err
+		}
+		contractors = append(contractors, c)
+	}
+	return contractors, rows.Err()
+}
+
+// countBy runs a two-column (key, count) query into a map
+func (s *Service) countBy(ctx context.Context, query string, args ...interface{}) (map[string]int, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var key string
+		var n int
+		if err := rows.Scan(&key, &n); err != nil {
+			return nil, err
+		}
+		counts[key] = n
+	}
+	return counts, rows.Err()
+}
+
+// zeroFill adds every enum value missing from counts with a zero count, so
+// charts need no defaults
+func zeroFill[T ~string](counts map[string]int, values []T) {
+	for _, v := range values {
+		if _, ok := counts[string(v)]; !ok {
+			counts[string(v)] = 0
+		}
+	}
+}
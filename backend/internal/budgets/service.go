@@ -0,0 +1,439 @@
+package budgets
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+	"github.com/myideascope/HomeGenie/backend/pkg/events"
+)
+
+// ErrNotFound is returned when a budget does not exist in the property
+var ErrNotFound = errors.New("budget not found")
+
+// ErrPropertyNotFound is returned when a property does not exist or belongs to another user
+var ErrPropertyNotFound = errors.New("property not found")
+
+// ErrExists is returned when a property already has a budget for the category and year
+var ErrExists = errors.New("property already has a budget for this category and year")
+
+// ErrFractionalAmount is returned when an update leaves a budget with a
+// fractional amount in a currency without decimal places
+var ErrFractionalAmount = errors.New("amount must be whole in a currency without decimal places")
+
+// defaultThresholds are the percentages alerted when a budget names none
+var defaultThresholds = []int64{80, 100}
+
+// Service manages annual property budgets and sends an alert notification
+// when recorded spend crosses one of a budget's thresholds
+type Service struct {
+	db *sql.DB
+}
+
+// NewService creates a budget service
+func NewService(db *sql.DB) *Service {
+	return &Service{db: db}
+}
+
+const budgetColumns = `b.id, b.property_id, b.year, b.category, b.amount, b.currency, b.thresholds, b.created_at,
+	b.updated_at`
+
+// spentColumn totals the line items counted against budget b: those in its
+// category and currency on maintenance completed during its year in the time
+// zone $1
+const spentColumn = `COALESCE((
+		SELECT SUM(i.amount)
+		FROM maintenance_cost_items i JOIN maintenance_records m ON m.id = i.maintenance_record_id
+		WHERE m.property_id = b.property_id AND m.currency = b.currency AND i.category = b.category
+			AND m.completed_date >= make_timestamptz(b.year, 1, 1, 0, 0, 0, $1)
+			AND m.completed_date < make_timestamptz(b.year + 1, 1, 1, 0, 0, 0, $1)
+	), 0)`
+
+func scanBudget(row interface{ Scan(...interface{}) error }) (*database.Budget, error) {
+	var b database.Budget
+	err := row.Scan(&b.ID, &b.PropertyID, &b.Year, &b.Category, &b.Amount, &b.Currency, pq.Array(&b.Thresholds),
+		&b.CreatedAt, &b.UpdatedAt, &b.Spent)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	b.Remaining = b.Amount - b.Spent
+	b.PercentUsed = int(int64(b.Spent) * 100 / int64(b.Amount))
+	return &b, nil
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// timezone checks that userID owns propertyID and returns the owner's time
+// zone if Postgres knows it, UTC otherwise. Budget years follow it.
+func timezone(ctx context.Context, q querier, userID, propertyID int) (string, error) {
+	var tz string
+	err := q.QueryRowContext(ctx, `
+		SELECT COALESCE((
+			SELECT z.name FROM users u JOIN pg_timezone_names z ON z.name = u.timezone WHERE u.id = p.user_id
+		), 'UTC')
+		FROM properties p
+		WHERE p.id = $1 AND p.user_id = $2
+	`, propertyID, userID).Scan(&tz)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrPropertyNotFound
+	}
+	return tz, err
+}
+
+// thresholds sorts and deduplicates requested thresholds, defaulting to 80
+// and 100 percent
+func thresholds(requested []int64) []int64 {
+	if len(requested) == 0 {
+		return append([]int64(nil), defaultThresholds...)
+	}
+	sorted := append([]int64(nil), requested...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	unique := sorted[:1]
+	for _, t := range sorted[1:] {
+		if t != unique[len(unique)-1] {
+			unique = append(unique, t)
+		}
+	}
+	return unique
+}
+
+// List returns the budgets of a property owned by userID with their spend,
+// latest year first
+func (s *Service) List(ctx context.Context, userID, propertyID int, filters database.BudgetFilters) ([]database.Budget, error) {
+	tz, err := timezone(ctx, s.db, userID, propertyID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+budgetColumns+`, `+spentColumn+`
+		FROM property_budgets b
+		WHERE b.property_id = $2 AND ($3::int IS NULL OR b.year = $3)
+		ORDER BY b.year DESC, b.category
+	`, tz, propertyID, filters.Year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	budgets := []database.Budget{}
+	for rows.Next() {
+		b, err := scanBudget(rows)
+		if err != nil {
+			return nil, err
+		}
+		budgets = append(budgets, *b)
+	}
+	return budgets, rows.Err()
+}
+
+func get(ctx context.Context, q querier, tz string, propertyID, id int) (*database.Budget, error) {
+	return scanBudget(q.QueryRowContext(ctx, `
+		SELECT `+budgetColumns+`, `+spentColumn+`
+		FROM property_budgets b
+		WHERE b.id = $2 AND b.property_id = $3
+	`, tz, id, propertyID))
+}
+
+// Create sets a budget for a property owned by userID. Spend already
+// recorded is alerted straight away.
+func (s *Service) Create(ctx context.Context, userID, propertyID int, req database.CreateBudgetRequest) (*database.Budget, error) {
+	tz, err := timezone(ctx, s.db, userID, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	currency := req.Currency
+	if currency == "" {
+		currency = database.DefaultCurrency
+	}
+
+	var id int
+	err = s.db.QueryRowContext(ctx, `
+		INSERT INTO property_budgets (property_id, year, category, amount, currency, thresholds)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, propertyID, req.Year, req.Category, req.Amount, currency, pq.Array(thresholds(req.Thresholds))).Scan(&id)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return nil, ErrExists
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := get(ctx, s.db, tz, propertyID, id)
+	if err != nil {
+		return nil, err
+	}
+	s.check(ctx, userID, b)
+	return b, nil
+}
+
+// Update changes a budget of a property owned by userID. Thresholds that
+// are no longer crossed, or no longer set, are alerted again when spend
+// next crosses them.
+func (s *Service) Update(ctx context.Context, userID, propertyID, id int, req database.UpdateBudgetRequest) (*database.Budget, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	tz, err := timezone(ctx, tx, userID, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	b, err := get(ctx, tx, tz, propertyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Amount != nil {
+		b.Amount = *req.Amount
+	}
+	if req.Currency != nil {
+		b.Currency = *req.Currency
+	}
+	if req.Thresholds != nil {
+		b.Thresholds = thresholds(req.Thresholds)
+	}
+	if !b.Amount.FitsCurrency(b.Currency) {
+		return nil, ErrFractionalAmount
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE property_budgets SET amount = $1, currency = $2, thresholds = $3, updated_at = NOW()
+		WHERE id = $4
+	`, b.Amount, b.Currency, pq.Array(b.Thresholds), id)
+	if err != nil {
+		return nil, err
+	}
+	if b, err = get(ctx, tx, tz, propertyID, id); err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM budget_alerts
+		WHERE budget_id = $1 AND (threshold <> ALL($2::int[]) OR threshold * $3::numeric > $4::numeric * 100)
+	`, id, pq.Array(b.Thresholds), b.Amount, b.Spent)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.check(ctx, userID, b)
+	return b, nil
+}
+
+// Delete removes a budget of a property owned by userID
+func (s *Service) Delete(ctx context.Context, userID, propertyID, id int) error {
+	if _, err := timezone(ctx, s.db, userID, propertyID); err != nil {
+		return err
+	}
+	result, err := s.db.ExecContext(ctx,
+		`DELETE FROM property_budgets WHERE id = $1 AND property_id = $2`, id, propertyID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// HandleEvent is an events.Handler that checks the budgets of a property
+// for the year maintenance was recorded in
+func (s *Service) HandleEvent(ctx context.Context, evt events.Event) error {
+	if evt.Type != events.MaintenanceRecorded {
+		return nil
+	}
+	var record struct {
+		PropertyID    int       `json:"propertyId"`
+		CompletedDate time.Time `json:"completedDate"`
+	}
+	if err := evt.Decode(&record); err != nil || record.PropertyID == 0 {
+		return nil
+	}
+
+	tz, err := timezone(ctx, s.db, evt.UserID, record.PropertyID)
+	if errors.Is(err, ErrPropertyNotFound) {
+		// Deleted since, along with its budgets
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+budgetColumns+`, `+spentColumn+`
+		FROM property_budgets b
+		WHERE b.property_id = $2 AND b.year = EXTRACT(YEAR FROM $3::timestamptz AT TIME ZONE $1)::int
+	`, tz, record.PropertyID, record.CompletedDate)
+	if err != nil {
+		return err
+	}
+	var budgets []*database.Budget
+	for rows.Next() {
+		b, err := scanBudget(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		budgets = append(budgets, b)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, b := range budgets {
+		if err := s.alert(ctx, evt.UserID, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// check alerts b after a change made through the API. Failures are only
+// logged: the change itself has been saved, and the next maintenance
+// recorded on the property checks again.
+func (s *Service) check(ctx context.Context, userID int, b *database.Budget) {
+	if err := s.alert(ctx, userID, b); err != nil {
+		log.Printf("Failed to check budget %d for alerts: %v", b.ID, err)
+	}
+}
+
+// alert notifies userID the first time b's spend crosses each of its
+// thresholds. When several are crossed at once, only the highest is
+// notified. Users who turned maintenance alerts off are not notified, but
+// the thresholds still count as alerted.
+func (s *Service) alert(ctx context.Context, userID int, b *database.Budget) error {
+	crossed := crossedThresholds(b)
+	if len(crossed) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		INSERT INTO budget_alerts (budget_id, threshold)
+		SELECT $1, unnest($2::int[])
+		ON CONFLICT DO NOTHING
+		RETURNING threshold
+	`, b.ID, pq.Array(crossed))
+	if err != nil {
+		return err
+	}
+	var highest int64
+	for rows.Next() {
+		var t int64
+		if err := rows.Scan(&t); err != nil {
+			rows.Close()
+			return err
+		}
+		highest = max(highest, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if highest == 0 {
+		return nil
+	}
+
+	var (
+		property string
+		enabled  bool
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT p.name, COALESCE(st.maintenance_alerts, true)
+		FROM properties p LEFT JOIN notification_settings st ON st.user_id = p.user_id
+		WHERE p.id = $1
+	`, b.PropertyID).Scan(&property, &enabled)
+	if err != nil {
+		return err
+	}
+	if enabled {
+		n, err := notify(ctx, tx, userID, b, property, highest)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			`UPDATE budget_alerts SET notification_id = $1 WHERE budget_id = $2 AND threshold = $3`,
+			n.ID, b.ID, highest)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// crossedThresholds lists the thresholds b's spend has reached, whether or
+// not they have been alerted yet
+func crossedThresholds(b *database.Budget) []int64 {
+	var crossed []int64
+	for _, t := range b.Thresholds {
+		if int64(b.Spent)*100 >= int64(b.Amount)*t {
+			crossed = append(crossed, t)
+		}
+	}
+	return crossed
+}
+
+// notify creates the alert notification for b crossing threshold and
+// records its event
+func notify(ctx context.Context, tx *sql.Tx, userID int, b *database.Budget, property string, threshold int64) (*database.Notification, error) {
+	category := string(b.Category)
+	category = strings.ToUpper(category[:1]) + category[1:]
+
+	n := database.Notification{
+		UserID:     userID,
+		Title:      fmt.Sprintf("%s budget %d%% used", category, threshold),
+		Type:       database.NotificationTypeAlert,
+		Priority:   database.NotificationPriorityMedium,
+		PropertyID: &b.PropertyID,
+	}
+	if threshold >= 100 {
+		n.Title = fmt.Sprintf("Over budget: %s", strings.ToLower(category))
+		n.Priority = database.NotificationPriorityHigh
+	}
+	n.Message = fmt.Sprintf("%s spending at %s in %d is %s %s, %d%% of its %s %s budget.",
+		category, property, b.Year, b.Spent, b.Currency, b.PercentUsed, b.Amount, b.Currency)
+
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO notifications (user_id, title, message, type, priority, property_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, read, created_at, updated_at
+	`, n.UserID, n.Title, n.Message, n.Type, n.Priority, n.PropertyID).Scan(&n.ID, &n.Read, &n.CreatedAt, &n.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	evt, err := events.New(events.NotificationCreated, events.AggregateNotification, n.ID, userID, n)
+	if err != nil {
+		return nil, err
+	}
+	if err := events.Append(ctx, tx, evt); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
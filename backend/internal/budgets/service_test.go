@@ -0,0 +1,315 @@
+package budgets
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+	"github.com/myideascope/HomeGenie/backend/pkg/events"
+)
+
+func TestThresholds(t *testing.T) {
+	tests := []struct {
+		requested []int64
+		want      []int64
+	}{
+		{nil, []int64{80, 100}},
+		{[]int64{}, []int64{80, 100}},
+		{[]int64{100, 50, 75}, []int64{50, 75, 100}},
+		{[]int64{90, 90, 120, 90}, []int64{90, 120}},
+	}
+	for _, tt := range tests {
+		if got := thresholds(tt.requested); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("thresholds(%v) = %v, want %v", tt.requested, got, tt.want)
+		}
+	}
+}
+
+func TestCrossedThresholds(t *testing.T) {
+	tests := []struct {
+		name  string
+		spent database.Amount
+		want  []int64
+	}{
+		{"none", 49999, nil},
+		{"exactly one", 50000, []int64{50}},
+		{"one", 79999, []int64{50}},
+		{"several at once", 100000, []int64{50, 80, 100}},
+		{"over budget", 150000, []int64{50, 80, 100, 150}},
+	}
+	for _, tt := range tests {
+		b := &database.Budget{Amount: 100000, Spent: tt.spent, Thresholds: []int64{50, 80, 100, 150}}
+		if got := crossedThresholds(b); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("crossedThresholds(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Percentages are compared exactly, without rounding the spend
+	b := &database.Budget{Amount: 300, Spent: 100, Thresholds: []int64{33, 34}}
+	if got := crossedThresholds(b); !reflect.DeepEqual(got, []int64{33}) {
+		t.Errorf("crossedThresholds(1.00 of 3.00) = %v, want [33]", got)
+	}
+}
+
+// testDB migrates a fresh schema in the database named by TEST_DATABASE_URL,
+// skipping the test when it is unset. The handle uses a single connection
+// whose search_path is the new schema.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	ctx := context.Background()
+	schema := fmt.Sprintf("budgets_test_%d", time.Now().UnixNano())
+	if _, err := db.ExecContext(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	t.Cleanup(func() { db.ExecContext(ctx, "DROP SCHEMA "+schema+" CASCADE") })
+	if _, err := db.ExecContext(ctx, "SET search_path TO "+schema); err != nil {
+		t.Fatalf("failed to set search_path: %v", err)
+	}
+	if err := database.RunMigrationsContext(ctx, db); err != nil {
+		t.Fatalf("RunMigrationsContext() error = %v", err)
+	}
+	return db
+}
+
+// fixture is a user with properties whose maintenance is recorded directly
+type fixture struct {
+	t      *testing.T
+	db     *sql.DB
+	userID int
+}
+
+func newFixture(t *testing.T, timezone string) *fixture {
+	db := testDB(t)
+	f := &fixture{t: t, db: db}
+	err := db.QueryRow(`
+		INSERT INTO users (email, password_hash, first_name, last_name, timezone)
+		VALUES ('ana@example.com', 'x', 'Ana', 'García', $1)
+		RETURNING id
+	`, timezone).Scan(&f.userID)
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	return f
+}
+
+func (f *fixture) property(name string) int {
+	var id int
+	err := f.db.QueryRow(`
+		INSERT INTO properties (user_id, name, address, type) VALUES ($1, $2, '1 Main St', 'house') RETURNING id
+	`, f.userID, name).Scan(&id)
+	if err != nil {
+		f.t.Fatalf("failed to create property: %v", err)
+	}
+	return id
+}
+
+// record stores maintenance with a single line item and returns the event
+// the properties service records for it
+func (f *fixture) record(propertyID int, completed time.Time, currency string, category database.CostCategory, amount database.Amount) events.Event {
+	var id int
+	err := f.db.QueryRow(`
+		INSERT INTO maintenance_records (property_id, title, description, completed_date, cost, currency)
+		VALUES ($1, 'Repair', 'Repair', $2, $3, $4)
+		RETURNING id
+	`, propertyID, completed, amount, currency).Scan(&id)
+	if err != nil {
+		f.t.Fatalf("failed to record maintenance: %v", err)
+	}
+	_, err = f.db.Exec(`
+		INSERT INTO maintenance_cost_items (maintenance_record_id, category, amount) VALUES ($1, $2, $3)
+	`, id, string(category), amount)
+	if err != nil {
+		f.t.Fatalf("failed to record cost item: %v", err)
+	}
+
+	evt, err := events.New(events.MaintenanceRecorded, events.AggregateMaintenance, id, f.userID, map[string]interface{}{
+		"propertyId": propertyID, "completedDate": completed,
+	})
+	if err != nil {
+		f.t.Fatalf("events.New() error = %v", err)
+	}
+	return evt
+}
+
+// notifications lists the titles of the user's notifications, oldest first
+func (f *fixture) notifications() []string {
+	rows, err := f.db.Query(`SELECT title FROM notifications WHERE user_id = $1 ORDER BY id`, f.userID)
+	if err != nil {
+		f.t.Fatalf("failed to list notifications: %v", err)
+	}
+	defer rows.Close()
+	var titles []string
+	for rows.Next() {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			f.t.Fatalf("failed to scan notification: %v", err)
+		}
+		titles = append(titles, title)
+	}
+	return titles
+}
+
+// alerted lists the thresholds recorded as alerted for a budget
+func (f *fixture) alerted(budgetID int) []int64 {
+	rows, err := f.db.Query(`SELECT threshold FROM budget_alerts WHERE budget_id = $1 ORDER BY threshold`, budgetID)
+	if err != nil {
+		f.t.Fatalf("failed to list budget alerts: %v", err)
+	}
+	defer rows.Close()
+	var thresholds []int64
+	for rows.Next() {
+		var t int64
+		if err := rows.Scan(&t); err != nil {
+			f.t.Fatalf("failed to scan budget alert: %v", err)
+		}
+		thresholds = append(thresholds, t)
+	}
+	return thresholds
+}
+
+func TestHandleEventAlerts(t *testing.T) {
+	f := newFixture(t, "UTC")
+	ctx := context.Background()
+	s := NewService(f.db)
+	propertyID := f.property("Lake house")
+
+	b, err := s.Create(ctx, f.userID, propertyID, database.CreateBudgetRequest{
+		Year: 2026, Category: database.CostCategoryParts, Amount: 100000, Thresholds: []int64{50, 80, 100},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	march := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		name    string
+		amount  database.Amount
+		alerted []int64
+		titles  []string
+	}{
+		{"under every threshold", 10000, nil, nil},
+		{"crossing one", 45000, []int64{50}, []string{"Parts budget 50% used"}},
+		{"crossing several at once", 50000, []int64{50, 80, 100},
+			[]string{"Parts budget 50% used", "Over budget: parts"}},
+		{"crossed again", 1000, []int64{50, 80, 100},
+			[]string{"Parts budget 50% used", "Over budget: parts"}},
+	}
+	for _, step := range steps {
+		evt := f.record(propertyID, march, "USD", database.CostCategoryParts, step.amount)
+		if err := s.HandleEvent(ctx, evt); err != nil {
+			t.Fatalf("%s: HandleEvent() error = %v", step.name, err)
+		}
+		// Redelivered events change nothing
+		if err := s.HandleEvent(ctx, evt); err != nil {
+			t.Fatalf("%s: redelivered HandleEvent() error = %v", step.name, err)
+		}
+
+		if got := f.alerted(b.ID); !reflect.DeepEqual(got, step.alerted) {
+			t.Errorf("%s: alerted thresholds = %v, want %v", step.name, got, step.alerted)
+		}
+		if got := f.notifications(); !reflect.DeepEqual(got, step.titles) {
+			t.Errorf("%s: notifications = %q, want %q", step.name, got, step.titles)
+		}
+	}
+}
+
+func TestHandleEventAlertsDisabled(t *testing.T) {
+	f := newFixture(t, "UTC")
+	ctx := context.Background()
+	s := NewService(f.db)
+	propertyID := f.property("Lake house")
+	if _, err := f.db.Exec(`INSERT INTO notification_settings (user_id, maintenance_alerts) VALUES ($1, false)`, f.userID); err != nil {
+		t.Fatalf("failed to turn alerts off: %v", err)
+	}
+
+	b, err := s.Create(ctx, f.userID, propertyID, database.CreateBudgetRequest{
+		Year: 2026, Category: database.CostCategoryParts, Amount: 100000,
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	evt := f.record(propertyID, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), "USD", database.CostCategoryParts, 90000)
+	if err := s.HandleEvent(ctx, evt); err != nil {
+		t.Fatalf("HandleEvent() error = %v", err)
+	}
+
+	if got := f.alerted(b.ID); !reflect.DeepEqual(got, []int64{80}) {
+		t.Errorf("alerted thresholds = %v, want [80] counted though not sent", got)
+	}
+	if got := f.notifications(); len(got) != 0 {
+		t.Errorf("notifications = %q, want none", got)
+	}
+}
+
+func TestSpent(t *testing.T) {
+	// Budget years follow the owner's time zone
+	f := newFixture(t, "America/New_York")
+	ctx := context.Background()
+	s := NewService(f.db)
+	propertyID := f.property("Lake house")
+	otherID := f.property("City flat")
+
+	if _, err := s.Create(ctx, f.userID, propertyID, database.CreateBudgetRequest{
+		Year: 2026, Category: database.CostCategoryParts, Amount: 100000, Currency: "USD",
+	}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := s.Create(ctx, f.userID, otherID, database.CreateBudgetRequest{
+		Year: 2026, Category: database.CostCategoryParts, Amount: 10000000, Currency: "JPY",
+	}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	utc := func(year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+	}
+	f.record(propertyID, utc(2026, 3, 1, 12), "USD", database.CostCategoryParts, 10000)
+	f.record(propertyID, utc(2026, 3, 2, 12), "USD", database.CostCategoryLabor, 20000)
+	f.record(propertyID, utc(2026, 3, 3, 12), "EUR", database.CostCategoryParts, 40000)
+	f.record(propertyID, utc(2026, 3, 4, 12), "JPY", database.CostCategoryParts, 500000)
+	// 22:00 on 31 December 2026 in New York, and 22:00 on 31 December 2025
+	f.record(propertyID, utc(2027, 1, 1, 3), "USD", database.CostCategoryParts, 1600)
+	f.record(propertyID, utc(2026, 1, 1, 3), "USD", database.CostCategoryParts, 3200)
+	f.record(otherID, utc(2026, 3, 5, 12), "USD", database.CostCategoryParts, 80000)
+	f.record(otherID, utc(2026, 3, 6, 12), "JPY", database.CostCategoryParts, 700000)
+
+	tests := []struct {
+		propertyID int
+		currency   string
+		spent      database.Amount
+	}{
+		{propertyID, "USD", 11600},
+		{otherID, "JPY", 700000},
+	}
+	for _, tt := range tests {
+		budgets, err := s.List(ctx, f.userID, tt.propertyID, database.BudgetFilters{})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if len(budgets) != 1 || budgets[0].Currency != tt.currency {
+			t.Fatalf("List() = %+v, want one %s budget", budgets, tt.currency)
+		}
+		b := budgets[0]
+		if b.Spent != tt.spent || b.Remaining != b.Amount-tt.spent {
+			t.Errorf("%s budget spent %s with %s remaining, want %s spent", tt.currency, b.Spent, b.Remaining, tt.spent)
+		}
+	}
+}
@@ -0,0 +1,472 @@
+package files
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+	"github.com/myideascope/HomeGenie/backend/pkg/events"
+)
+
+// ErrParentNotFound is returned when the entity being attached to does not
+// exist or belongs to another user
+var ErrParentNotFound = errors.New("attachment parent not found")
+
+// ErrAttachmentNotFound is returned when an attachment does not exist on its parent
+var ErrAttachmentNotFound = errors.New("attachment not found")
+
+// ErrAlreadyAttached is returned when a file is attached to the same parent twice
+var ErrAlreadyAttached = errors.New("file is already attached")
+
+// ErrInvalidOrder is returned when a reorder does not list each of the
+// parent's attachments exactly once
+var ErrInvalidOrder = errors.New("attachment order must list every attachment exactly once")
+
+// ParentKind is the type of entity a file is attached to
+type ParentKind string
+
+const (
+	ParentTask        ParentKind = "task"
+	ParentProperty    ParentKind = "property"
+	ParentRoom        ParentKind = "room"
+	ParentMaintenance ParentKind = "maintenance"
+)
+
+// Parent identifies the entity attachments belong to. Rooms and
+// maintenance records are addressed within PropertyID.
+type Parent struct {
+	Kind       ParentKind
+	ID         int
+	PropertyID int
+}
+
+// attachmentTable describes the join table of one kind of parent
+type attachmentTable struct {
+	table  string
+	column string
+	// owned selects a row when parent $1 belongs to user $2, within
+	// property $3 where scoped
+	owned  string
+	scoped bool
+}
+
+var attachmentTables = map[ParentKind]attachmentTable{
+	ParentTask: {
+		table: "task_attachments", column: "task_id",
+		owned: `SELECT 1 FROM tasks WHERE id = $1 AND user_id = $2`,
+	},
+	ParentProperty: {
+		table: "property_attachments", column: "property_id",
+		owned: `SELECT 1 FROM properties WHERE id = $1 AND user_id = $2`,
+	},
+	ParentRoom: {
+		table: "room_attachments", column: "room_id",
+		owned: `SELECT 1 FROM rooms r JOIN properties p ON p.id = r.property_id
+			WHERE r.id = $1 AND p.user_id = $2 AND p.id = $3`,
+		scoped: true,
+	},
+	ParentMaintenance: {
+		table: "maintenance_record_attachments", column: "maintenance_record_id",
+		owned: `SELECT 1 FROM maintenance_records m JOIN properties p ON p.id = m.property_id
+			WHERE m.id = $1 AND p.user_id = $2 AND p.id = $3`,
+		scoped: true,
+	},
+}
+
+// orphaned matches files, aliased f, that are attached nowhere and either
+// were attached before or have waited the grace period, in seconds, to be.
+// Avatars are never attached and are excluded.
+func orphaned(grace string) string {
+	var b strings.Builder
+	b.WriteString(`f.category <> 'avatar'`)
+	for _, kind := range []ParentKind{ParentTask, ParentProperty, ParentRoom, ParentMaintenance} {
+		fmt.Fprintf(&b, ` AND NOT EXISTS (SELECT 1 FROM %s WHERE file_id = f.id)`, attachmentTables[kind].table)
+	}
+	fmt.Fprintf(&b, ` AND (f.attached_at IS NOT NULL OR f.created_at < NOW() - %s * INTERVAL '1 second')`, grace)
+	return b.String()
+}
+
+// cleanupInterval is how often Run sweeps for orphaned files
+const cleanupInterval = time.Hour
+
+// rescanInterval is how often Run retries the scans of pending files
+const rescanInterval = time.Minute
+
+// querier and lister are satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type lister interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+const attachmentColumns = `a.id, a.file_id, a.position, a.caption, a.created_at, ` + fileColumns
+
+func scanAttachment(row interface{ Scan(...interface{}) error }) (*database.Attachment, error) {
+	var a database.Attachment
+	dest := append([]interface{}{&a.ID, &a.FileID, &a.Position, &a.Caption, &a.CreatedAt}, fileFields(&a.File)...)
+	err := row.Scan(dest...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// checkParent returns parent's join table once it is confirmed to belong to userID
+func checkParent(ctx context.Context, q querier, userID int, parent Parent) (attachmentTable, error) {
+	t, ok := attachmentTables[parent.Kind]
+	if !ok {
+		return attachmentTable{}, fmt.Errorf("unknown attachment parent %q", parent.Kind)
+	}
+	args := []interface{}{parent.ID, userID}
+	if t.scoped {
+		args = append(args, parent.PropertyID)
+	}
+	var one int
+	err := q.QueryRowContext(ctx, t.owned, args...).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return attachmentTable{}, ErrParentNotFound
+	}
+	return t, err
+}
+
+// Attachments lists parent's attachments in order, with fresh download URLs
+func (s *Service) Attachments(ctx context.Context, userID int, parent Parent) ([]database.Attachment, error) {
+	t, err := checkParent(ctx, s.db, userID, parent)
+	if err != nil {
+		return nil, err
+	}
+	return s.attachments(ctx, s.db, t, parent.ID)
+}
+
+func (s *Service) attachments(ctx context.Context, q lister, t attachmentTable, parentID int) ([]database.Attachment, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT `+attachmentColumns+`
+		FROM `+t.table+` a JOIN files f ON f.id = a.file_id
+		WHERE a.`+t.column+` = $1
+		ORDER BY a.position, a.id`,
+		parentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := []database.Attachment{}
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, *a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range attachments {
+		if _, err := s.withURL(ctx, &attachments[i].File); err != nil {
+			return nil, err
+		}
+	}
+	return attachments, nil
+}
+
+// Attach attaches one of userID's uploaded files to parent, after its
+// existing attachments. Quarantined files return ErrQuarantined.
+func (s *Service) Attach(ctx context.Context, userID int, parent Parent, req database.AttachFileRequest) (*database.Attachment, error) {
+	var key string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT file_path FROM files WHERE user_id = $1 AND filename = $2`, userID, req.Filename).Scan(&key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Holding the key's lock keeps the orphan sweep from deleting the file
+	// between the lookup and the insert
+	if err := lockKey(ctx, tx, key); err != nil {
+		return nil, err
+	}
+	t, err := checkParent(ctx, tx, userID, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		fileID int
+		status database.FileStatus
+		threat *string
+	)
+	err = tx.QueryRowContext(ctx, `
+		UPDATE files SET attached_at = COALESCE(attached_at, NOW())
+		WHERE user_id = $1 AND filename = $2
+		RETURNING id, status, scan_threat`,
+		userID, req.Filename,
+	).Scan(&fileID, &status, &threat)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Pending files may be attached and get their URLs once they pass
+	if status == database.FileStatusQuarantined {
+		return nil, quarantined(&database.File{ScanThreat: threat})
+	}
+
+	var id int
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO `+t.table+` (`+t.column+`, file_id, position, caption)
+		SELECT $1, $2, COALESCE(MAX(position) + 1, 0), NULLIF($3, '')
+		FROM `+t.table+` WHERE `+t.column+` = $1
+		RETURNING id`,
+		parent.ID, fileID, req.Caption,
+	).Scan(&id)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return nil, ErrAlreadyAttached
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.getAttachment(ctx, tx, t, parent.ID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// UpdateAttachment changes the caption of one of parent's attachments
+func (s *Service) UpdateAttachment(ctx context.Context, userID int, parent Parent, id int,
+	req database.UpdateAttachmentRequest) (*database.Attachment, error) {
+	t, err := checkParent(ctx, s.db, userID, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Caption != nil {
+		result, err := s.db.ExecContext(ctx,
+			`UPDATE `+t.table+` SET caption = NULLIF($1, '') WHERE id = $2 AND `+t.column+` = $3`,
+			*req.Caption, id, parent.ID)
+		if err != nil {
+			return nil, err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return nil, ErrAttachmentNotFound
+		}
+	}
+	return s.getAttachment(ctx, s.db, t, parent.ID, id)
+}
+
+// ReorderAttachments sets the order of parent's attachments. ids must list
+// each of them exactly once.
+func (s *Service) ReorderAttachments(ctx context.Context, userID int, parent Parent, ids []int) ([]database.Attachment, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	t, err := checkParent(ctx, tx, userID, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	// Positions follow the order of ids; a count mismatch means ids had
+	// duplicates, strangers or gaps
+	var total, distinct int
+	err = tx.QueryRowContext(ctx, `
+		SELECT (SELECT COUNT(*) FROM `+t.table+` WHERE `+t.column+` = $2),
+			(SELECT COUNT(DISTINCT o.id) FROM unnest($1::int[]) AS o(id))`,
+		pq.Array(ids), parent.ID,
+	).Scan(&total, &distinct)
+	if err != nil {
+		return nil, err
+	}
+	if total != len(ids) || distinct != len(ids) {
+		return nil, ErrInvalidOrder
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE `+t.table+` a SET position = o.ord - 1
+		FROM unnest($1::int[]) WITH ORDINALITY AS o(id, ord)
+		WHERE a.id = o.id AND a.`+t.column+` = $2`,
+		pq.Array(ids), parent.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := result.RowsAffected(); int(n) != len(ids) {
+		return nil, ErrInvalidOrder
+	}
+
+	attachments, err := s.attachments(ctx, tx, t, parent.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// Detach removes one of parent's attachments. The file is deleted too once
+// it is attached nowhere else.
+func (s *Service) Detach(ctx context.Context, userID int, parent Parent, id int) error {
+	t, err := checkParent(ctx, s.db, userID, parent)
+	if err != nil {
+		return err
+	}
+
+	var fileID int
+	var key string
+	err = s.db.QueryRowContext(ctx, `
+		DELETE FROM `+t.table+` a USING files f
+		WHERE a.id = $1 AND a.`+t.column+` = $2 AND f.id = a.file_id
+		RETURNING f.id, f.file_path`,
+		id, parent.ID,
+	).Scan(&fileID, &key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAttachmentNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = s.remove(ctx, key, "f.id = $2 AND "+orphaned("$3"),
+		fileID, s.cfg.OrphanGrace.Seconds())
+	return err
+}
+
+func (s *Service) getAttachment(ctx context.Context, q querier, t attachmentTable, parentID, id int) (*database.Attachment, error) {
+	a, err := scanAttachment(q.QueryRowContext(ctx, `
+		SELECT `+attachmentColumns+`
+		FROM `+t.table+` a JOIN files f ON f.id = a.file_id
+		WHERE a.id = $1 AND a.`+t.column+` = $2`,
+		id, parentID,
+	))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.withURL(ctx, &a.File); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// CleanupOrphans deletes files that are no longer attached to anything, or
+// were uploaded but never attached within the grace period. Only userID's
+// files are considered unless userID is 0. It returns how many files were
+// deleted.
+func (s *Service) CleanupOrphans(ctx context.Context, userID int) (int, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT f.id, f.file_path FROM files f
+		WHERE ($1 = 0 OR f.user_id = $1) AND `+orphaned("$2")+`
+		ORDER BY f.id`,
+		userID, s.cfg.OrphanGrace.Seconds(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	type orphan struct {
+		id  int
+		key string
+	}
+	var orphans []orphan
+	for rows.Next() {
+		var o orphan
+		if err := rows.Scan(&o.id, &o.key); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		orphans = append(orphans, o)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	// Each file is rechecked under its key's lock, in case it was attached
+	// since it was listed
+	removed := 0
+	for _, o := range orphans {
+		deleted, err := s.remove(ctx, o.key, "f.id = $2 AND "+orphaned("$3"),
+			o.id, s.cfg.OrphanGrace.Seconds())
+		if err != nil {
+			return removed, err
+		}
+		if deleted {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// HandleEvent is an events.Handler that cleans up the files of deleted
+// tasks, properties and rooms. Their attachments are removed by the
+// database when the parent row is deleted.
+func (s *Service) HandleEvent(ctx context.Context, evt events.Event) error {
+	switch evt.Type {
+	case events.TaskDeleted, events.PropertyDeleted, events.RoomDeleted:
+		if evt.UserID == 0 {
+			return nil
+		}
+		_, err := s.CleanupOrphans(ctx, evt.UserID)
+		return err
+	}
+	return nil
+}
+
+// Run sweeps for orphaned files every hour until ctx is cancelled, catching
+// uploads that were never attached. Between sweeps it retries the scans of
+// pending files every minute.
+func (s *Service) Run(ctx context.Context) error {
+	var lastCleanup time.Time
+	for {
+		scanned, err := s.ScanPending(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Scan of pending files failed: %v", err)
+		} else if scanned > 0 {
+			log.Printf("Scanned %d pending files", scanned)
+		}
+
+		if time.Since(lastCleanup) >= cleanupInterval {
+			lastCleanup = time.Now()
+			removed, err := s.CleanupOrphans(ctx, 0)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("Orphaned file cleanup failed: %v", err)
+			} else if removed > 0 {
+				log.Printf("Removed %d orphaned files", removed)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(rescanInterval):
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package files
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ErrExtensionMismatch is returned when an upload's filename extension
+// claims a different type than its content
+var ErrExtensionMismatch = errors.New("file extension does not match its content")
+
+// ErrUnsafeContent is returned for uploads that are also valid as another,
+// active format, such as an image that is also an HTML page or a ZIP archive
+var ErrUnsafeContent = errors.New("file contains embedded active content")
+
+// nameExtensions lists the client filename extensions accepted for each
+// sniffed content type
+var nameExtensions = map[string][]string{
+	"image/jpeg":      {".jpg", ".jpeg", ".jpe", ".jfif"},
+	"image/png":       {".png"},
+	"image/gif":       {".gif"},
+	"image/webp":      {".webp"},
+	"application/pdf": {".pdf"},
+}
+
+// checkExtension rejects a client filename whose extension names another
+// type than contentType. Names without an extension are accepted.
+func checkExtension(name, contentType string) error {
+	ext := strings.ToLower(path.Ext(cleanFilename(name, "")))
+	if ext == "" {
+		return nil
+	}
+	for _, allowed := range nameExtensions[contentType] {
+		if ext == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s is not %s", ErrExtensionMismatch, ext, contentType)
+}
+
+// sniffWindow is how far into a file browsers look when guessing its type
+const sniffWindow = 1024
+
+// htmlMarkers are tags that would let a browser treat the start of a file
+// as a page or script
+var htmlMarkers = [][]byte{
+	[]byte("<!doctype"), []byte("<html"), []byte("<head"), []byte("<body"), []byte("<script"),
+	[]byte("<iframe"), []byte("<svg"), []byte("<?php"),
+}
+
+// zipEndRecord starts the end of central directory record of a ZIP archive,
+// which readers look for within the last 64KB of a file, so JAR and other
+// ZIP-based polyglots carry one at the end
+var zipEndRecord = []byte("PK\x05\x06")
+
+// zipSearchWindow covers the end record and the longest archive comment
+const zipSearchWindow = 22 + 0xFFFF
+
+// pdfDelimiters end a PDF name token such as /JavaScript, which may hide
+// its letters as #xx escapes
+const pdfDelimiters = "\x00\t\n\f\r /<>[]()%{}"
+
+// maxPDFName is how much of a name token is kept. Any longer name is longer
+// than every active name even when fully written as #xx escapes.
+const maxPDFName = 64
+
+// pdfActiveNames are the PDF names that run scripts, launch programs or
+// carry embedded files. Names inside compressed object streams are not
+// visible here; those are left to the malware scanner.
+var pdfActiveNames = map[string]bool{
+	"JavaScript": true, "JS": true, "Launch": true, "EmbeddedFile": true, "EmbeddedFiles": true,
+	"RichMedia": true,
+}
+
+// inspect rejects the size bytes of r, of contentType, when they are also
+// a page, script or archive. Only the head and tail are read for markup and
+// archives, and PDFs are scanned in one streaming pass. Images are
+// re-encoded afterwards, but GIFs and PDFs are stored as uploaded, so every
+// type is checked.
+func inspect(r io.ReaderAt, size int64, contentType string) error {
+	head, err := readSection(r, 0, min(size, sniffWindow))
+	if err != nil {
+		return err
+	}
+	head = bytes.ToLower(head)
+	for _, marker := range htmlMarkers {
+		if bytes.Contains(head, marker) {
+			return fmt.Errorf("%w: markup %s", ErrUnsafeContent, marker)
+		}
+	}
+
+	tailStart := max(0, size-zipSearchWindow)
+	tail, err := readSection(r, tailStart, size-tailStart)
+	if err != nil {
+		return err
+	}
+	if bytes.Contains(tail, zipEndRecord) {
+		return fmt.Errorf("%w: ZIP archive", ErrUnsafeContent)
+	}
+
+	if contentType == "application/pdf" {
+		name, err := pdfActiveName(io.NewSectionReader(r, 0, size))
+		if err != nil {
+			return err
+		}
+		if name != "" {
+			return fmt.Errorf("%w: PDF /%s", ErrUnsafeContent, name)
+		}
+	}
+	return nil
+}
+
+// readSection reads n bytes of r starting at off
+func readSection(r io.ReaderAt, off, n int64) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := r.ReadAt(buf, off); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// pdfActiveName returns the first active name in a PDF read from r, or ""
+// when it has none
+func pdfActiveName(r io.Reader) (string, error) {
+	br := bufio.NewReaderSize(r, 64<<10)
+	name := make([]byte, 0, maxPDFName)
+	inName, long := false, false
+	for {
+		c, err := br.ReadByte()
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		if err == nil && inName && strings.IndexByte(pdfDelimiters, c) < 0 {
+			if len(name) < maxPDFName {
+				name = append(name, c)
+			} else {
+				long = true
+			}
+			continue
+		}
+		if inName && !long {
+			if decoded := unescapePDFName(name); pdfActiveNames[decoded] {
+				return decoded, nil
+			}
+		}
+		if err == io.EOF {
+			return "", nil
+		}
+		inName, long, name = c == '/', false, name[:0]
+	}
+}
+
+// unescapePDFName decodes the #xx escapes in a PDF name
+func unescapePDFName(name []byte) string {
+	if !bytes.ContainsRune(name, '#') {
+		return string(name)
+	}
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] == '#' && i+2 < len(name) {
+			if c, err := strconv.ParseUint(string(name[i+1:i+3]), 16, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(name[i])
+	}
+	return b.String()
+}
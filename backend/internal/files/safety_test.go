@@ -0,0 +1,73 @@
+package files
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckExtension(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		want        error
+	}{
+		{"photo.JPG", "image/jpeg", nil},
+		{"photo.jfif", "image/jpeg", nil},
+		{"photo", "image/png", nil},
+		{`C:\Users\me\receipt.pdf`, "application/pdf", nil},
+		{"photo.png", "image/jpeg", ErrExtensionMismatch},
+		{"page.html", "image/gif", ErrExtensionMismatch},
+		{"receipt.pdf.exe", "application/pdf", ErrExtensionMismatch},
+	}
+	for _, tt := range tests {
+		if err := checkExtension(tt.name, tt.contentType); !errors.Is(err, tt.want) {
+			t.Errorf("checkExtension(%q, %s) error = %v, want %v", tt.name, tt.contentType, err, tt.want)
+		}
+	}
+}
+
+func TestInspect(t *testing.T) {
+	// filler pushes content past the sniff window and the PDF reader's buffer
+	filler := strings.Repeat("0 0 0 RG\n", 20000)
+	zip := "PK\x05\x06" + strings.Repeat("\x00", 18)
+
+	tests := []struct {
+		name        string
+		data        string
+		contentType string
+		want        error
+	}{
+		{"plain PDF", "%PDF-1.7\n<< /Type /Catalog /Pages 2 0 R >>\n" + filler + "%%EOF", "application/pdf", nil},
+		{"HTML in head", "GIF89a<script>alert(1)</script>", "image/gif", ErrUnsafeContent},
+		{"uppercase HTML", "GIF89a<HTML>", "image/gif", ErrUnsafeContent},
+		{"markup past the sniff window", "GIF89a" + filler + "<script>", "image/gif", nil},
+		{"ZIP at the end", "GIF89a" + filler + zip, "image/gif", ErrUnsafeContent},
+		{"ZIP before the search window", "GIF89a" + zip + filler + filler, "image/gif", nil},
+		{"JavaScript", "%PDF-1.7\n<< /S /JavaScript /JS (app.alert(1)) >>", "application/pdf", ErrUnsafeContent},
+		{"escaped name", "%PDF-1.7\n<< /S /J#61va#53cript >>", "application/pdf", ErrUnsafeContent},
+		{"name deep in the file", "%PDF-1.7\n" + filler + "<</Launch<<>>>>" + filler, "application/pdf", ErrUnsafeContent},
+		{"name at the end", "%PDF-1.7\n" + filler + "/EmbeddedFiles", "application/pdf", ErrUnsafeContent},
+		{"longer name", "%PDF-1.7\n<< /JavaScriptish true /JSON 1 >>", "application/pdf", nil},
+		{"overlong name", "%PDF-1.7\n/" + strings.Repeat("A", 100) + "JavaScript", "application/pdf", nil},
+		{"PDF names in an image", "\x89PNG\r\n\x1a\n/JavaScript", "image/png", nil},
+	}
+	for _, tt := range tests {
+		data := []byte(tt.data)
+		if err := inspect(bytes.NewReader(data), int64(len(data)), tt.contentType); !errors.Is(err, tt.want) {
+			t.Errorf("inspect(%s) error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestPDFActiveNameAcrossBuffers(t *testing.T) {
+	// Place /JavaScript across every offset of the reader's 64KB buffer edge
+	for offset := 1; offset <= len("/JavaScript"); offset++ {
+		data := strings.Repeat(" ", 64<<10-offset) + "/JavaScript "
+		name, err := pdfActiveName(strings.NewReader(data))
+		if err != nil || name != "JavaScript" {
+			t.Errorf("name split %d bytes before the buffer edge = %q, %v, want JavaScript", offset, name, err)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package files
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+)
+
+// ErrQuarantined is returned for files the malware scanner found infected
+var ErrQuarantined = errors.New("file failed the malware scan and was quarantined")
+
+// ErrNotAvailable is returned when a file is requested before its malware
+// scan has passed
+var ErrNotAvailable = errors.New("file is still being scanned")
+
+// rescanBatch caps how many pending files Run scans per pass
+const rescanBatch = 100
+
+// quarantined wraps ErrQuarantined with the threat found in file
+func quarantined(file *database.File) error {
+	if file.ScanThreat != nil {
+		return fmt.Errorf("%w: %s", ErrQuarantined, *file.ScanThreat)
+	}
+	return ErrQuarantined
+}
+
+// scan runs the scanner over r, a pending file's contents, and records the
+// verdict in the database and in file. On error the file stays pending.
+func (s *Service) scan(ctx context.Context, file *database.File, r io.Reader) error {
+	result, err := s.scanner.Scan(ctx, r)
+	if err != nil {
+		return err
+	}
+	if result.Infected {
+		return s.quarantine(ctx, file, result.Threat)
+	}
+
+	err = s.db.QueryRowContext(ctx, `
+		UPDATE files SET status = $2, scanned_at = NOW()
+		WHERE id = $1 AND status = $3
+		RETURNING status, scanned_at`,
+		file.ID, database.FileStatusAvailable, database.FileStatusPending,
+	).Scan(&file.Status, &file.ScannedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Quarantined meanwhile through another file with the same contents
+		return s.db.QueryRowContext(ctx, `SELECT status, scan_threat, scanned_at FROM files WHERE id = $1`,
+			file.ID).Scan(&file.Status, &file.ScanThreat, &file.ScannedAt)
+	}
+	return err
+}
+
+// quarantine marks every file sharing file's contents as infected with
+// threat, whoever uploaded it, and deletes the contents and variants from
+// storage. The rows remain as a record until the orphan sweep removes them.
+func (s *Service) quarantine(ctx context.Context, file *database.File, threat string) error {
+	threat = truncate(strings.ToValidUTF8(threat, ""), 255)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockKey(ctx, tx, file.FilePath); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE files SET status = $2, scan_threat = $3, scanned_at = NOW()
+		WHERE file_path = $1`,
+		file.FilePath, database.FileStatusQuarantined, threat,
+	); err != nil {
+		return err
+	}
+	for _, v := range file.Variants {
+		if err := s.storage.Delete(ctx, variantKey(file.FilePath, v.Name)); err != nil {
+			return err
+		}
+	}
+	if err := s.storage.Delete(ctx, file.FilePath); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	log.Printf("Quarantined file %s (%s): %s", file.Filename, file.FilePath, threat)
+	file.Status = database.FileStatusQuarantined
+	file.ScanThreat = &threat
+	return nil
+}
+
+// ScanPending scans files left pending because the scanner could not be
+// reached when they were uploaded, and returns how many were scanned. Files
+// uploaded in the last minute are skipped, as Save is still scanning them.
+// Without a scanner, pending files are made available.
+func (s *Service) ScanPending(ctx context.Context) (int, error) {
+	if s.scanner == nil {
+		result, err := s.db.ExecContext(ctx, `UPDATE files SET status = $1 WHERE status = $2`,
+			database.FileStatusAvailable, database.FileStatusPending)
+		if err != nil {
+			return 0, err
+		}
+		n, err := result.RowsAffected()
+		return int(n), err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+fileColumns+` FROM files f
+		WHERE f.status = $1 AND f.created_at < NOW() - INTERVAL '1 minute'
+		ORDER BY f.id
+		LIMIT $2`,
+		database.FileStatusPending, rescanBatch,
+	)
+	if err != nil {
+		return 0, err
+	}
+	var pending []*database.File
+	for rows.Next() {
+		file, err := scanFile(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		pending = append(pending, file)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	scanned := 0
+	for _, file := range pending {
+		r, err := s.storage.Open(ctx, file.FilePath)
+		if err != nil {
+			return scanned, err
+		}
+		err = s.scan(ctx, file, r)
+		r.Close()
+		if err != nil {
+			return scanned, err
+		}
+		scanned++
+	}
+	return scanned, nil
+}
@@ -0,0 +1,535 @@
+package files
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+	"github.com/myideascope/HomeGenie/backend/pkg/imaging"
+	"github.com/myideascope/HomeGenie/backend/pkg/scanner"
+	"github.com/myideascope/HomeGenie/backend/pkg/storage"
+)
+
+// ErrNotFound is returned when a file does not exist or belongs to another user
+var ErrNotFound = errors.New("file not found")
+
+// ErrTooLarge is returned when an upload exceeds the configured maximum size
+var ErrTooLarge = errors.New("file exceeds the maximum upload size")
+
+// ErrEmpty is returned for uploads with no content
+var ErrEmpty = errors.New("file is empty")
+
+// ErrUnsupportedType is returned when an upload's sniffed content type is
+// not accepted for its category
+var ErrUnsupportedType = errors.New("unsupported file type")
+
+// Config limits uploads and sets how long download URLs stay valid
+type Config struct {
+	MaxSize   int64
+	URLExpiry time.Duration
+	// OrphanGrace is how long an upload that was never attached is kept
+	OrphanGrace time.Duration
+}
+
+// ConfigFromEnv builds a config from MAX_FILE_SIZE (bytes, 10MB by default),
+// FILE_URL_EXPIRY (a duration, 15 minutes by default) and FILE_ORPHAN_GRACE
+// (a duration, 24 hours by default)
+func ConfigFromEnv() Config {
+	cfg := Config{MaxSize: 10 << 20, URLExpiry: 15 * time.Minute, OrphanGrace: 24 * time.Hour}
+	if size, err := strconv.ParseInt(os.Getenv("MAX_FILE_SIZE"), 10, 64); err == nil && size > 0 {
+		cfg.MaxSize = size
+	}
+	if expiry, err := time.ParseDuration(os.Getenv("FILE_URL_EXPIRY")); err == nil && expiry > 0 {
+		cfg.URLExpiry = expiry
+	}
+	if grace, err := time.ParseDuration(os.Getenv("FILE_ORPHAN_GRACE")); err == nil && grace > 0 {
+		cfg.OrphanGrace = grace
+	}
+	return cfg
+}
+
+// extensions maps each accepted content type to the extension of stored filenames
+var extensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// accepts reports whether contentType may be uploaded in category. Avatars
+// must be images; other categories also take PDFs such as receipts and
+// manuals.
+func accepts(category database.FileCategory, contentType string) bool {
+	if _, ok := extensions[contentType]; !ok {
+		return false
+	}
+	return category != database.FileCategoryAvatar || strings.HasPrefix(contentType, "image/")
+}
+
+// Service stores uploaded files and records them in the files table.
+// Contents are stored once per distinct SHA-256 hash and shared by every
+// row with that hash.
+type Service struct {
+	db      *sql.DB
+	storage storage.Storage
+	// scanner checks new files before they become available; files are
+	// available immediately when it is nil
+	scanner scanner.Scanner
+	cfg     Config
+}
+
+// NewService creates a file service storing contents in store and scanning
+// them with scan, which may be nil to disable scanning
+func NewService(db *sql.DB, store storage.Storage, scan scanner.Scanner, cfg Config) *Service {
+	return &Service{db: db, storage: store, scanner: scan, cfg: cfg}
+}
+
+// fileColumns selects a file from the files table aliased as f
+const fileColumns = `f.id, f.user_id, f.filename, f.original_filename, f.mime_type, f.size_bytes, f.category,
+	f.file_path, COALESCE(f.content_hash, ''), f.width, f.height, f.variants, f.status, f.scan_threat,
+	f.scanned_at, f.created_at`
+
+// fileFields returns scan destinations for fileColumns
+func fileFields(f *database.File) []interface{} {
+	return []interface{}{&f.ID, &f.UserID, &f.Filename, &f.OriginalFilename, &f.MimeType, &f.SizeBytes, &f.Category,
+		&f.FilePath, &f.ContentHash, &f.Width, &f.Height, &f.Variants, &f.Status, &f.ScanThreat,
+		&f.ScannedAt, &f.CreatedAt}
+}
+
+func scanFile(row interface{ Scan(...interface{}) error }) (*database.File, error) {
+	var f database.File
+	err := row.Scan(fileFields(&f)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Upload is an incoming file spooled to a temporary file, with its size,
+// hash and sniffed content type. It must be closed.
+type Upload struct {
+	file *os.File
+	// Filename is the name the client gave the file
+	Filename    string
+	Size        int64
+	Hash        string
+	ContentType string
+	// image is set for images, whose spooled contents are replaced by the
+	// sanitized original
+	image *imaging.Result
+}
+
+// Close removes the spooled file
+func (u *Upload) Close() error {
+	err := u.file.Close()
+	os.Remove(u.file.Name())
+	return err
+}
+
+// Spool copies r, uploaded as filename, to a temporary file, hashing it on
+// the way. It returns ErrTooLarge as soon as more than the maximum size has
+// been read, and ErrEmpty for empty input. The content type is sniffed from
+// the data; the client's claimed type is never used, and ErrExtensionMismatch
+// or ErrUnsafeContent is returned when filename or the data suggest another
+// one. Images are then rotated upright, stripped of metadata and resized into
+// variants, and the hash and type describe the sanitized image.
+func (s *Service) Spool(r io.Reader, filename string) (*Upload, error) {
+	f, err := os.CreateTemp("", "homegenie-upload-*")
+	if err != nil {
+		return nil, err
+	}
+	upload := &Upload{file: f, Filename: filename}
+
+	hash := sha256.New()
+	upload.Size, err = io.Copy(io.MultiWriter(f, hash), io.LimitReader(r, s.cfg.MaxSize+1))
+	switch {
+	case err != nil:
+	case upload.Size > s.cfg.MaxSize:
+		err = ErrTooLarge
+	case upload.Size == 0:
+		err = ErrEmpty
+	}
+	if err != nil {
+		upload.Close()
+		return nil, err
+	}
+	upload.Hash = hex.EncodeToString(hash.Sum(nil))
+
+	head := make([]byte, 512)
+	n, err := f.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		upload.Close()
+		return nil, err
+	}
+	upload.ContentType, _, _ = strings.Cut(http.DetectContentType(head[:n]), ";")
+
+	// Types Save will reject anyway are left for it to report
+	if _, ok := extensions[upload.ContentType]; ok {
+		if err := upload.check(); err != nil {
+			upload.Close()
+			return nil, err
+		}
+	}
+	return upload, nil
+}
+
+// check applies the content safety checks to the spooled file and
+// sanitizes it if it is an image
+func (u *Upload) check() error {
+	if err := checkExtension(u.Filename, u.ContentType); err != nil {
+		return err
+	}
+	if err := inspect(u.file, u.Size, u.ContentType); err != nil {
+		return err
+	}
+	if imaging.Supported(u.ContentType) {
+		return u.sanitize()
+	}
+	return nil
+}
+
+// sanitize replaces the spooled image with its processed original and keeps
+// the variants for Save
+func (u *Upload) sanitize() error {
+	result, err := imaging.Process(io.NewSectionReader(u.file, 0, u.Size), u.ContentType)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUnsupportedType, err)
+	}
+
+	original := result.Original.Data
+	if err := u.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := u.file.WriteAt(original, 0); err != nil {
+		return err
+	}
+	hash := sha256.Sum256(original)
+	u.Size = int64(len(original))
+	u.Hash = hex.EncodeToString(hash[:])
+	u.ContentType = result.Original.ContentType
+	u.image = result
+	return nil
+}
+
+// dimensions returns the upload's width, height and variants, which are
+// unset for anything but processed images
+func (u *Upload) dimensions() (*int, *int, database.FileVariants) {
+	variants := database.FileVariants{}
+	if u.image == nil {
+		return nil, nil, variants
+	}
+	for _, v := range u.image.Variants {
+		variants = append(variants, database.FileVariant{
+			Name:      v.Name,
+			Width:     v.Width,
+			Height:    v.Height,
+			MimeType:  v.ContentType,
+			SizeBytes: int64(len(v.Data)),
+		})
+	}
+	return &u.image.Original.Width, &u.image.Original.Height, variants
+}
+
+// objectKey is the content-addressed storage key for a SHA-256 hex digest
+func objectKey(hash string) string {
+	return "sha256/" + hash[:2] + "/" + hash
+}
+
+// variantKey is the storage key of the named variant of the image under key
+func variantKey(key, name string) string {
+	return key + "." + name
+}
+
+// lockKey serializes uploads and deletes of one stored object until the
+// transaction ends, so a blob is never deleted while a new row claims it
+func lockKey(ctx context.Context, tx *sql.Tx, key string) error {
+	_, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, key)
+	return err
+}
+
+// Save records a spooled upload in category for userID, storing its
+// contents unless an identical file is already stored. Uploading the same
+// contents to the same category again returns the existing file.
+//
+// With a scanner, the file is recorded as pending and scanned once stored.
+// Infected files are quarantined and reported as ErrQuarantined. When the
+// scanner cannot be reached the file is returned still pending, and Run
+// scans it later.
+func (s *Service) Save(ctx context.Context, userID int, upload *Upload,
+	category database.FileCategory) (*database.File, error) {
+	if !accepts(category, upload.ContentType) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, upload.ContentType)
+	}
+	key := objectKey(upload.Hash)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockKey(ctx, tx, key); err != nil {
+		return nil, err
+	}
+
+	existing, err := scanFile(tx.QueryRowContext(ctx, `
+		SELECT `+fileColumns+` FROM files f
+		WHERE f.user_id = $1 AND f.content_hash = $2 AND f.category = $3
+		ORDER BY f.id LIMIT 1`,
+		userID, upload.Hash, category,
+	))
+	if err == nil {
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		if existing.Status == database.FileStatusQuarantined {
+			return nil, quarantined(existing)
+		}
+		return s.withURL(ctx, existing)
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	stored, err := s.storage.Exists(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if !stored {
+		if _, err := upload.file.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if err := s.storage.Put(ctx, key, upload.file, upload.Size, upload.ContentType); err != nil {
+			return nil, err
+		}
+		if upload.image != nil {
+			for _, v := range upload.image.Variants {
+				err := s.storage.Put(ctx, variantKey(key, v.Name), bytes.NewReader(v.Data), int64(len(v.Data)),
+					v.ContentType)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	width, height, variants := upload.dimensions()
+	status := database.FileStatusAvailable
+	if s.scanner != nil {
+		status = database.FileStatusPending
+	}
+
+	filename, err := generateFilename(extensions[upload.ContentType])
+	if err != nil {
+		return nil, err
+	}
+	file, err := scanFile(tx.QueryRowContext(ctx, `
+		INSERT INTO files AS f (user_id, filename, original_filename, mime_type, size_bytes, category, file_path,
+			content_hash, width, height, variants, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING `+fileColumns,
+		userID, filename, cleanFilename(upload.Filename, filename), upload.ContentType, upload.Size, category, key,
+		upload.Hash, width, height, variants, status,
+	))
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if status == database.FileStatusPending {
+		if _, err := upload.file.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if err := s.scan(ctx, file, upload.file); err != nil {
+			log.Printf("Scan of file %s failed, leaving it pending: %v", file.Filename, err)
+		}
+		if file.Status == database.FileStatusQuarantined {
+			return nil, quarantined(file)
+		}
+	}
+	return s.withURL(ctx, file)
+}
+
+// Get returns one of userID's files with a fresh download URL
+func (s *Service) Get(ctx context.Context, userID int, filename string) (*database.File, error) {
+	file, err := scanFile(s.db.QueryRowContext(ctx,
+		`SELECT `+fileColumns+` FROM files f WHERE f.user_id = $1 AND f.filename = $2`, userID, filename))
+	if err != nil {
+		return nil, err
+	}
+	return s.withURL(ctx, file)
+}
+
+// Delete removes one of userID's files, detaching it everywhere, and its
+// stored contents once no other file shares them
+func (s *Service) Delete(ctx context.Context, userID int, filename string) error {
+	var key string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT file_path FROM files WHERE user_id = $1 AND filename = $2`, userID, filename).Scan(&key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.remove(ctx, key, "f.user_id = $2 AND f.filename = $3", userID, filename)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// remove deletes the file stored under key that matches cond, whose
+// arguments start at $2, and deletes the contents once no other file
+// shares them. It reports whether a file was deleted.
+func (s *Service) remove(ctx context.Context, key, cond string, args ...interface{}) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if err := lockKey(ctx, tx, key); err != nil {
+		return false, err
+	}
+
+	// The outer SELECT still sees the deleted row, so it is excluded by id
+	var deleted, remaining int
+	var variants database.FileVariants
+	err = tx.QueryRowContext(ctx, `
+		WITH deleted AS (
+			DELETE FROM files f WHERE f.file_path = $1 AND `+cond+` RETURNING f.id, f.variants
+		)
+		SELECT (SELECT COUNT(*) FROM deleted),
+			(SELECT COUNT(*) FROM files WHERE file_path = $1 AND id NOT IN (SELECT id FROM deleted)),
+			(SELECT variants FROM deleted LIMIT 1)`,
+		append([]interface{}{key}, args...)...,
+	).Scan(&deleted, &remaining, &variants)
+	if err != nil {
+		return false, err
+	}
+	if deleted == 0 {
+		return false, nil
+	}
+	if remaining == 0 {
+		for _, v := range variants {
+			if err := s.storage.Delete(ctx, variantKey(key, v.Name)); err != nil {
+				return false, err
+			}
+		}
+		if err := s.storage.Delete(ctx, key); err != nil {
+			return false, err
+		}
+	}
+	return true, tx.Commit()
+}
+
+// withURL fills in signed download URLs for a file and its variants that
+// keep the original filename. Files that are not available get none.
+func (s *Service) withURL(ctx context.Context, file *database.File) (*database.File, error) {
+	if file.Status != database.FileStatusAvailable {
+		return file, nil
+	}
+	expires := time.Now().Add(s.cfg.URLExpiry).UTC()
+	url, err := s.storage.SignedURL(ctx, file.FilePath, s.cfg.URLExpiry, storage.URLOptions{
+		Filename:    file.OriginalFilename,
+		ContentType: file.MimeType,
+	})
+	if err != nil {
+		return nil, err
+	}
+	file.URL = url
+	file.URLExpiresAt = &expires
+
+	for i, v := range file.Variants {
+		file.Variants[i].URL, err = s.storage.SignedURL(ctx, variantKey(file.FilePath, v.Name), s.cfg.URLExpiry,
+			storage.URLOptions{Filename: variantFilename(file.OriginalFilename, v), ContentType: v.MimeType})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return file, nil
+}
+
+// DownloadURL returns a signed URL for one of userID's files at size, which
+// is "original" or a variant name. Images smaller than the requested size,
+// and files that are not images, are served at their original size. Files
+// still being scanned return ErrNotAvailable and quarantined ones
+// ErrQuarantined.
+func (s *Service) DownloadURL(ctx context.Context, userID int, filename, size string) (string, error) {
+	file, err := s.Get(ctx, userID, filename)
+	if err != nil {
+		return "", err
+	}
+	switch file.Status {
+	case database.FileStatusPending:
+		return "", ErrNotAvailable
+	case database.FileStatusQuarantined:
+		return "", quarantined(file)
+	}
+	for _, v := range file.Variants {
+		if v.Name == size {
+			return v.URL, nil
+		}
+	}
+	return file.URL, nil
+}
+
+// variantFilename names a variant after the original, e.g. photo-thumbnail.jpg
+func variantFilename(original string, v database.FileVariant) string {
+	base := strings.TrimSuffix(original, path.Ext(original))
+	return base + "-" + v.Name + extensions[v.MimeType]
+}
+
+// generateFilename returns a random public filename with ext
+func generateFilename(ext string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b) + ext, nil
+}
+
+// cleanFilename reduces a client-supplied name to a base name that fits the
+// column, falling back to fallback when nothing usable is left
+func cleanFilename(name, fallback string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimSpace(strings.ToValidUTF8(name, ""))
+	if name == "." || name == "/" || name == "" {
+		return fallback
+	}
+	return truncate(name, 255)
+}
+
+// truncate shortens s to at most n bytes without splitting a rune
+func truncate(s string, n int) string {
+	for len(s) > n {
+		_, size := utf8.DecodeLastRuneInString(s)
+		s = s[:len(s)-size]
+	}
+	return s
+}
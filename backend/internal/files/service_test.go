@@ -0,0 +1,52 @@
+package files
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"Eicar-Test-Signature", 255, "Eicar-Test-Signature"},
+		{"abcdef", 3, "abc"},
+		{"héllo", 2, "h"},
+		{"héllo", 3, "hé"},
+		{"日本語", 8, "日本"},
+		{"日本語", 2, ""},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.s, tt.n); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+
+	// A threat name whose 255th byte falls inside a rune
+	threat := strings.Repeat("a", 254) + "é-Trojan"
+	got := truncate(threat, 255)
+	if len(got) != 254 || !utf8.ValidString(got) {
+		t.Errorf("truncate() of a split rune = %d bytes, valid %v, want 254 valid bytes", len(got), utf8.ValidString(got))
+	}
+}
+
+func TestCleanFilename(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"report.pdf", "report.pdf"},
+		{"../../etc/passwd", "passwd"},
+		{`C:\Users\ana\plan.png`, "plan.png"},
+		{"bad\xffname.txt", "badname.txt"},
+		{strings.Repeat("ü", 200) + ".pdf", strings.Repeat("ü", 127)},
+	}
+	for _, tt := range tests {
+		if got := cleanFilename(tt.name, "fallback"); got != tt.want {
+			t.Errorf("cleanFilename(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+)
+
+// Delivery channel names, matching the per-channel switches in NotificationSettings
+const (
+	ChannelEmail = "email"
+	ChannelPush  = "push"
+	ChannelSMS   = "sms"
+)
+
+// Channel delivers notifications outside the app. In-app delivery is the
+// notifications table itself and needs no channel.
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, user *database.User, n *database.Notification) error
+}
+
+// EmailChannel sends notifications by SMTP
+type EmailChannel struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewEmailChannel creates an email channel sending through the SMTP server at
+// host:port as from. Username and password may be empty for servers that do
+// not require authentication.
+func NewEmailChannel(host string, port int, username, password, from string) *EmailChannel {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &EmailChannel{addr: net.JoinHostPort(host, fmt.Sprint(port)), auth: auth, from: from}
+}
+
+// Name implements Channel
+func (e *EmailChannel) Name() string {
+	return ChannelEmail
+}
+
+// Send implements Channel. net/smtp has no context support, so ctx is only
+// checked before connecting.
+func (e *EmailChannel) Send(ctx context.Context, user *database.User, n *database.Notification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: HomeGenie <%s>\r\n", e.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", user.Email)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", headerValue(n.Title))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&msg, "Hi %s,\r\n\r\n%s\r\n", user.FirstName, n.Message)
+
+	if err := smtp.SendMail(e.addr, e.auth, e.from, []string{user.Email}, []byte(msg.String())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// headerValue strips line breaks so a title cannot inject mail headers
+func headerValue(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
@@ -0,0 +1,351 @@
+package notifications
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+	"github.com/myideascope/HomeGenie/backend/pkg/events"
+	"github.com/myideascope/HomeGenie/backend/pkg/metrics"
+)
+
+// ErrNotFound is returned when a notification does not exist or belongs to another user
+var ErrNotFound = errors.New("notification not found")
+
+// Delivery statuses reported by Test
+const (
+	DeliverySent        = "sent"
+	DeliveryFailed      = "failed"
+	DeliveryUnavailable = "unavailable"
+)
+
+// Service manages a user's notifications and notification settings
+type Service struct {
+	db       *sql.DB
+	channels map[string]Channel
+}
+
+// NewService creates a notification service delivering through channels.
+// Channels enabled in a user's settings but not given here are reported as
+// unavailable.
+func NewService(db *sql.DB, channels ...Channel) *Service {
+	s := &Service{db: db, channels: make(map[string]Channel)}
+	for _, c := range channels {
+		s.channels[c.Name()] = c
+	}
+	return s
+}
+
+const notificationColumns = `id, user_id, title, message, type, priority, read, task_id, property_id, action_url,
+	scheduled_for, created_at, updated_at`
+
+// visible matches notifications that are due to be shown
+const visible = `(scheduled_for IS NULL OR scheduled_for <= NOW())`
+
+func scanNotification(row interface{ Scan(...interface{}) error }) (*database.Notification, error) {
+	var n database.Notification
+	err := row.Scan(&n.ID, &n.UserID, &n.Title, &n.Message, &n.Type, &n.Priority, &n.Read, &n.TaskID, &n.PropertyID,
+		&n.ActionURL, &n.ScheduledFor, &n.CreatedAt, &n.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// List returns a page of userID's notifications matching filters, newest
+// first, with the user's unread count across all notifications
+func (s *Service) List(ctx context.Context, userID int, filters database.NotificationFilters) ([]database.Notification, int, int, error) {
+	where := "WHERE user_id = $1 AND " + visible
+	args := []interface{}{userID}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filters.Read != nil {
+		where += " AND read = " + arg(*filters.Read)
+	}
+	if filters.Type != nil {
+		where += " AND type = " + arg(*filters.Type)
+	}
+	if filters.Priority != nil {
+		where += " AND priority = " + arg(*filters.Priority)
+	}
+
+	var total, unread int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM notifications `+where+`),
+			(SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND `+visible+` AND NOT read)
+	`, args...).Scan(&total, &unread)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM notifications
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT %s OFFSET %s
+	`, notificationColumns, where, arg(filters.Limit), arg((filters.Page-1)*filters.Limit))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	defer rows.Close()
+
+	notifications := []database.Notification{}
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		notifications = append(notifications, *n)
+	}
+	return notifications, total, unread, rows.Err()
+}
+
+// MarkRead marks a notification owned by userID as read
+func (s *Service) MarkRead(ctx context.Context, userID, id int) (*database.Notification, error) {
+	row := s.db.QueryRowContext(ctx, `
+		UPDATE notifications SET read = true, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+		RETURNING `+notificationColumns,
+		id, userID,
+	)
+	n, err := scanNotification(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return n, err
+}
+
+// MarkAllRead marks all of userID's visible notifications as read and
+// returns how many changed
+func (s *Service) MarkAllRead(ctx context.Context, userID int) (int, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE notifications SET read = true, updated_at = NOW()
+		WHERE user_id = $1 AND NOT read AND `+visible,
+		userID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+// Delete removes a notification owned by userID
+func (s *Service) Delete(ctx context.Context, userID, id int) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM notifications WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Clear removes all of userID's visible notifications. Scheduled ones that
+// are not yet due are kept.
+func (s *Service) Clear(ctx context.Context, userID int) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM notifications WHERE user_id = $1 AND "+visible, userID)
+	return err
+}
+
+const settingsColumns = `id, user_id, email_notifications, push_notifications, sms_notifications, reminder_advance,
+	quiet_hours_enabled, to_char(quiet_hours_start, 'HH24:MI'), to_char(quiet_hours_end, 'HH24:MI'),
+	task_reminders, maintenance_alerts, system_notifications, created_at, updated_at`
+
+func scanSettings(row interface{ Scan(...interface{}) error }) (*database.NotificationSettings, error) {
+	var st database.NotificationSettings
+	err := row.Scan(&st.ID, &st.UserID, &st.EmailNotifications, &st.PushNotifications, &st.SMSNotifications,
+		&st.ReminderAdvance, &st.QuietHours.Enabled, &st.QuietHours.Start, &st.QuietHours.End,
+		&st.TaskReminders, &st.MaintenanceAlerts, &st.SystemNotifications, &st.CreatedAt, &st.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// Settings returns userID's notification settings, creating them with the
+// defaults on first access
+func (s *Service) Settings(ctx context.Context, userID int) (*database.NotificationSettings, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+settingsColumns+` FROM notification_settings WHERE user_id = $1`, userID)
+	st, err := scanSettings(row)
+	if err != sql.ErrNoRows {
+		return st, err
+	}
+
+	// Another request may create them concurrently; either insert wins
+	_, err = s.db.ExecContext(ctx,
+		"INSERT INTO notification_settings (user_id) VALUES ($1) ON CONFLICT (user_id) DO NOTHING", userID)
+	if err != nil {
+		return nil, err
+	}
+	row = s.db.QueryRowContext(ctx, `SELECT `+settingsColumns+` FROM notification_settings WHERE user_id = $1`, userID)
+	return scanSettings(row)
+}
+
+// UpdateSettings applies a partial update to userID's notification settings
+func (s *Service) UpdateSettings(ctx context.Context, userID int, req database.UpdateNotificationSettingsRequest) (*database.NotificationSettings, error) {
+	st, err := s.Settings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.EmailNotifications != nil {
+		st.EmailNotifications = *req.EmailNotifications
+	}
+	if req.PushNotifications != nil {
+		st.PushNotifications = *req.PushNotifications
+	}
+	if req.SMSNotifications != nil {
+		st.SMSNotifications = *req.SMSNotifications
+	}
+	if req.ReminderAdvance != nil {
+		st.ReminderAdvance = *req.ReminderAdvance
+	}
+	if q := req.QuietHours; q != nil {
+		if q.Enabled != nil {
+			st.QuietHours.Enabled = *q.Enabled
+		}
+		if q.Start != nil {
+			st.QuietHours.Start = *q.Start
+		}
+		if q.End != nil {
+			st.QuietHours.End = *q.End
+		}
+	}
+	if req.TaskReminders != nil {
+		st.TaskReminders = *req.TaskReminders
+	}
+	if req.MaintenanceAlerts != nil {
+		st.MaintenanceAlerts = *req.MaintenanceAlerts
+	}
+	if req.SystemNotifications != nil {
+		st.SystemNotifications = *req.SystemNotifications
+	}
+
+	row := s.db.QueryRowContext(ctx, `
+		UPDATE notification_settings
+		SET email_notifications = $1, push_notifications = $2, sms_notifications = $3, reminder_advance = $4,
+			quiet_hours_enabled = $5, quiet_hours_start = $6, quiet_hours_end = $7,
+			task_reminders = $8, maintenance_alerts = $9, system_notifications = $10, updated_at = NOW()
+		WHERE user_id = $11
+		RETURNING `+settingsColumns,
+		st.EmailNotifications, st.PushNotifications, st.SMSNotifications, st.ReminderAdvance,
+		st.QuietHours.Enabled, st.QuietHours.Start, st.QuietHours.End,
+		st.TaskReminders, st.MaintenanceAlerts, st.SystemNotifications, userID,
+	)
+	return scanSettings(row)
+}
+
+// enabledChannels lists the channels switched on in st
+func enabledChannels(st *database.NotificationSettings) []string {
+	var names []string
+	if st.EmailNotifications {
+		names = append(names, ChannelEmail)
+	}
+	if st.PushNotifications {
+		names = append(names, ChannelPush)
+	}
+	if st.SMSNotifications {
+		names = append(names, ChannelSMS)
+	}
+	return names
+}
+
+// Test creates an in-app test notification for userID and sends it through
+// every channel enabled in their settings, ignoring quiet hours, so the user
+// can check their setup. Per-channel failures are reported, not returned.
+func (s *Service) Test(ctx context.Context, userID int, notificationType database.NotificationType) (*database.TestNotificationResponse, error) {
+	if notificationType == "" {
+		notificationType = database.NotificationTypeSystem
+	}
+
+	st, err := s.Settings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.user(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := s.create(ctx, userID, "Test notification",
+		"This is a test notification from HomeGenie. If you can read this, notifications are working.",
+		notificationType, database.NotificationPriorityLow)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &database.TestNotificationResponse{Notification: n, Deliveries: []database.NotificationDelivery{}}
+	for _, name := range enabledChannels(st) {
+		delivery := database.NotificationDelivery{Channel: name, Status: DeliverySent}
+		channel, ok := s.channels[name]
+		if !ok {
+			msg := "channel is not configured"
+			delivery.Status, delivery.Error = DeliveryUnavailable, &msg
+			resp.Deliveries = append(resp.Deliveries, delivery)
+			continue
+		}
+
+		err := channel.Send(ctx, user, n)
+		metrics.RecordNotificationDelivery(name, err)
+		if err != nil {
+			log.Printf("Test notification %d via %s failed: %v", n.ID, name, err)
+			msg := err.Error()
+			delivery.Status, delivery.Error = DeliveryFailed, &msg
+		}
+		resp.Deliveries = append(resp.Deliveries, delivery)
+	}
+	return resp, nil
+}
+
+// create inserts a notification and records its event
+func (s *Service) create(ctx context.Context, userID int, title, message string, notificationType database.NotificationType, priority database.NotificationPriority) (*database.Notification, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRowContext(ctx, `
+		INSERT INTO notifications (user_id, title, message, type, priority)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+notificationColumns,
+		userID, title, message, notificationType, priority,
+	)
+	n, err := scanNotification(row)
+	if err != nil {
+		return nil, err
+	}
+
+	evt, err := events.New(events.NotificationCreated, events.AggregateNotification, n.ID, userID, n)
+	if err != nil {
+		return nil, err
+	}
+	if err := events.Append(ctx, tx, evt); err != nil {
+		return nil, err
+	}
+	return n, tx.Commit()
+}
+
+// user loads the recipient details channels need
+func (s *Service) user(ctx context.Context, userID int) (*database.User, error) {
+	var u database.User
+	err := s.db.QueryRowContext(ctx,
+		"SELECT id, email, first_name, last_name, phone, timezone FROM users WHERE id = $1", userID,
+	).Scan(&u.ID, &u.Email, &u.FirstName, &u.LastName, &u.Phone, &u.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
@@ -0,0 +1,696 @@
+package properties
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/lib/pq"
+	"github.com/myideascope/HomeGenie/backend/pkg/cache"
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+	"github.com/myideascope/HomeGenie/backend/pkg/events"
+)
+
+// ErrNotFound is returned when a property does not exist or belongs to another user
+var ErrNotFound = errors.New("property not found")
+
+// ErrRoomNotFound is returned when a room does not exist in the property
+var ErrRoomNotFound = errors.New("room not found")
+
+// ErrTaskNotFound is returned when a maintenance record refers to a task of another property
+var ErrTaskNotFound = errors.New("task not found")
+
+// DependentsError is returned when deleting a property that still has rooms,
+// tasks or maintenance history without confirming the cascade
+type DependentsError struct {
+	Dependents database.PropertyDependents
+}
+
+func (e *DependentsError) Error() string {
+	d := e.Dependents
+	return fmt.Sprintf("property has %d rooms, %d tasks and %d maintenance records; confirm to delete them",
+		d.Rooms, d.Tasks, d.MaintenanceRecords)
+}
+
+// Service manages properties, their rooms and maintenance history
+type Service struct {
+	db    *sql.DB
+	cache *cache.Loader
+}
+
+// NewService creates a property service. Property details are read through
+// loader and invalidated by the cache's event handler; this service also
+// drops them directly after its own writes so clients read their changes.
+func NewService(db *sql.DB, loader *cache.Loader) *Service {
+	return &Service{db: db, cache: loader}
+}
+
+const propertyColumns = `id, user_id, name, address, type, year_built, square_footage, notes, created_at, updated_at`
+
+const roomColumns = `id, property_id, name, type, floor_area, description, created_at, updated_at`
+
+const maintenanceColumns = `id, property_id, task_id, title, description, completed_date, cost, currency, contractor,
+	notes, created_at, updated_at`
+
+const costItemColumns = `id, maintenance_record_id, category, description, amount`
+
+func scanProperty(row interface{ Scan(...interface{}) error }) (*database.Property, error) {
+	var p database.Property
+	err := row.Scan(&p.ID, &p.UserID, &p.Name, &p.Address, &p.Type, &p.YearBuilt, &p.SquareFootage, &p.Notes,
+		&p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	p.Rooms = []database.Room{}
+	p.MaintenanceHistory = []database.MaintenanceRecord{}
+	return &p, nil
+}
+
+func scanRoom(row interface{ Scan(...interface{}) error }) (*database.Room, error) {
+	var r database.Room
+	err := row.Scan(&r.ID, &r.PropertyID, &r.Name, &r.Type, &r.FloorArea, &r.Description, &r.CreatedAt, &r.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func scanMaintenance(row interface{ Scan(...interface{}) error }) (*database.MaintenanceRecord, error) {
+	var m database.MaintenanceRecord
+	err := row.Scan(&m.ID, &m.PropertyID, &m.TaskID, &m.Title, &m.Description, &m.CompletedDate, &m.Cost,
+		&m.Currency, &m.Contractor, &m.Notes, &m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	m.LineItems = []database.CostLineItem{}
+	return &m, nil
+}
+
+func scanCostItem(row interface{ Scan(...interface{}) error }) (*database.CostLineItem, error) {
+	var i database.CostLineItem
+	if err := row.Scan(&i.ID, &i.MaintenanceRecordID, &i.Category, &i.Description, &i.Amount); err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// roomPayload is the event payload for a room. database.Room hides its
+// property ID from JSON, so it is added back for consumers.
+type roomPayload struct {
+	database.Room
+	PropertyID int `json:"propertyId"`
+}
+
+// maintenancePayload is the event payload for a maintenance record
+type maintenancePayload struct {
+	database.MaintenanceRecord
+	PropertyID int `json:"propertyId"`
+}
+
+// deletedPayload is the event payload for a deleted property
+type deletedPayload struct {
+	database.Property
+	Dependents database.PropertyDependents `json:"dependents"`
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Create adds a property for userID
+func (s *Service) Create(ctx context.Context, userID int, req database.CreatePropertyRequest) (*database.Property, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRowContext(ctx, `
+		INSERT INTO properties (user_id, name, address, type, year_built, square_footage, notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+propertyColumns,
+		userID, req.Name, req.Address, req.Type, req.YearBuilt, req.SquareFootage, req.Notes,
+	)
+	p, err := scanProperty(row)
+	if err != nil {
+		return nil, err
+	}
+	if err := appendEvent(ctx, tx, events.PropertyCreated, events.AggregateProperty, p.ID, userID, p); err != nil {
+		return nil, err
+	}
+	return p, tx.Commit()
+}
+
+// List returns a page of userID's properties matching filters, by name. Each
+// property includes its rooms; maintenance history is only loaded by Get.
+func (s *Service) List(ctx context.Context, userID int, filters database.PropertyFilters) ([]database.Property, int, error) {
+	where := "WHERE user_id = $1"
+	args := []interface{}{userID}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filters.Type != nil {
+		where += " AND type = " + arg(*filters.Type)
+	}
+	if filters.Search != nil && *filters.Search != "" {
+		pattern := arg(database.ContainsPattern(*filters.Search))
+		where += " AND (name ILIKE " + pattern + " OR address ILIKE " + pattern + ")"
+	}
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM properties "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM properties
+		%s
+		ORDER BY name, id
+		LIMIT %s OFFSET %s
+	`, propertyColumns, where, arg(filters.Limit), arg((filters.Page-1)*filters.Limit))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	properties := []database.Property{}
+	index := make(map[int]int)
+	for rows.Next() {
+		p, err := scanProperty(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		index[p.ID] = len(properties)
+		properties = append(properties, *p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	if len(properties) == 0 {
+		return properties, total, nil
+	}
+
+	ids := make([]int64, 0, len(properties))
+	for _, p := range properties {
+		ids = append(ids, int64(p.ID))
+	}
+	rooms, err := s.rooms(ctx, "property_id = ANY($1)", pq.Array(ids))
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, r := range rooms {
+		p := &properties[index[r.PropertyID]]
+		p.Rooms = append(p.Rooms, r)
+	}
+	return properties, total, nil
+}
+
+// cachedProperty is a property detail as cached. The owner is kept alongside
+// because Property hides it from JSON.
+type cachedProperty struct {
+	OwnerID  int                `json:"ownerId"`
+	Property *database.Property `json:"property"`
+}
+
+// Get returns a property owned by userID with its rooms and maintenance
+// history, read through the cache
+func (s *Service) Get(ctx context.Context, userID, id int) (*database.Property, error) {
+	cached, err := cache.Load(ctx, s.cache, cache.PropertyDetailKey(id), cache.PropertyDetailTTL,
+		func(ctx context.Context) (cachedProperty, error) {
+			p, err := s.detail(ctx, id)
+			if err != nil {
+				return cachedProperty{}, err
+			}
+			return cachedProperty{OwnerID: p.UserID, Property: p}, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	if cached.OwnerID != userID {
+		return nil, ErrNotFound
+	}
+	cached.Property.UserID = cached.OwnerID
+	return cached.Property, nil
+}
+
+// detail loads a property with its rooms and maintenance history
+func (s *Service) detail(ctx context.Context, id int) (*database.Property, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+propertyColumns+` FROM properties WHERE id = $1`, id)
+	p, err := scanProperty(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Rooms, err = s.rooms(ctx, "property_id = $1", id); err != nil {
+		return nil, err
+	}
+	if p.MaintenanceHistory, err = s.maintenance(ctx, id); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// getOwned returns the property row, without rooms or history, if userID owns it
+func getOwned(ctx context.Context, q querier, userID, id int) (*database.Property, error) {
+	row := q.QueryRowContext(ctx, `SELECT `+propertyColumns+` FROM properties WHERE id = $1 AND user_id = $2`, id, userID)
+	p, err := scanProperty(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return p, err
+}
+
+// Check returns ErrNotFound unless userID owns property id
+func (s *Service) Check(ctx context.Context, userID, id int) error {
+	_, err := getOwned(ctx, s.db, userID, id)
+	return err
+}
+
+// Update applies a partial update to a property owned by userID
+func (s *Service) Update(ctx context.Context, userID, id int, req database.UpdatePropertyRequest) (*database.Property, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	p, err := getOwned(ctx, tx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		p.Name = *req.Name
+	}
+	if req.Address != nil {
+		p.Address = *req.Address
+	}
+	if req.Type != nil {
+		p.Type = *req.Type
+	}
+	if req.YearBuilt != nil {
+		p.YearBuilt = req.YearBuilt
+	}
+	if req.SquareFootage != nil {
+		p.SquareFootage = req.SquareFootage
+	}
+	if req.Notes != nil {
+		p.Notes = req.Notes
+	}
+
+	row := tx.QueryRowContext(ctx, `
+		UPDATE properties
+		SET name = $1, address = $2, type = $3, year_built = $4, square_footage = $5, notes = $6, updated_at = NOW()
+		WHERE id = $7 AND user_id = $8
+		RETURNING `+propertyColumns,
+		p.Name, p.Address, p.Type, p.YearBuilt, p.SquareFootage, p.Notes, id, userID,
+	)
+	if p, err = scanProperty(row); err != nil {
+		return nil, err
+	}
+	if err := appendEvent(ctx, tx, events.PropertyUpdated, events.AggregateProperty, id, userID, p); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx, id)
+	return s.Get(ctx, userID, id)
+}
+
+// Dependents counts the records that deleting a property owned by userID
+// would also delete
+func (s *Service) Dependents(ctx context.Context, userID, id int) (*database.PropertyDependents, error) {
+	if err := s.Check(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return dependents(ctx, s.db, id)
+}
+
+func dependents(ctx context.Context, q querier, id int) (*database.PropertyDependents, error) {
+	var d database.PropertyDependents
+	err := q.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM rooms WHERE property_id = $1),
+			(SELECT COUNT(*) FROM tasks WHERE property_id = $1),
+			(SELECT COUNT(*) FROM maintenance_records WHERE property_id = $1)
+	`, id).Scan(&d.Rooms, &d.Tasks, &d.MaintenanceRecords)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// Delete removes a property owned by userID. Its rooms, tasks and
+// maintenance history are deleted with it, so unless confirm is set a
+// property that has any is left in place and a *DependentsError returned.
+func (s *Service) Delete(ctx context.Context, userID, id int, confirm bool) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock the row so the counts cannot change between checking and deleting
+	row := tx.QueryRowContext(ctx,
+		`SELECT `+propertyColumns+` FROM properties WHERE id = $1 AND user_id = $2 FOR UPDATE`, id, userID)
+	p, err := scanProperty(row)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	d, err := dependents(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if !confirm && (d.Rooms > 0 || d.Tasks > 0 || d.MaintenanceRecords > 0) {
+		return &DependentsError{Dependents: *d}
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM properties WHERE id = $1 AND user_id = $2", id, userID); err != nil {
+		return err
+	}
+	payload := deletedPayload{Property: *p, Dependents: *d}
+	if err := appendEvent(ctx, tx, events.PropertyDeleted, events.AggregateProperty, id, userID, payload); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.invalidate(ctx, id)
+	return nil
+}
+
+// Rooms returns the rooms of a property owned by userID, by name
+func (s *Service) Rooms(ctx context.Context, userID, propertyID int) ([]database.Room, error) {
+	if err := s.Check(ctx, userID, propertyID); err != nil {
+		return nil, err
+	}
+	return s.rooms(ctx, "property_id = $1", propertyID)
+}
+
+func (s *Service) rooms(ctx context.Context, where string, args ...interface{}) ([]database.Room, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+roomColumns+` FROM rooms WHERE `+where+` ORDER BY name, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rooms := []database.Room{}
+	for rows.Next() {
+		r, err := scanRoom(rows)
+		if err != nil {
+			return nil, err
+		}
+		rooms = append(rooms, *r)
+	}
+	return rooms, rows.Err()
+}
+
+// CreateRoom adds a room to a property owned by userID
+func (s *Service) CreateRoom(ctx context.Context, userID, propertyID int, req database.CreateRoomRequest) (*database.Room, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := getOwned(ctx, tx, userID, propertyID); err != nil {
+		return nil, err
+	}
+
+	row := tx.QueryRowContext(ctx, `
+		INSERT INTO rooms (property_id, name, type, floor_area, description)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+roomColumns,
+		propertyID, req.Name, req.Type, req.FloorArea, req.Description,
+	)
+	r, err := scanRoom(row)
+	if err != nil {
+		return nil, err
+	}
+	return r, s.commitRoom(ctx, tx, events.RoomCreated, userID, r)
+}
+
+// UpdateRoom applies a partial update to a room of a property owned by userID
+func (s *Service) UpdateRoom(ctx context.Context, userID, propertyID, roomID int, req database.UpdateRoomRequest) (*database.Room, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	r, err := getRoom(ctx, tx, userID, propertyID, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		r.Name = *req.Name
+	}
+	if req.Type != nil {
+		r.Type = *req.Type
+	}
+	if req.FloorArea != nil {
+		r.FloorArea = req.FloorArea
+	}
+	if req.Description != nil {
+		r.Description = req.Description
+	}
+
+	row := tx.QueryRowContext(ctx, `
+		UPDATE rooms
+		SET name = $1, type = $2, floor_area = $3, description = $4, updated_at = NOW()
+		WHERE id = $5
+		RETURNING `+roomColumns,
+		r.Name, r.Type, r.FloorArea, r.Description, roomID,
+	)
+	if r, err = scanRoom(row); err != nil {
+		return nil, err
+	}
+	return r, s.commitRoom(ctx, tx, events.RoomUpdated, userID, r)
+}
+
+// DeleteRoom removes a room from a property owned by userID
+func (s *Service) DeleteRoom(ctx context.Context, userID, propertyID, roomID int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	r, err := getRoom(ctx, tx, userID, propertyID, roomID)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM rooms WHERE id = $1", roomID); err != nil {
+		return err
+	}
+	return s.commitRoom(ctx, tx, events.RoomDeleted, userID, r)
+}
+
+func getRoom(ctx context.Context, q querier, userID, propertyID, roomID int) (*database.Room, error) {
+	row := q.QueryRowContext(ctx, `
+		SELECT r.id, r.property_id, r.name, r.type, r.floor_area, r.description, r.created_at, r.updated_at
+		FROM rooms r JOIN properties p ON p.id = r.property_id
+		WHERE r.id = $1 AND r.property_id = $2 AND p.user_id = $3
+	`, roomID, propertyID, userID)
+	r, err := scanRoom(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrRoomNotFound
+	}
+	return r, err
+}
+
+// commitRoom records a room event and commits tx
+func (s *Service) commitRoom(ctx context.Context, tx *sql.Tx, eventType events.Type, userID int, r *database.Room) error {
+	payload := roomPayload{Room: *r, PropertyID: r.PropertyID}
+	if err := appendEvent(ctx, tx, eventType, events.AggregateRoom, r.ID, userID, payload); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.invalidate(ctx, r.PropertyID)
+	return nil
+}
+
+// MaintenanceHistory returns the maintenance records of a property owned by
+// userID, most recent first
+func (s *Service) MaintenanceHistory(ctx context.Context, userID, propertyID int) ([]database.MaintenanceRecord, error) {
+	if err := s.Check(ctx, userID, propertyID); err != nil {
+		return nil, err
+	}
+	return s.maintenance(ctx, propertyID)
+}
+
+func (s *Service) maintenance(ctx context.Context, propertyID int) ([]database.MaintenanceRecord, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+maintenanceColumns+`
+		FROM maintenance_records
+		WHERE property_id = $1
+		ORDER BY completed_date DESC, id DESC
+	`, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []database.MaintenanceRecord{}
+	for rows.Next() {
+		m, err := scanMaintenance(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.lineItems(ctx, records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// lineItems loads the cost line items of records
+func (s *Service) lineItems(ctx context.Context, records []database.MaintenanceRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	index := make(map[int]int, len(records))
+	ids := make([]int64, 0, len(records))
+	for i, m := range records {
+		index[m.ID] = i
+		ids = append(ids, int64(m.ID))
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+costItemColumns+`
+		FROM maintenance_cost_items
+		WHERE maintenance_record_id = ANY($1)
+		ORDER BY id
+	`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scanCostItem(rows)
+		if err != nil {
+			return err
+		}
+		m := &records[index[item.MaintenanceRecordID]]
+		m.LineItems = append(m.LineItems, *item)
+	}
+	return rows.Err()
+}
+
+// addLineItems records the cost line items of a new maintenance record
+func addLineItems(ctx context.Context, tx *sql.Tx, recordID int, items []database.CostLineItemRequest) ([]database.CostLineItem, error) {
+	added := make([]database.CostLineItem, 0, len(items))
+	for _, item := range items {
+		row := tx.QueryRowContext(ctx, `
+			INSERT INTO maintenance_cost_items (maintenance_record_id, category, description, amount)
+			VALUES ($1, $2, $3, $4)
+			RETURNING `+costItemColumns,
+			recordID, item.Category, item.Description, item.Amount,
+		)
+		i, err := scanCostItem(row)
+		if err != nil {
+			return nil, err
+		}
+		added = append(added, *i)
+	}
+	return added, nil
+}
+
+// AddMaintenance records maintenance done on a property owned by userID. A
+// linked task must belong to the same property. A cost without line items
+// is recorded as a single "other" item.
+func (s *Service) AddMaintenance(ctx context.Context, userID, propertyID int, req database.CreateMaintenanceRecordRequest) (*database.MaintenanceRecord, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := getOwned(ctx, tx, userID, propertyID); err != nil {
+		return nil, err
+	}
+	if req.TaskID != nil {
+		var exists bool
+		err := tx.QueryRowContext(ctx,
+			"SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1 AND property_id = $2)", *req.TaskID, propertyID,
+		).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, ErrTaskNotFound
+		}
+	}
+
+	items, cost := database.Itemize(req.Cost, req.LineItems)
+	currency := req.Currency
+	if currency == "" {
+		currency = database.DefaultCurrency
+	}
+
+	row := tx.QueryRowContext(ctx, `
+		INSERT INTO maintenance_records (property_id, task_id, title, description, completed_date, cost, currency,
+			contractor, notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING `+maintenanceColumns,
+		propertyID, req.TaskID, req.Title, req.Description, req.CompletedDate, cost, currency, req.Contractor,
+		req.Notes,
+	)
+	m, err := scanMaintenance(row)
+	if err != nil {
+		return nil, err
+	}
+	if m.LineItems, err = addLineItems(ctx, tx, m.ID, items); err != nil {
+		return nil, err
+	}
+
+	payload := maintenancePayload{MaintenanceRecord: *m, PropertyID: propertyID}
+	if err := appendEvent(ctx, tx, events.MaintenanceRecorded, events.AggregateMaintenance, m.ID, userID, payload); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx, propertyID)
+	return m, nil
+}
+
+func appendEvent(ctx context.Context, tx *sql.Tx, eventType events.Type, aggregateType string, aggregateID, userID int, payload interface{}) error {
+	evt, err := events.New(eventType, aggregateType, aggregateID, userID, payload)
+	if err != nil {
+		return err
+	}
+	return events.Append(ctx, tx, evt)
+}
+
+// invalidate drops the cached detail of a property. Failures are only
+// logged: the event recorded with the change invalidates it again.
+func (s *Service) invalidate(ctx context.Context, propertyID int) {
+	if err := s.cache.Cache().Delete(ctx, cache.PropertyDetailKey(propertyID)); err != nil {
+		log.Printf("Failed to invalidate property %d: %v", propertyID, err)
+	}
+}
@@ -0,0 +1,470 @@
+package tasks
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+	"github.com/myideascope/HomeGenie/backend/pkg/events"
+)
+
+// ErrNotFound is returned when a task does not exist or belongs to another user
+var ErrNotFound = errors.New("task not found")
+
+// ErrPropertyNotFound is returned when a task refers to a property the user does not own
+var ErrPropertyNotFound = errors.New("property not found")
+
+// ErrAlreadyCompleted is returned when completing a task that is already completed
+var ErrAlreadyCompleted = errors.New("task is already completed")
+
+// Service manages tasks and their completion
+type Service struct {
+	db *sql.DB
+}
+
+// NewService creates a task service
+func NewService(db *sql.DB) *Service {
+	return &Service{db: db}
+}
+
+// taskColumns selects a task and its property's name from tasks t joined to properties p
+const taskColumns = `t.id, t.user_id, t.property_id, p.name, t.title, t.description, t.priority, t.status,
+	t.category, t.due_date, t.estimated_time, t.assignee, t.notes, t.created_at, t.updated_at, t.completed_at`
+
+const taskFrom = `FROM tasks t JOIN properties p ON p.id = t.property_id`
+
+// openTask matches tasks that still need doing
+const openTask = `t.status IN ('pending', 'in_progress', 'overdue')`
+
+// overdueTask matches open tasks past their due date, whether or not the
+// scheduler has flagged them yet
+const overdueTask = `(t.status = 'overdue' OR (t.status IN ('pending', 'in_progress') AND t.due_date < NOW()))`
+
+func scanTask(row interface{ Scan(...interface{}) error }) (*database.Task, error) {
+	var t database.Task
+	err := row.Scan(&t.ID, &t.UserID, &t.PropertyID, &t.Property, &t.Title, &t.Description, &t.Priority, &t.Status,
+		&t.Category, &t.DueDate, &t.EstimatedTime, &t.Assignee, &t.Notes, &t.CreatedAt, &t.UpdatedAt, &t.CompletedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// taskPayload is the event payload for a task. database.Task hides its
+// property ID from JSON, so it is added back for consumers.
+type taskPayload struct {
+	database.Task
+	PropertyID int `json:"propertyId"`
+}
+
+// maintenancePayload is the event payload for a maintenance record
+type maintenancePayload struct {
+	database.MaintenanceRecord
+	PropertyID int `json:"propertyId"`
+}
+
+func taskEvent(eventType events.Type, t *database.Task) (events.Event, error) {
+	return events.New(eventType, events.AggregateTask, t.ID, t.UserID, taskPayload{Task: *t, PropertyID: t.PropertyID})
+}
+
+// Create adds a task to a property owned by userID
+func (s *Service) Create(ctx context.Context, userID int, req database.CreateTaskRequest) (*database.Task, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := checkProperty(ctx, tx, userID, req.PropertyID); err != nil {
+		return nil, err
+	}
+
+	var id int
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO tasks (user_id, property_id, title, description, priority, category, due_date, estimated_time, assignee, notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`, userID, req.PropertyID, req.Title, req.Description, req.Priority, req.Category, req.DueDate,
+		req.EstimatedTime, req.Assignee, req.Notes,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := getTask(ctx, tx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := appendTaskEvents(ctx, tx, task, events.TaskCreated); err != nil {
+		return nil, err
+	}
+	return task, tx.Commit()
+}
+
+// List returns a page of userID's tasks matching filters, soonest due first
+func (s *Service) List(ctx context.Context, userID int, filters database.TaskFilters) ([]database.Task, int, error) {
+	where := "WHERE t.user_id = $1"
+	args := []interface{}{userID}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filters.Status != nil {
+		if *filters.Status == database.TaskStatusOverdue {
+			where += " AND " + overdueTask
+		} else {
+			where += " AND t.status = " + arg(*filters.Status)
+		}
+	}
+	if filters.Priority != nil {
+		where += " AND t.priority = " + arg(*filters.Priority)
+	}
+	if filters.PropertyID != nil {
+		where += " AND t.property_id = " + arg(*filters.PropertyID)
+	}
+	if filters.Assignee != nil {
+		where += " AND t.assignee = " + arg(*filters.Assignee)
+	}
+	if filters.DueAfter != nil {
+		where += " AND t.due_date >= " + arg(*filters.DueAfter)
+	}
+	if filters.DueBefore != nil {
+		where += " AND t.due_date <= " + arg(*filters.DueBefore)
+	}
+	if filters.Search != nil && *filters.Search != "" {
+		pattern := arg(database.ContainsPattern(*filters.Search))
+		where += " AND (t.title ILIKE " + pattern + " OR t.description ILIKE " + pattern + ")"
+	}
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) "+taskFrom+" "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		%s
+		%s
+		ORDER BY t.due_date ASC NULLS LAST, t.id
+		LIMIT %s OFFSET %s
+	`, taskColumns, taskFrom, where, arg(filters.Limit), arg((filters.Page-1)*filters.Limit))
+
+	tasks, err := s.query(ctx, query, args...)
+	return tasks, total, err
+}
+
+// Upcoming returns userID's open tasks due within the next days days,
+// soonest first
+func (s *Service) Upcoming(ctx context.Context, userID, days int) ([]database.Task, error) {
+	return s.query(ctx, `
+		SELECT `+taskColumns+`
+		`+taskFrom+`
+		WHERE t.user_id = $1 AND `+openTask+`
+			AND t.due_date >= NOW() AND t.due_date < NOW() + make_interval(days => $2)
+		ORDER BY t.due_date, t.id
+	`, userID, days)
+}
+
+// Overdue returns userID's open tasks past their due date, most overdue first
+func (s *Service) Overdue(ctx context.Context, userID int) ([]database.Task, error) {
+	return s.query(ctx, `
+		SELECT `+taskColumns+`
+		`+taskFrom+`
+		WHERE t.user_id = $1 AND `+overdueTask+`
+		ORDER BY t.due_date ASC NULLS LAST, t.id
+	`, userID)
+}
+
+func (s *Service) query(ctx context.Context, query string, args ...interface{}) ([]database.Task, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []database.Task{}
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *t)
+	}
+	return tasks, rows.Err()
+}
+
+// Get returns a task owned by userID
+func (s *Service) Get(ctx context.Context, userID, id int) (*database.Task, error) {
+	return getTask(ctx, s.db, userID, id)
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func getTask(ctx context.Context, q querier, userID, id int) (*database.Task, error) {
+	row := q.QueryRowContext(ctx, `SELECT `+taskColumns+` `+taskFrom+` WHERE t.id = $1 AND t.user_id = $2`, id, userID)
+	t, err := scanTask(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return t, err
+}
+
+// checkProperty returns ErrPropertyNotFound unless userID owns propertyID
+func checkProperty(ctx context.Context, q querier, userID, propertyID int) error {
+	var exists bool
+	err := q.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM properties WHERE id = $1 AND user_id = $2)", propertyID, userID,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrPropertyNotFound
+	}
+	return nil
+}
+
+// Update applies a partial update to a task owned by userID. Moving a task
+// to or from completed sets or clears its completion time.
+func (s *Service) Update(ctx context.Context, userID, id int, req database.UpdateTaskRequest) (*database.Task, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	t, err := getTask(ctx, tx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	wasCompleted := t.Status == database.TaskStatusCompleted
+
+	if req.Title != nil {
+		t.Title = *req.Title
+	}
+	if req.Description != nil {
+		t.Description = req.Description
+	}
+	if req.PropertyID != nil && *req.PropertyID != t.PropertyID {
+		if err := checkProperty(ctx, tx, userID, *req.PropertyID); err != nil {
+			return nil, err
+		}
+		t.PropertyID = *req.PropertyID
+	}
+	if req.Priority != nil {
+		t.Priority = *req.Priority
+	}
+	if req.Status != nil {
+		t.Status = *req.Status
+	}
+	if req.DueDate != nil {
+		t.DueDate = req.DueDate
+	}
+	if req.Category != nil {
+		t.Category = *req.Category
+	}
+	if req.EstimatedTime != nil {
+		t.EstimatedTime = req.EstimatedTime
+	}
+	if req.Assignee != nil {
+		t.Assignee = req.Assignee
+	}
+	if req.Notes != nil {
+		t.Notes = req.Notes
+	}
+
+	completed := t.Status == database.TaskStatusCompleted
+	switch {
+	case completed && req.CompletedAt != nil:
+		t.CompletedAt = req.CompletedAt
+	case completed && !wasCompleted:
+		now := time.Now().UTC()
+		t.CompletedAt = &now
+	case !completed:
+		t.CompletedAt = nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE tasks
+		SET property_id = $1, title = $2, description = $3, priority = $4, status = $5, category = $6,
+			due_date = $7, estimated_time = $8, assignee = $9, notes = $10, completed_at = $11, updated_at = NOW()
+		WHERE id = $12 AND user_id = $13
+	`, t.PropertyID, t.Title, t.Description, t.Priority, t.Status, t.Category,
+		t.DueDate, t.EstimatedTime, t.Assignee, t.Notes, t.CompletedAt, id, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Re-read for the new property's name and updated_at
+	if t, err = getTask(ctx, tx, userID, id); err != nil {
+		return nil, err
+	}
+	types := []events.Type{events.TaskUpdated}
+	if completed && !wasCompleted {
+		types = append(types, events.TaskCompleted)
+	}
+	if err := appendTaskEvents(ctx, tx, t, types...); err != nil {
+		return nil, err
+	}
+	return t, tx.Commit()
+}
+
+// Complete marks a task owned by userID completed. If req includes a
+// maintenance record, one is added to the property's history in the same
+// transaction.
+func (s *Service) Complete(ctx context.Context, userID, id int, req database.CompleteTaskRequest) (*database.CompleteTaskResponse, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	t, err := getTask(ctx, tx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if t.Status == database.TaskStatusCompleted {
+		return nil, ErrAlreadyCompleted
+	}
+
+	completedAt := time.Now().UTC()
+	if req.CompletedAt != nil {
+		completedAt = *req.CompletedAt
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE tasks SET status = $1, completed_at = $2, updated_at = NOW()
+		WHERE id = $3 AND user_id = $4
+	`, database.TaskStatusCompleted, completedAt, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if t, err = getTask(ctx, tx, userID, id); err != nil {
+		return nil, err
+	}
+
+	resp := &database.CompleteTaskResponse{Task: t}
+	evts := make([]events.Event, 0, 2)
+	evt, err := taskEvent(events.TaskCompleted, t)
+	if err != nil {
+		return nil, err
+	}
+	evts = append(evts, evt)
+
+	if req.MaintenanceRecord != nil {
+		record, err := recordMaintenance(ctx, tx, t, completedAt, *req.MaintenanceRecord)
+		if err != nil {
+			return nil, err
+		}
+		resp.MaintenanceRecord = record
+
+		evt, err := events.New(events.MaintenanceRecorded, events.AggregateMaintenance, record.ID, userID,
+			maintenancePayload{MaintenanceRecord: *record, PropertyID: record.PropertyID})
+		if err != nil {
+			return nil, err
+		}
+		evts = append(evts, evt)
+	}
+
+	if err := events.Append(ctx, tx, evts...); err != nil {
+		return nil, err
+	}
+	return resp, tx.Commit()
+}
+
+// recordMaintenance adds a maintenance record for completed task t. A cost
+// without line items is recorded as a single "other" item.
+func recordMaintenance(ctx context.Context, tx *sql.Tx, t *database.Task, completedAt time.Time, req database.CompleteTaskMaintenance) (*database.MaintenanceRecord, error) {
+	items, cost := database.Itemize(req.Cost, req.LineItems)
+	record := database.MaintenanceRecord{
+		PropertyID:    t.PropertyID,
+		TaskID:        &t.ID,
+		Title:         t.Title,
+		Description:   t.Title,
+		CompletedDate: completedAt,
+		Cost:          cost,
+		Currency:      req.Currency,
+		LineItems:     make([]database.CostLineItem, 0, len(items)),
+		Contractor:    req.Contractor,
+		Notes:         req.Notes,
+	}
+	if record.Currency == "" {
+		record.Currency = database.DefaultCurrency
+	}
+	if req.Title != nil {
+		record.Title = *req.Title
+	}
+	if req.Description != nil {
+		record.Description = *req.Description
+	} else if t.Description != nil {
+		record.Description = *t.Description
+	}
+
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO maintenance_records (property_id, task_id, title, description, completed_date, cost, currency,
+			contractor, notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`, record.PropertyID, record.TaskID, record.Title, record.Description, record.CompletedDate,
+		record.Cost, record.Currency, record.Contractor, record.Notes,
+	).Scan(&record.ID, &record.CreatedAt, &record.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		line := database.CostLineItem{MaintenanceRecordID: record.ID, Category: item.Category,
+			Description: item.Description, Amount: item.Amount}
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO maintenance_cost_items (maintenance_record_id, category, description, amount)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id
+		`, record.ID, line.Category, line.Description, line.Amount).Scan(&line.ID)
+		if err != nil {
+			return nil, err
+		}
+		record.LineItems = append(record.LineItems, line)
+	}
+	return &record, nil
+}
+
+// Delete removes a task owned by userID. Maintenance records created when it
+// was completed are kept.
+func (s *Service) Delete(ctx context.Context, userID, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	t, err := getTask(ctx, tx, userID, id)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM tasks WHERE id = $1 AND user_id = $2", id, userID); err != nil {
+		return err
+	}
+	if err := appendTaskEvents(ctx, tx, t, events.TaskDeleted); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func appendTaskEvents(ctx context.Context, tx *sql.Tx, t *database.Task, types ...events.Type) error {
+	evts := make([]events.Event, 0, len(types))
+	for _, eventType := range types {
+		evt, err := taskEvent(eventType, t)
+		if err != nil {
+			return err
+		}
+		evts = append(evts, evt)
+	}
+	return events.Append(ctx, tx, evts...)
+}
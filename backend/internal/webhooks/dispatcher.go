@@ -0,0 +1,352 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+	"github.com/myideascope/HomeGenie/backend/pkg/events"
+)
+
+// DispatcherConfig controls webhook delivery behaviour
+type DispatcherConfig struct {
+	Timeout              time.Duration // per-request timeout
+	MaxAttempts          int           // attempts before a delivery is marked failed
+	BaseBackoff          time.Duration // delay before the first retry, doubled per attempt
+	MaxBackoff           time.Duration // upper bound on retry delay
+	DisableAfter         int           // consecutive failures before a webhook is disabled
+	PollInterval         time.Duration // how often to look for due deliveries
+	BatchSize            int           // deliveries claimed per poll
+	AllowPrivateNetworks bool          // permit loopback/private targets (development only)
+}
+
+// DefaultDispatcherConfig returns production defaults
+func DefaultDispatcherConfig() DispatcherConfig {
+	return DispatcherConfig{
+		Timeout:      10 * time.Second,
+		MaxAttempts:  8,
+		BaseBackoff:  30 * time.Second,
+		MaxBackoff:   6 * time.Hour,
+		DisableAfter: 20,
+		PollInterval: 5 * time.Second,
+		BatchSize:    50,
+	}
+}
+
+// maxLoggedResponse caps how much of a receiver's response is kept in the log
+const maxLoggedResponse = 2048
+
+// envelope is the JSON body POSTed to webhook endpoints
+type envelope struct {
+	ID         string          `json:"id"`
+	Type       events.Type     `json:"type"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Dispatcher fans domain events out to webhook endpoints and delivers them
+// with retries
+type Dispatcher struct {
+	db     *sql.DB
+	config DispatcherConfig
+	client *http.Client
+	wake   chan struct{}
+}
+
+// NewDispatcher creates a webhook dispatcher
+func NewDispatcher(db *sql.DB, config DispatcherConfig) *Dispatcher {
+	dialer := &net.Dialer{Timeout: config.Timeout}
+	if !config.AllowPrivateNetworks {
+		dialer.Control = denyPrivateNetworks
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+
+	return &Dispatcher{
+		db:     db,
+		config: config,
+		client: &http.Client{
+			Timeout:   config.Timeout,
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		wake: make(chan struct{}, 1),
+	}
+}
+
+// HandleEvent is an events.Handler that queues a delivery for every active
+// webhook of the event's user that subscribes to its type. Redelivered events
+// are ignored thanks to the (webhook_id, event_position) uniqueness.
+func (d *Dispatcher) HandleEvent(ctx context.Context, evt events.Event) error {
+	if evt.UserID == 0 {
+		return nil
+	}
+
+	rows, err := d.db.QueryContext(ctx, "SELECT id, event_types FROM webhooks WHERE user_id = $1 AND active = true", evt.UserID)
+	if err != nil {
+		return err
+	}
+
+	var targets []int
+	for rows.Next() {
+		var id int
+		var types database.WebhookEventTypes
+		if err := rows.Scan(&id, &types); err != nil {
+			rows.Close()
+			return err
+		}
+		if types.Matches(string(evt.Type)) {
+			targets = append(targets, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(envelope{
+		ID:         "evt_" + strconv.FormatInt(evt.Position, 10),
+		Type:       evt.Type,
+		OccurredAt: evt.OccurredAt,
+		Data:       evt.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range targets {
+		if _, err := d.db.ExecContext(ctx, `
+			INSERT INTO webhook_deliveries (webhook_id, event_position, event_type, payload)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (webhook_id, event_position) DO NOTHING
+		`, id, evt.Position, string(evt.Type), body); err != nil {
+			return fmt.Errorf("failed to queue delivery for webhook %d: %w", id, err)
+		}
+	}
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Run delivers queued webhooks until ctx is cancelled
+func (d *Dispatcher) Run(ctx context.Context) error {
+	for {
+		if err := d.deliverDue(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Webhook dispatch failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-d.wake:
+		case <-time.After(d.config.PollInterval):
+		}
+	}
+}
+
+// pendingDelivery is a claimed delivery together with its endpoint
+type pendingDelivery struct {
+	id        int64
+	webhookID int
+	eventType string
+	payload   []byte
+	attempts  int
+	url       string
+	secret    string
+}
+
+// deliverDue claims due deliveries and attempts each of them once
+func (d *Dispatcher) deliverDue(ctx context.Context) error {
+	// Push next_attempt_at forward while we work so that other replicas
+	// polling concurrently do not pick up the same rows.
+	lease := d.config.Timeout * time.Duration(d.config.BatchSize+1)
+	rows, err := d.db.QueryContext(ctx, `
+		UPDATE webhook_deliveries wd
+		SET next_attempt_at = NOW() + $1 * INTERVAL '1 millisecond', updated_at = NOW()
+		FROM webhooks w
+		WHERE wd.webhook_id = w.id
+			AND wd.id IN (
+				SELECT d.id FROM webhook_deliveries d
+				JOIN webhooks h ON h.id = d.webhook_id
+				WHERE d.status = 'pending' AND d.next_attempt_at <= NOW() AND h.active = true
+				ORDER BY d.id
+				LIMIT $2
+				FOR UPDATE OF d SKIP LOCKED
+			)
+		RETURNING wd.id, wd.webhook_id, wd.event_type, wd.payload, wd.attempts, w.url, w.secret
+	`, lease.Milliseconds(), d.config.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	var batch []pendingDelivery
+	for rows.Next() {
+		var p pendingDelivery
+		if err := rows.Scan(&p.id, &p.webhookID, &p.eventType, &p.payload, &p.attempts, &p.url, &p.secret); err != nil {
+			rows.Close()
+			return err
+		}
+		batch = append(batch, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, p := range batch {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		d.attempt(ctx, p)
+	}
+	return nil
+}
+
+// attempt sends one delivery and records the outcome
+func (d *Dispatcher) attempt(ctx context.Context, p pendingDelivery) {
+	status, body, sendErr := d.send(ctx, p)
+	attempts := p.attempts + 1
+
+	var statusPtr *int
+	if status != 0 {
+		statusPtr = &status
+	}
+
+	if sendErr == nil {
+		if _, err := d.db.ExecContext(ctx, `
+			UPDATE webhook_deliveries
+			SET status = 'succeeded', attempts = $1, response_status = $2, response_body = $3,
+				last_error = NULL, next_attempt_at = NULL, delivered_at = NOW(), updated_at = NOW()
+			WHERE id = $4
+		`, attempts, statusPtr, body, p.id); err != nil {
+			log.Printf("Failed to record webhook delivery %d: %v", p.id, err)
+		}
+		if _, err := d.db.ExecContext(ctx, "UPDATE webhooks SET consecutive_failures = 0 WHERE id = $1 AND consecutive_failures <> 0", p.webhookID); err != nil {
+			log.Printf("Failed to reset failures for webhook %d: %v", p.webhookID, err)
+		}
+		return
+	}
+
+	errMsg := sendErr.Error()
+	if attempts >= d.config.MaxAttempts {
+		_, err := d.db.ExecContext(ctx, `
+			UPDATE webhook_deliveries
+			SET status = 'failed', attempts = $1, response_status = $2, response_body = $3,
+				last_error = $4, next_attempt_at = NULL, updated_at = NOW()
+			WHERE id = $5
+		`, attempts, statusPtr, body, errMsg, p.id)
+		if err != nil {
+			log.Printf("Failed to record webhook delivery %d: %v", p.id, err)
+		}
+	} else {
+		_, err := d.db.ExecContext(ctx, `
+			UPDATE webhook_deliveries
+			SET attempts = $1, response_status = $2, response_body = $3, last_error = $4,
+				next_attempt_at = NOW() + $5 * INTERVAL '1 millisecond', updated_at = NOW()
+			WHERE id = $6
+		`, attempts, statusPtr, body, errMsg, d.backoff(attempts).Milliseconds(), p.id)
+		if err != nil {
+			log.Printf("Failed to record webhook delivery %d: %v", p.id, err)
+		}
+	}
+
+	var disabled bool
+	err := d.db.QueryRowContext(ctx, `
+		UPDATE webhooks
+		SET consecutive_failures = consecutive_failures + 1,
+			active = CASE WHEN consecutive_failures + 1 >= $1 THEN false ELSE active END,
+			disabled_at = CASE WHEN consecutive_failures + 1 >= $1 AND active THEN NOW() ELSE disabled_at END,
+			updated_at = NOW()
+		WHERE id = $2
+		RETURNING NOT active AND disabled_at = NOW()
+	`, d.config.DisableAfter, p.webhookID).Scan(&disabled)
+	if err != nil {
+		log.Printf("Failed to record failure for webhook %d: %v", p.webhookID, err)
+	} else if disabled {
+		log.Printf("Webhook %d disabled after %d consecutive failures", p.webhookID, d.config.DisableAfter)
+	}
+}
+
+// send POSTs the signed payload and returns the response status and a
+// truncated response body. Non-2xx responses are reported as errors.
+func (d *Dispatcher) send(ctx context.Context, p pendingDelivery) (int, *string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(p.payload))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "HomeGenie-Webhooks/1.0")
+	req.Header.Set(HeaderEvent, p.eventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(p.id, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(p.secret, now, p.payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxLoggedResponse))
+	body := string(data)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, &body, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, &body, nil
+}
+
+// backoff returns the delay before the next attempt, doubling per attempt
+// with up to 10% jitter
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.config.BaseBackoff
+	for i := 1; i < attempts && delay < d.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > d.config.MaxBackoff {
+		delay = d.config.MaxBackoff
+	}
+	return delay + time.Duration(rand.Int63n(int64(delay)/10+1))
+}
+
+// errPrivateNetwork is returned when a webhook resolves to a non-public address
+var errPrivateNetwork = errors.New("webhook target resolves to a private or loopback address")
+
+// denyPrivateNetworks refuses connections to loopback, private and link-local
+// addresses so webhooks cannot be used to probe the internal network
+func denyPrivateNetworks(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsUnspecified() || ip.IsMulticast() {
+		return errPrivateNetwork
+	}
+	return nil
+}
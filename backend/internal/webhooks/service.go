@@ -0,0 +1,232 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+	"github.com/myideascope/HomeGenie/backend/pkg/events"
+)
+
+// ErrNotFound is returned when a webhook does not exist or belongs to another user
+var ErrNotFound = errors.New("webhook not found")
+
+// ErrInvalidEventType is returned when a subscription names an unknown event type
+var ErrInvalidEventType = errors.New("invalid event type")
+
+// ErrInvalidURL is returned when a webhook URL is not an absolute http(s) URL
+var ErrInvalidURL = errors.New("webhook URL must be an absolute http or https URL")
+
+// Service manages webhook registrations and their delivery log
+type Service struct {
+	db *sql.DB
+}
+
+// NewService creates a webhook service
+func NewService(db *sql.DB) *Service {
+	return &Service{db: db}
+}
+
+const webhookColumns = `id, user_id, url, description, secret, event_types, active, consecutive_failures, disabled_at, created_at, updated_at`
+
+func scanWebhook(row interface{ Scan(...interface{}) error }) (*database.Webhook, error) {
+	var w database.Webhook
+	err := row.Scan(&w.ID, &w.UserID, &w.URL, &w.Description, &w.Secret, &w.EventTypes,
+		&w.Active, &w.ConsecutiveFailures, &w.DisabledAt, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// Create registers a new webhook and returns it along with its signing secret
+func (s *Service) Create(ctx context.Context, userID int, req database.CreateWebhookRequest) (*database.Webhook, error) {
+	if err := validateURL(req.URL); err != nil {
+		return nil, err
+	}
+	if err := validateEventTypes(req.EventTypes); err != nil {
+		return nil, err
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	row := s.db.QueryRowContext(ctx, `
+		INSERT INTO webhooks (user_id, url, description, secret, event_types)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+webhookColumns,
+		userID, req.URL, req.Description, secret, database.WebhookEventTypes(req.EventTypes),
+	)
+	return scanWebhook(row)
+}
+
+// List returns all webhooks owned by userID
+func (s *Service) List(ctx context.Context, userID int) ([]database.Webhook, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE user_id = $1 ORDER BY id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []database.Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, *w)
+	}
+	return webhooks, rows.Err()
+}
+
+// Get returns a webhook owned by userID
+func (s *Service) Get(ctx context.Context, userID, id int) (*database.Webhook, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = $1 AND user_id = $2`, id, userID)
+	w, err := scanWebhook(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return w, err
+}
+
+// Update applies a partial update to a webhook owned by userID. Re-activating
+// a disabled webhook resets its failure count.
+func (s *Service) Update(ctx context.Context, userID, id int, req database.UpdateWebhookRequest) (*database.Webhook, error) {
+	w, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if err := validateURL(*req.URL); err != nil {
+			return nil, err
+		}
+		w.URL = *req.URL
+	}
+	if req.Description != nil {
+		w.Description = req.Description
+	}
+	if req.EventTypes != nil {
+		if err := validateEventTypes(*req.EventTypes); err != nil {
+			return nil, err
+		}
+		w.EventTypes = *req.EventTypes
+	}
+	if req.Active != nil {
+		if *req.Active && !w.Active {
+			w.ConsecutiveFailures = 0
+			w.DisabledAt = nil
+		}
+		w.Active = *req.Active
+	}
+
+	row := s.db.QueryRowContext(ctx, `
+		UPDATE webhooks
+		SET url = $1, description = $2, event_types = $3, active = $4,
+			consecutive_failures = $5, disabled_at = $6, updated_at = NOW()
+		WHERE id = $7 AND user_id = $8
+		RETURNING `+webhookColumns,
+		w.URL, w.Description, w.EventTypes, w.Active, w.ConsecutiveFailures, w.DisabledAt, id, userID,
+	)
+	w, err = scanWebhook(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return w, err
+}
+
+// Delete removes a webhook owned by userID along with its delivery log
+func (s *Service) Delete(ctx context.Context, userID, id int) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM webhooks WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Deliveries returns a page of the delivery log for a webhook owned by userID,
+// newest first
+func (s *Service) Deliveries(ctx context.Context, userID, id int, filters database.WebhookDeliveryFilters) ([]database.WebhookDelivery, int, error) {
+	if _, err := s.Get(ctx, userID, id); err != nil {
+		return nil, 0, err
+	}
+
+	where := "WHERE webhook_id = $1"
+	args := []interface{}{id}
+	if filters.Status != nil {
+		where += " AND status = $2"
+		args = append(args, *filters.Status)
+	}
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM webhook_deliveries "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, webhook_id, event_position, event_type, payload, status, attempts,
+			response_status, response_body, last_error, next_attempt_at, delivered_at, created_at, updated_at
+		FROM webhook_deliveries
+		%s
+		ORDER BY id DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)+1, len(args)+2)
+	args = append(args, filters.Limit, (filters.Page-1)*filters.Limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	deliveries := []database.WebhookDelivery{}
+	for rows.Next() {
+		var d database.WebhookDelivery
+		var payload []byte
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.EventPosition, &d.EventType, &payload, &d.Status, &d.Attempts,
+			&d.ResponseStatus, &d.ResponseBody, &d.LastError, &d.NextAttemptAt, &d.DeliveredAt, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			return nil, 0, err
+		}
+		d.Payload = payload
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, total, rows.Err()
+}
+
+// validateURL ensures a webhook URL is an absolute http(s) URL
+func validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+// validateEventTypes ensures every subscribed event type exists
+func validateEventTypes(types []string) error {
+	for _, t := range types {
+		if !events.Type(t).Valid() {
+			return fmt.Errorf("%w: %s", ErrInvalidEventType, t)
+		}
+	}
+	return nil
+}
+
+// generateSecret creates a random signing secret
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
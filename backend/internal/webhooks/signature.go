@@ -0,0 +1,45 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// Headers sent with every webhook delivery
+const (
+	HeaderSignature = "X-HomeGenie-Signature"
+	HeaderTimestamp = "X-HomeGenie-Timestamp"
+	HeaderEvent     = "X-HomeGenie-Event"
+	HeaderDelivery  = "X-HomeGenie-Delivery"
+)
+
+// Sign computes the signature header value for body sent at timestamp.
+// The signed message is "<unix timestamp>.<body>", so receivers can reject
+// replays by checking the timestamp header against their own clock.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature produced by Sign and that timestamp is within
+// tolerance of now. It is provided for receivers written in Go.
+func Verify(secret, signature, timestamp string, body []byte, tolerance time.Duration) bool {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	sent := time.Unix(unix, 0)
+	if age := time.Since(sent); age > tolerance || age < -tolerance {
+		return false
+	}
+
+	expected := Sign(secret, sent, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
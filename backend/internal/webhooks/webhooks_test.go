@@ -0,0 +1,160 @@
+package webhooks
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	const secret = "whsec_test"
+	body := []byte(`{"type":"task.completed"}`)
+	now := time.Now()
+	signature := Sign(secret, now, body)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	if !Verify(secret, signature, timestamp, body, 5*time.Minute) {
+		t.Fatal("Verify() rejected a fresh signature")
+	}
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		timestamp string
+		body      []byte
+	}{
+		{"tampered body", secret, signature, timestamp, []byte(`{"type":"task.deleted"}`)},
+		{"wrong secret", "other", signature, timestamp, body},
+		{"tampered signature", secret, signature[:len(signature)-1] + "0", timestamp, body},
+		{"timestamp changed", secret, signature, strconv.FormatInt(now.Unix()+1, 10), body},
+		{"stale", secret, Sign(secret, now.Add(-6*time.Minute), body),
+			strconv.FormatInt(now.Add(-6*time.Minute).Unix(), 10), body},
+		{"future", secret, Sign(secret, now.Add(6*time.Minute), body),
+			strconv.FormatInt(now.Add(6*time.Minute).Unix(), 10), body},
+		{"malformed timestamp", secret, signature, "yesterday", body},
+		{"missing signature", secret, "", timestamp, body},
+	}
+	for _, tt := range tests {
+		if Verify(tt.secret, tt.signature, tt.timestamp, tt.body, 5*time.Minute) {
+			t.Errorf("Verify() accepted a %s", tt.name)
+		}
+	}
+
+	// Within tolerance on either side of now
+	for _, skew := range []time.Duration{-4 * time.Minute, 4 * time.Minute} {
+		at := now.Add(skew)
+		if !Verify(secret, Sign(secret, at, body), strconv.FormatInt(at.Unix(), 10), body, 5*time.Minute) {
+			t.Errorf("Verify() rejected a signature %v from now", skew)
+		}
+	}
+}
+
+func TestSignFormat(t *testing.T) {
+	got := Sign("secret", time.Unix(1700000000, 0), []byte("{}"))
+	if len(got) != len("sha256=")+64 || got[:7] != "sha256=" {
+		t.Errorf("Sign() = %q, want sha256=<64 hex digits>", got)
+	}
+	if again := Sign("secret", time.Unix(1700000000, 0), []byte("{}")); again != got {
+		t.Error("Sign() is not deterministic")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(nil, DispatcherConfig{BaseBackoff: 30 * time.Second, MaxBackoff: 10 * time.Minute})
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{4, 4 * time.Minute},
+		{5, 8 * time.Minute},
+		{6, 10 * time.Minute},
+		{7, 10 * time.Minute},
+		{100, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			got := d.backoff(tt.attempts)
+			if got < tt.want || got > tt.want+tt.want/10 {
+				t.Errorf("backoff(%d) = %v, want %v plus at most 10%% jitter", tt.attempts, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestDenyPrivateNetworks(t *testing.T) {
+	tests := []struct {
+		address string
+		denied  bool
+	}{
+		{"127.0.0.1:80", true},
+		{"[::1]:443", true},
+		{"10.1.2.3:80", true},
+		{"172.16.0.1:80", true},
+		{"172.31.255.254:80", true},
+		{"192.168.1.10:8080", true},
+		{"169.254.169.254:80", true},
+		{"[fe80::1]:80", true},
+		{"[fd00::1]:80", true},
+		{"0.0.0.0:80", true},
+		{"224.0.0.1:80", true},
+		{"172.32.0.1:443", false},
+		{"93.184.216.34:443", false},
+		{"[2606:4700::1111]:443", false},
+	}
+	for _, tt := range tests {
+		err := denyPrivateNetworks("tcp", tt.address, nil)
+		if denied := errors.Is(err, errPrivateNetwork); denied != tt.denied {
+			t.Errorf("denyPrivateNetworks(%s) = %v, want denied %v", tt.address, err, tt.denied)
+		}
+	}
+}
+
+func TestDispatcherRefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	config := DefaultDispatcherConfig()
+	config.Timeout = time.Second
+	resp, err := NewDispatcher(nil, config).client.Post(server.URL, "application/json", nil)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("dispatcher reached a loopback address")
+	}
+	if !errors.Is(err, errPrivateNetwork) {
+		t.Errorf("dial error = %v, want errPrivateNetwork", err)
+	}
+
+	config.AllowPrivateNetworks = true
+	resp, err = NewDispatcher(nil, config).client.Post(server.URL, "application/json", nil)
+	if err != nil {
+		t.Fatalf("dispatcher allowing private networks error = %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestDispatcherRefusesLoopbackByName(t *testing.T) {
+	// "localhost" resolves before dialing, so the check sees the address
+	if _, err := net.LookupHost("localhost"); err != nil {
+		t.Skip("localhost does not resolve")
+	}
+	config := DefaultDispatcherConfig()
+	config.Timeout = time.Second
+	resp, err := NewDispatcher(nil, config).client.Get("http://localhost:1/")
+	if err == nil {
+		resp.Body.Close()
+	}
+	if !errors.Is(err, errPrivateNetwork) {
+		t.Errorf("dial error = %v, want errPrivateNetwork", err)
+	}
+}
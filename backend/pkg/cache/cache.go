@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrMiss is returned by Get when a key is not cached
+var ErrMiss = errors.New("cache miss")
+
+// Cache stores opaque values with an expiry
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Loader reads through a Cache, collapsing concurrent misses for the same key
+// into a single load so an expiring hot key does not stampede the database
+type Loader struct {
+	cache Cache
+	group singleflight.Group
+}
+
+// NewLoader creates a read-through loader on top of c
+func NewLoader(c Cache) *Loader {
+	return &Loader{cache: c}
+}
+
+// Cache returns the underlying cache, e.g. for invalidation
+func (l *Loader) Cache() Cache {
+	return l.cache
+}
+
+// Load returns the cached value for key, or calls load and caches its result
+// for ttl. Cache errors are treated as misses so a cache outage only costs
+// latency.
+func Load[T any](ctx context.Context, l *Loader, key string, ttl time.Duration, load func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+
+	if data, err := l.cache.Get(ctx, key); err == nil {
+		var value T
+		if err := json.Unmarshal(data, &value); err == nil {
+			return value, nil
+		}
+	}
+
+	result, err, _ := l.group.Do(key, func() (interface{}, error) {
+		// Another caller may have filled the key while we waited
+		if data, err := l.cache.Get(ctx, key); err == nil {
+			var value T
+			if err := json.Unmarshal(data, &value); err == nil {
+				return value, nil
+			}
+		}
+
+		value, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if data, err := json.Marshal(value); err == nil {
+			_ = l.cache.Set(ctx, key, data, jitter(ttl))
+		}
+		return value, nil
+	})
+	if err != nil {
+		return zero, err
+	}
+
+	value, ok := result.(T)
+	if !ok {
+		return zero, fmt.Errorf("cache: unexpected value type %T for key %s", result, key)
+	}
+	return value, nil
+}
+
+// jitter spreads expiries by up to 10% so keys written together do not all
+// expire together
+func jitter(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return ttl
+	}
+	return ttl + time.Duration(rand.Int63n(int64(ttl)/10+1))
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/myideascope/HomeGenie/backend/pkg/events"
+)
+
+// TTLs for cached reads. Entries are also invalidated on writes, so these only
+// bound staleness if an invalidation is lost.
+//
+// User profiles are not cached: this service serves no profile endpoint and
+// never writes users, so nothing would record the event to invalidate them.
+// Add a key and a user.updated event alongside the first profile write path.
+const (
+	DashboardTTL      = 5 * time.Minute
+	PropertyDetailTTL = 30 * time.Minute
+)
+
+// DashboardKey is the cache key for a user's dashboard analytics
+func DashboardKey(userID int) string {
+	return fmt.Sprintf("dashboard:%d", userID)
+}
+
+// PropertyDetailKey is the cache key for a property with rooms and maintenance history
+func PropertyDetailKey(propertyID int) string {
+	return fmt.Sprintf("property:%d", propertyID)
+}
+
+// propertyRef picks the owning property out of an event payload
+type propertyRef struct {
+	PropertyID *int `json:"propertyId"`
+}
+
+// Invalidator returns an events.Handler that drops cached reads affected by
+// each event. Each replica using a MemoryCache must subscribe it through a
+// publisher with its own cursor name so every replica sees every event.
+func Invalidator(c Cache) events.Handler {
+	return func(ctx context.Context, evt events.Event) error {
+		keys := invalidatedKeys(evt)
+		if len(keys) == 0 {
+			return nil
+		}
+		if err := c.Delete(ctx, keys...); err != nil {
+			log.Printf("Failed to invalidate cache for %s event %d: %v", evt.Type, evt.Position, err)
+			return err
+		}
+		return nil
+	}
+}
+
+// invalidatedKeys lists the cache keys made stale by evt
+func invalidatedKeys(evt events.Event) []string {
+	var keys []string
+	if evt.UserID != 0 {
+		keys = append(keys, DashboardKey(evt.UserID))
+	}
+
+	switch evt.AggregateType {
+	case events.AggregateProperty:
+		keys = append(keys, PropertyDetailKey(evt.AggregateID))
+	case events.AggregateTask, events.AggregateRoom, events.AggregateMaintenance:
+		var ref propertyRef
+		if err := evt.Decode(&ref); err == nil && ref.PropertyID != nil {
+			keys = append(keys, PropertyDetailKey(*ref.PropertyID))
+		}
+	}
+	return keys
+}
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// entry is a cached value with its expiry
+type entry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryCache is an in-process Cache for single-replica deployments and
+// development
+type MemoryCache struct {
+	mu      sync.RWMutex
+	entries map[string]entry
+	swept   time.Time
+}
+
+// NewMemoryCache creates an empty in-memory cache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]entry)}
+}
+
+// Get returns the value for key or ErrMiss
+func (c *MemoryCache) Get(_ context.Context, key string) ([]byte, error) {
+	c.mu.RLock()
+	e, ok := c.entries[key]
+	c.mu.RUnlock()
+
+	if !ok || time.Now().After(e.expiresAt) {
+		return nil, ErrMiss
+	}
+	return e.value, nil
+}
+
+// Set stores value under key for ttl
+func (c *MemoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = entry{value: value, expiresAt: now.Add(ttl)}
+
+	// Drop expired entries at most once a minute
+	if now.Sub(c.swept) > time.Minute {
+		c.swept = now
+		for k, e := range c.entries {
+			if now.After(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	return nil
+}
+
+// Delete removes keys from the cache
+func (c *MemoryCache) Delete(_ context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+	return nil
+}
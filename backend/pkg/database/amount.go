@@ -0,0 +1,158 @@
+package database
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the ISO 4217 code used when a request names none
+const DefaultCurrency = "USD"
+
+// Amount is a money amount held exactly as a whole number of hundredths. It
+// is written as a decimal in JSON and NUMERIC columns and read from either
+// JSON numbers or strings, so amounts never pass through float64. Currencies
+// with two or no decimal places fit; see SupportedCurrency and FitsCurrency.
+type Amount int64
+
+// zeroDecimalCurrencies lists the ISO 4217 currencies without a minor unit
+// in use, whose amounts must be whole
+var zeroDecimalCurrencies = map[string]bool{
+	"BIF": true, "CLP": true, "DJF": true, "GNF": true, "ISK": true, "JPY": true, "KMF": true, "KRW": true,
+	"PYG": true, "RWF": true, "UGX": true, "UYI": true, "VND": true, "VUV": true, "XAF": true, "XOF": true,
+	"XPF": true,
+}
+
+// unsupportedCurrencies lists the ISO 4217 currencies an Amount cannot hold:
+// those with three or four decimal places, and the funds and precious metals
+// that have no minor unit at all
+var unsupportedCurrencies = map[string]bool{
+	"BHD": true, "IQD": true, "JOD": true, "KWD": true, "LYD": true, "OMR": true, "TND": true,
+	"CLF": true, "UYW": true,
+	"XAG": true, "XAU": true, "XBA": true, "XBB": true, "XBC": true, "XBD": true, "XDR": true, "XPD": true,
+	"XPT": true, "XSU": true, "XTS": true, "XUA": true, "XXX": true,
+}
+
+// SupportedCurrency reports whether amounts in the ISO 4217 currency code
+// have two or no decimal places, so an Amount holds them exactly
+func SupportedCurrency(code string) bool {
+	return !unsupportedCurrencies[code]
+}
+
+// FitsCurrency reports whether a is a valid amount in the currency code: a
+// whole number for currencies without decimal places. An empty code means
+// DefaultCurrency.
+func (a Amount) FitsCurrency(code string) bool {
+	return !zeroDecimalCurrencies[code] || a%100 == 0
+}
+
+// ParseAmount parses a decimal such as "12", "12.5" or "-0.25" with at most
+// two significant decimal places
+func ParseAmount(s string) (Amount, error) {
+	digits := strings.TrimPrefix(s, "-")
+	whole, frac, _ := strings.Cut(digits, ".")
+	// NUMERIC results such as averages may carry extra zero places
+	if len(frac) > 2 && strings.Trim(frac[2:], "0") == "" {
+		frac = frac[:2]
+	}
+	if whole == "" || len(frac) > 2 || !isDigits(whole) || !isDigits(frac) ||
+		strings.HasSuffix(digits, ".") {
+		return 0, fmt.Errorf("invalid amount %q: expected a decimal with at most two places", s)
+	}
+
+	n, err := strconv.ParseInt(whole+(frac + "00")[:2], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %w", s, err)
+	}
+	if digits != s {
+		n = -n
+	}
+	return Amount(n), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the amount with two decimal places, e.g. "12.50"
+func (a Amount) String() string {
+	n, sign := int64(a), ""
+	if n < 0 {
+		n, sign = -n, "-"
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, n/100, n%100)
+}
+
+// JSONSchemaType describes amounts as decimal numbers in the OpenAPI document
+func (Amount) JSONSchemaType() (string, string) {
+	return "number", "decimal"
+}
+
+// MarshalJSON writes the amount as a JSON number with two decimal places
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON reads a JSON number or a string holding one
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+	parsed, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// Value implements driver.Valuer
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Scan implements sql.Scanner for NUMERIC and integer columns
+func (a *Amount) Scan(src interface{}) error {
+	var err error
+	switch src := src.(type) {
+	case []byte:
+		*a, err = ParseAmount(string(src))
+	case string:
+		*a, err = ParseAmount(src)
+	case int64:
+		*a = Amount(src * 100)
+	default:
+		err = fmt.Errorf("cannot scan %T into Amount", src)
+	}
+	return err
+}
+
+// Itemize returns the line items recorded for a maintenance cost and their
+// total, which is nil when there is no cost. A cost given without line items
+// becomes a single "other" item.
+func Itemize(cost *Amount, items []CostLineItemRequest) ([]CostLineItemRequest, *Amount) {
+	if len(items) == 0 {
+		if cost == nil || *cost == 0 {
+			return []CostLineItemRequest{}, cost
+		}
+		items = []CostLineItemRequest{{Category: CostCategoryOther, Amount: *cost}}
+	}
+	var total Amount
+	for _, item := range items {
+		total += item.Amount
+	}
+	return items, &total
+}
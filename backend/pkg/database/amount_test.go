@@ -0,0 +1,189 @@
+package database
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    Amount
+		wantErr bool
+	}{
+		{s: "12", want: 1200},
+		{s: "12.5", want: 1250},
+		{s: "12.50", want: 1250},
+		{s: "-0.25", want: -25},
+		{s: "0", want: 0},
+		{s: "0.01", want: 1},
+		{s: "1.2300", want: 123},
+		{s: "92233720368547758.07", want: math.MaxInt64},
+		{s: "1.234", wantErr: true},
+		{s: "1.2301", wantErr: true},
+		{s: "92233720368547758.08", wantErr: true},
+		{s: "100000000000000000000", wantErr: true},
+		{s: "", wantErr: true},
+		{s: "-", wantErr: true},
+		{s: "12.", wantErr: true},
+		{s: ".5", wantErr: true},
+		{s: "+1", wantErr: true},
+		{s: "--1", wantErr: true},
+		{s: "1e3", wantErr: true},
+		{s: "1,5", wantErr: true},
+		{s: " 1", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.s)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseAmount(%q) = %v, %v, want %v, error %v", tt.s, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestAmountString(t *testing.T) {
+	tests := []struct {
+		a    Amount
+		want string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{1250, "12.50"},
+		{-25, "-0.25"},
+		{-1200, "-12.00"},
+		{math.MaxInt64, "92233720368547758.07"},
+	}
+	for _, tt := range tests {
+		if got := tt.a.String(); got != tt.want {
+			t.Errorf("Amount(%d).String() = %q, want %q", int64(tt.a), got, tt.want)
+		}
+		if back, err := ParseAmount(tt.want); err != nil || back != tt.a {
+			t.Errorf("ParseAmount(%q) = %v, %v, want %d", tt.want, back, err, int64(tt.a))
+		}
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	tests := []struct {
+		json    string
+		want    Amount
+		wantErr bool
+	}{
+		{json: `12`, want: 1200},
+		{json: `12.5`, want: 1250},
+		{json: `"12.5"`, want: 1250},
+		{json: `-0.25`, want: -25},
+		{json: `1.234`, wantErr: true},
+		{json: `"1.234"`, wantErr: true},
+		{json: `1e2`, wantErr: true},
+		{json: `"abc"`, wantErr: true},
+		{json: `true`, wantErr: true},
+		{json: `92233720368547758.08`, wantErr: true},
+	}
+	for _, tt := range tests {
+		var got Amount
+		err := json.Unmarshal([]byte(tt.json), &got)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Unmarshal(%s) = %v, %v, want %v, error %v", tt.json, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	// null leaves the amount untouched
+	a := Amount(700)
+	if err := json.Unmarshal([]byte(`null`), &a); err != nil || a != 700 {
+		t.Errorf("Unmarshal(null) = %v, %v, want 7.00 unchanged", a, err)
+	}
+
+	type record struct {
+		Cost  *Amount `json:"cost,omitempty"`
+		Spent Amount  `json:"spent"`
+	}
+	cost := Amount(-1999)
+	data, err := json.Marshal(record{Cost: &cost, Spent: 10})
+	if err != nil || string(data) != `{"cost":-19.99,"spent":0.10}` {
+		t.Fatalf("Marshal() = %s, %v", data, err)
+	}
+	var back record
+	if err := json.Unmarshal(data, &back); err != nil || back.Cost == nil || *back.Cost != cost || back.Spent != 10 {
+		t.Errorf("round trip = %+v, %v", back, err)
+	}
+}
+
+func TestAmountScan(t *testing.T) {
+	tests := []struct {
+		src     interface{}
+		want    Amount
+		wantErr bool
+	}{
+		{src: []byte("12.50"), want: 1250},
+		{src: "-0.25", want: -25},
+		{src: []byte("33.3300000000000000"), want: 3333},
+		{src: int64(7), want: 700},
+		{src: []byte("33.333"), wantErr: true},
+		{src: 12.5, wantErr: true},
+		{src: nil, wantErr: true},
+	}
+	for _, tt := range tests {
+		var got Amount
+		err := got.Scan(tt.src)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Scan(%#v) = %v, %v, want %v, error %v", tt.src, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	if v, err := Amount(1250).Value(); err != nil || v != "12.50" {
+		t.Errorf("Value() = %v, %v, want 12.50", v, err)
+	}
+}
+
+func TestCurrencies(t *testing.T) {
+	tests := []struct {
+		code      string
+		supported bool
+		whole     bool
+	}{
+		{"USD", true, false},
+		{"", true, false},
+		{"EUR", true, false},
+		{"JPY", true, true},
+		{"KRW", true, true},
+		{"CLP", true, true},
+		{"VND", true, true},
+		{"KWD", false, false},
+		{"BHD", false, false},
+		{"CLF", false, false},
+		{"XAU", false, false},
+		{"XXX", false, false},
+	}
+	for _, tt := range tests {
+		if got := SupportedCurrency(tt.code); got != tt.supported {
+			t.Errorf("SupportedCurrency(%q) = %v, want %v", tt.code, got, tt.supported)
+		}
+		if !Amount(1200).FitsCurrency(tt.code) {
+			t.Errorf("Amount(12.00).FitsCurrency(%q) = false", tt.code)
+		}
+		if got := Amount(1250).FitsCurrency(tt.code); got == tt.whole {
+			t.Errorf("Amount(12.50).FitsCurrency(%q) = %v, want %v", tt.code, got, !tt.whole)
+		}
+	}
+}
+
+func TestItemize(t *testing.T) {
+	cost := Amount(5000)
+	items, total := Itemize(&cost, nil)
+	if len(items) != 1 || items[0].Category != CostCategoryOther || items[0].Amount != cost || *total != cost {
+		t.Errorf("Itemize(50.00, none) = %+v, %v, want one other item", items, total)
+	}
+
+	items, total = Itemize(nil, []CostLineItemRequest{
+		{Category: CostCategoryLabor, Amount: 3000}, {Category: CostCategoryParts, Amount: 1999},
+	})
+	if len(items) != 2 || total == nil || *total != 4999 {
+		t.Errorf("Itemize(nil, items) total = %v, want 49.99", total)
+	}
+
+	if items, total := Itemize(nil, nil); len(items) != 0 || total != nil {
+		t.Errorf("Itemize(nil, nil) = %+v, %v, want no items and no cost", items, total)
+	}
+}
@@ -0,0 +1,296 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/myideascope/HomeGenie/backend/pkg/requestid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/myideascope/HomeGenie/backend/pkg/database"
+
+// Open opens a database through driverName (which must already be
+// registered, e.g. by importing github.com/lib/pq) and wraps its connections
+// so every query is prefixed with the request ID from its context and traced
+// as a child span of the span in that context
+func Open(driverName, dsn string) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	drv := db.Driver()
+	db.Close()
+
+	var base driver.Connector
+	if dc, ok := drv.(driver.DriverContext); ok {
+		base, err = dc.OpenConnector(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s connector: %w", driverName, err)
+		}
+	} else {
+		base = dsnConnector{dsn: dsn, driver: drv}
+	}
+
+	return sql.OpenDB(&connector{base: base}), nil
+}
+
+// dsnConnector adapts a driver without DriverContext to driver.Connector
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) { return c.driver.Open(c.dsn) }
+func (c dsnConnector) Driver() driver.Driver                        { return c.driver }
+
+// connector wraps every connection opened by base
+type connector struct {
+	base driver.Connector
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	cn, err := c.base.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: cn}, nil
+}
+
+func (c *connector) Driver() driver.Driver { return c.base.Driver() }
+
+// conn annotates queries before handing them to the underlying connection.
+// Optional driver interfaces the underlying connection lacks are reported
+// with driver.ErrSkip so database/sql falls back to its default behaviour.
+type conn struct {
+	driver.Conn
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	ctx, span := startSpan(ctx, "PREPARE", query)
+	annotated := requestid.SQLComment(ctx, query)
+
+	var st driver.Stmt
+	var err error
+	if pc, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		st, err = pc.PrepareContext(ctx, annotated)
+	} else {
+		st, err = c.Conn.Prepare(annotated)
+	}
+	endSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{Stmt: st, query: query}, nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, span := startSpan(ctx, operationName(query), query)
+	result, err := ec.ExecContext(ctx, requestid.SQLComment(ctx, query), args)
+	endSpan(span, err)
+	return result, err
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, span := startSpan(ctx, operationName(query), query)
+	rows, err := qc.QueryContext(ctx, requestid.SQLComment(ctx, query), args)
+	endSpan(span, err)
+	return rows, err
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	spanCtx, span := startSpan(ctx, "BEGIN", "")
+
+	var t driver.Tx
+	var err error
+	if bc, ok := c.Conn.(driver.ConnBeginTx); ok {
+		t, err = bc.BeginTx(spanCtx, opts)
+	} else {
+		t, err = beginDefault(c.Conn, opts)
+	}
+	endSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return &tx{Tx: t, ctx: ctx}, nil
+}
+
+// beginDefault starts a transaction on a connection that cannot take
+// options, refusing options it would silently drop as database/sql does
+func beginDefault(cn driver.Conn, opts driver.TxOptions) (driver.Tx, error) {
+	if sql.IsolationLevel(opts.Isolation) != sql.LevelDefault {
+		return nil, errors.New("sql: driver does not support non-default isolation level")
+	}
+	if opts.ReadOnly {
+		return nil, errors.New("sql: driver does not support read-only transactions")
+	}
+	return cn.Begin()
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *conn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// tx traces commit and rollback under the context the transaction began in
+type tx struct {
+	driver.Tx
+	ctx context.Context
+}
+
+func (t *tx) Commit() error {
+	_, span := startSpan(t.ctx, "COMMIT", "")
+	err := t.Tx.Commit()
+	endSpan(span, err)
+	return err
+}
+
+func (t *tx) Rollback() error {
+	_, span := startSpan(t.ctx, "ROLLBACK", "")
+	err := t.Tx.Rollback()
+	endSpan(span, err)
+	return err
+}
+
+// stmt traces executions of a prepared statement
+type stmt struct {
+	driver.Stmt
+	query string
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	ctx, span := startSpan(ctx, operationName(s.query), s.query)
+
+	var result driver.Result
+	var err error
+	if sc, ok := s.Stmt.(driver.StmtExecContext); ok {
+		result, err = sc.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedToValues(args); err == nil {
+			result, err = s.Stmt.Exec(values)
+		}
+	}
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	ctx, span := startSpan(ctx, operationName(s.query), s.query)
+
+	var rows driver.Rows
+	var err error
+	if sc, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = sc.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedToValues(args); err == nil {
+			rows, err = s.Stmt.Query(values)
+		}
+	}
+	endSpan(span, err)
+	return rows, err
+}
+
+func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// namedToValues converts arguments for drivers without context support
+func namedToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, fmt.Errorf("driver does not support named parameter %s", arg.Name)
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
+
+// startSpan starts a client span for a database call. Calls made without a
+// span in their context (background pollers, for instance) are not traced,
+// so they do not each start a trace of their own.
+func startSpan(ctx context.Context, operation, query string) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, nil
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemPostgreSQL,
+		semconv.DBOperationName(operation),
+	}
+	if query != "" {
+		attrs = append(attrs, semconv.DBQueryText(strings.TrimSpace(query)))
+	}
+
+	return otel.Tracer(tracerName).Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// endSpan records err, if any, and ends span
+func endSpan(span trace.Span, err error) {
+	if span == nil {
+		return
+	}
+	if err != nil && !errors.Is(err, driver.ErrSkip) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// operationName returns the leading SQL keyword of query, e.g. "SELECT"
+func operationName(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+	return strings.ToUpper(fields[0])
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+// legacyDriver opens connections that only implement the original
+// driver.Conn methods, without BeginTx
+type legacyDriver struct{}
+
+func (legacyDriver) Open(string) (driver.Conn, error) { return legacyConn{}, nil }
+
+type legacyConn struct{}
+
+func (legacyConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (legacyConn) Close() error                        { return nil }
+func (legacyConn) Begin() (driver.Tx, error)           { return legacyTx{}, nil }
+
+type legacyTx struct{}
+
+func (legacyTx) Commit() error   { return nil }
+func (legacyTx) Rollback() error { return nil }
+
+func init() {
+	sql.Register("homegenie-legacy", legacyDriver{})
+}
+
+func TestBeginTxWithoutDriverSupport(t *testing.T) {
+	db, err := Open("homegenie-legacy", "")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	tests := []struct {
+		name string
+		opts *sql.TxOptions
+		ok   bool
+	}{
+		{"default", nil, true},
+		{"explicit default", &sql.TxOptions{Isolation: sql.LevelDefault}, true},
+		{"serializable", &sql.TxOptions{Isolation: sql.LevelSerializable}, false},
+		{"read only", &sql.TxOptions{ReadOnly: true}, false},
+	}
+	for _, tt := range tests {
+		tx, err := db.BeginTx(context.Background(), tt.opts)
+		if (err == nil) != tt.ok {
+			t.Errorf("BeginTx(%s) error = %v, want success %v", tt.name, err, tt.ok)
+		}
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Enum types, their constants and the Enums tag map are generated from
+// enums.json, which is the single definition shared by request validation,
+// SQL marshaling and the schema's CHECK constraints.
+//
+//go:generate go run ./internal/enumgen -in enums.json -out enums_gen.go
+
+// enumType is the shape shared by every generated enum
+type enumType interface {
+	~string
+	Valid() bool
+	EnumValues() []string
+}
+
+// enumColumn is a table column whose CHECK constraint mirrors an enum
+type enumColumn struct {
+	Table  string
+	Column string
+	Values []string
+}
+
+// constraintName is the name Postgres gives an inline column CHECK
+func (c enumColumn) constraintName() string {
+	return c.Table + "_" + c.Column + "_check"
+}
+
+// enumValue refuses to write values the schema would reject
+func enumValue[T enumType](v T) (driver.Value, error) {
+	if !v.Valid() {
+		return nil, fmt.Errorf("invalid %T %q", v, string(v))
+	}
+	return string(v), nil
+}
+
+// scanEnum reads a text column into v
+func scanEnum[T enumType](src interface{}, v *T) error {
+	var s string
+	switch src := src.(type) {
+	case string:
+		s = src
+	case []byte:
+		s = string(src)
+	default:
+		return fmt.Errorf("cannot scan %T into %T", src, v)
+	}
+	if !T(s).Valid() {
+		return fmt.Errorf("invalid %T %q", *v, s)
+	}
+	*v = T(s)
+	return nil
+}
+
+// checkClause builds "CHECK (column IN ('a', 'b'))"
+func checkClause(column string, values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	}
+	return fmt.Sprintf("CHECK (%s IN (%s))", column, strings.Join(quoted, ", "))
+}
+
+// enumConstraintsSQL replaces every enum column's CHECK constraint with one
+// built from the current enum values. Columns added by later migrations do
+// not exist yet when an earlier migration runs on a fresh database, so each
+// column is skipped unless it is present; those migrations create the column
+// with its constraint inline.
+func enumConstraintsSQL() string {
+	var b strings.Builder
+	for _, c := range enumColumns {
+		fmt.Fprintf(&b, "DO $$ BEGIN\n")
+		fmt.Fprintf(&b, "IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = '%s' AND column_name = '%s') THEN\n", c.Table, c.Column)
+		fmt.Fprintf(&b, "ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s;\n", c.Table, c.constraintName())
+		fmt.Fprintf(&b, "ALTER TABLE %s ADD CONSTRAINT %s %s;\n", c.Table, c.constraintName(), checkClause(c.Column, c.Values))
+		fmt.Fprintf(&b, "END IF;\nEND $$;\n")
+	}
+	return b.String()
+}
+
+// constraintValue matches the quoted literals in pg_get_constraintdef output
+var constraintValue = regexp.MustCompile(`'((?:[^']|'')*)'`)
+
+// VerifyEnumConstraints compares each enum column's CHECK constraint in the
+// live schema with the Go enum and reports every column where they differ.
+// A mismatch means a new migration calling enumConstraintsSQL is needed.
+func VerifyEnumConstraints(ctx context.Context, db *sql.DB) error {
+	var mismatches []string
+	for _, c := range enumColumns {
+		var definition string
+		err := db.QueryRowContext(ctx, `
+			SELECT pg_get_constraintdef(oid)
+			FROM pg_constraint
+			WHERE conrelid = to_regclass($1) AND conname = $2 AND contype = 'c'
+		`, c.Table, c.constraintName()).Scan(&definition)
+		if err == sql.ErrNoRows {
+			mismatches = append(mismatches, fmt.Sprintf("%s.%s: no CHECK constraint %s", c.Table, c.Column, c.constraintName()))
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read constraint %s: %w", c.constraintName(), err)
+		}
+
+		live := constraintValues(definition)
+		if !sameValues(live, c.Values) {
+			mismatches = append(mismatches, fmt.Sprintf("%s.%s: schema allows [%s], Go allows [%s]",
+				c.Table, c.Column, strings.Join(live, " "), strings.Join(c.Values, " ")))
+		}
+	}
+
+	if len(mismatches) > 0 {
+		return fmt.Errorf("enum constraints out of date: %s", strings.Join(mismatches, "; "))
+	}
+	return nil
+}
+
+// constraintValues returns the values allowed by a CHECK constraint
+// definition as printed by pg_get_constraintdef
+func constraintValues(definition string) []string {
+	var values []string
+	for _, m := range constraintValue.FindAllStringSubmatch(definition, -1) {
+		values = append(values, strings.ReplaceAll(m[1], "''", "'"))
+	}
+	return values
+}
+
+// sameValues compares two value lists ignoring order
+func sameValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,508 @@
+// Code generated by enumgen from enums.json; DO NOT EDIT.
+
+package database
+
+import "database/sql/driver"
+
+// TaskStatus is validated by the "task_status" binding tag
+type TaskStatus string
+
+const (
+	TaskStatusPending    TaskStatus = "pending"
+	TaskStatusInProgress TaskStatus = "in_progress"
+	TaskStatusCompleted  TaskStatus = "completed"
+	TaskStatusOverdue    TaskStatus = "overdue"
+)
+
+// TaskStatusValues lists every TaskStatus in declaration order
+var TaskStatusValues = []TaskStatus{
+	TaskStatusPending,
+	TaskStatusInProgress,
+	TaskStatusCompleted,
+	TaskStatusOverdue,
+}
+
+// Valid reports whether v is a known TaskStatus
+func (v TaskStatus) Valid() bool {
+	switch v {
+	case TaskStatusPending, TaskStatusInProgress, TaskStatusCompleted, TaskStatusOverdue:
+		return true
+	}
+	return false
+}
+
+// EnumValues returns the allowed values as strings
+func (TaskStatus) EnumValues() []string {
+	return []string{"pending", "in_progress", "completed", "overdue"}
+}
+
+// Value implements driver.Valuer
+func (v TaskStatus) Value() (driver.Value, error) {
+	return enumValue(v)
+}
+
+// Scan implements sql.Scanner
+func (v *TaskStatus) Scan(src interface{}) error {
+	return scanEnum(src, v)
+}
+
+// TaskStatusCheck returns the CHECK clause restricting column to TaskStatus values
+func TaskStatusCheck(column string) string {
+	return checkClause(column, TaskStatus("").EnumValues())
+}
+
+// TaskPriority is validated by the "task_priority" binding tag
+type TaskPriority string
+
+const (
+	TaskPriorityLow    TaskPriority = "low"
+	TaskPriorityMedium TaskPriority = "medium"
+	TaskPriorityHigh   TaskPriority = "high"
+)
+
+// TaskPriorityValues lists every TaskPriority in declaration order
+var TaskPriorityValues = []TaskPriority{
+	TaskPriorityLow,
+	TaskPriorityMedium,
+	TaskPriorityHigh,
+}
+
+// Valid reports whether v is a known TaskPriority
+func (v TaskPriority) Valid() bool {
+	switch v {
+	case TaskPriorityLow, TaskPriorityMedium, TaskPriorityHigh:
+		return true
+	}
+	return false
+}
+
+// EnumValues returns the allowed values as strings
+func (TaskPriority) EnumValues() []string {
+	return []string{"low", "medium", "high"}
+}
+
+// Value implements driver.Valuer
+func (v TaskPriority) Value() (driver.Value, error) {
+	return enumValue(v)
+}
+
+// Scan implements sql.Scanner
+func (v *TaskPriority) Scan(src interface{}) error {
+	return scanEnum(src, v)
+}
+
+// TaskPriorityCheck returns the CHECK clause restricting column to TaskPriority values
+func TaskPriorityCheck(column string) string {
+	return checkClause(column, TaskPriority("").EnumValues())
+}
+
+// PropertyType is validated by the "property_type" binding tag
+type PropertyType string
+
+const (
+	PropertyTypeHouse     PropertyType = "house"
+	PropertyTypeApartment PropertyType = "apartment"
+	PropertyTypeCondo     PropertyType = "condo"
+	PropertyTypeTownhouse PropertyType = "townhouse"
+	PropertyTypeOther     PropertyType = "other"
+)
+
+// PropertyTypeValues lists every PropertyType in declaration order
+var PropertyTypeValues = []PropertyType{
+	PropertyTypeHouse,
+	PropertyTypeApartment,
+	PropertyTypeCondo,
+	PropertyTypeTownhouse,
+	PropertyTypeOther,
+}
+
+// Valid reports whether v is a known PropertyType
+func (v PropertyType) Valid() bool {
+	switch v {
+	case PropertyTypeHouse, PropertyTypeApartment, PropertyTypeCondo, PropertyTypeTownhouse, PropertyTypeOther:
+		return true
+	}
+	return false
+}
+
+// EnumValues returns the allowed values as strings
+func (PropertyType) EnumValues() []string {
+	return []string{"house", "apartment", "condo", "townhouse", "other"}
+}
+
+// Value implements driver.Valuer
+func (v PropertyType) Value() (driver.Value, error) {
+	return enumValue(v)
+}
+
+// Scan implements sql.Scanner
+func (v *PropertyType) Scan(src interface{}) error {
+	return scanEnum(src, v)
+}
+
+// PropertyTypeCheck returns the CHECK clause restricting column to PropertyType values
+func PropertyTypeCheck(column string) string {
+	return checkClause(column, PropertyType("").EnumValues())
+}
+
+// RoomType is validated by the "room_type" binding tag
+type RoomType string
+
+const (
+	RoomTypeBedroom  RoomType = "bedroom"
+	RoomTypeBathroom RoomType = "bathroom"
+	RoomTypeKitchen  RoomType = "kitchen"
+	RoomTypeLiving   RoomType = "living"
+	RoomTypeGarage   RoomType = "garage"
+	RoomTypeBasement RoomType = "basement"
+	RoomTypeAttic    RoomType = "attic"
+	RoomTypeOffice   RoomType = "office"
+	RoomTypeOther    RoomType = "other"
+)
+
+// RoomTypeValues lists every RoomType in declaration order
+var RoomTypeValues = []RoomType{
+	RoomTypeBedroom,
+	RoomTypeBathroom,
+	RoomTypeKitchen,
+	RoomTypeLiving,
+	RoomTypeGarage,
+	RoomTypeBasement,
+	RoomTypeAttic,
+	RoomTypeOffice,
+	RoomTypeOther,
+}
+
+// Valid reports whether v is a known RoomType
+func (v RoomType) Valid() bool {
+	switch v {
+	case RoomTypeBedroom, RoomTypeBathroom, RoomTypeKitchen, RoomTypeLiving, RoomTypeGarage, RoomTypeBasement, RoomTypeAttic, RoomTypeOffice, RoomTypeOther:
+		return true
+	}
+	return false
+}
+
+// EnumValues returns the allowed values as strings
+func (RoomType) EnumValues() []string {
+	return []string{"bedroom", "bathroom", "kitchen", "living", "garage", "basement", "attic", "office", "other"}
+}
+
+// Value implements driver.Valuer
+func (v RoomType) Value() (driver.Value, error) {
+	return enumValue(v)
+}
+
+// Scan implements sql.Scanner
+func (v *RoomType) Scan(src interface{}) error {
+	return scanEnum(src, v)
+}
+
+// RoomTypeCheck returns the CHECK clause restricting column to RoomType values
+func RoomTypeCheck(column string) string {
+	return checkClause(column, RoomType("").EnumValues())
+}
+
+// NotificationType is validated by the "notification_type" binding tag
+type NotificationType string
+
+const (
+	NotificationTypeTaskReminder   NotificationType = "task_reminder"
+	NotificationTypeMaintenanceDue NotificationType = "maintenance_due"
+	NotificationTypeSystem         NotificationType = "system"
+	NotificationTypeAlert          NotificationType = "alert"
+)
+
+// NotificationTypeValues lists every NotificationType in declaration order
+var NotificationTypeValues = []NotificationType{
+	NotificationTypeTaskReminder,
+	NotificationTypeMaintenanceDue,
+	NotificationTypeSystem,
+	NotificationTypeAlert,
+}
+
+// Valid reports whether v is a known NotificationType
+func (v NotificationType) Valid() bool {
+	switch v {
+	case NotificationTypeTaskReminder, NotificationTypeMaintenanceDue, NotificationTypeSystem, NotificationTypeAlert:
+		return true
+	}
+	return false
+}
+
+// EnumValues returns the allowed values as strings
+func (NotificationType) EnumValues() []string {
+	return []string{"task_reminder", "maintenance_due", "system", "alert"}
+}
+
+// Value implements driver.Valuer
+func (v NotificationType) Value() (driver.Value, error) {
+	return enumValue(v)
+}
+
+// Scan implements sql.Scanner
+func (v *NotificationType) Scan(src interface{}) error {
+	return scanEnum(src, v)
+}
+
+// NotificationTypeCheck returns the CHECK clause restricting column to NotificationType values
+func NotificationTypeCheck(column string) string {
+	return checkClause(column, NotificationType("").EnumValues())
+}
+
+// NotificationPriority is validated by the "notification_priority" binding tag
+type NotificationPriority string
+
+const (
+	NotificationPriorityLow    NotificationPriority = "low"
+	NotificationPriorityMedium NotificationPriority = "medium"
+	NotificationPriorityHigh   NotificationPriority = "high"
+)
+
+// NotificationPriorityValues lists every NotificationPriority in declaration order
+var NotificationPriorityValues = []NotificationPriority{
+	NotificationPriorityLow,
+	NotificationPriorityMedium,
+	NotificationPriorityHigh,
+}
+
+// Valid reports whether v is a known NotificationPriority
+func (v NotificationPriority) Valid() bool {
+	switch v {
+	case NotificationPriorityLow, NotificationPriorityMedium, NotificationPriorityHigh:
+		return true
+	}
+	return false
+}
+
+// EnumValues returns the allowed values as strings
+func (NotificationPriority) EnumValues() []string {
+	return []string{"low", "medium", "high"}
+}
+
+// Value implements driver.Valuer
+func (v NotificationPriority) Value() (driver.Value, error) {
+	return enumValue(v)
+}
+
+// Scan implements sql.Scanner
+func (v *NotificationPriority) Scan(src interface{}) error {
+	return scanEnum(src, v)
+}
+
+// NotificationPriorityCheck returns the CHECK clause restricting column to NotificationPriority values
+func NotificationPriorityCheck(column string) string {
+	return checkClause(column, NotificationPriority("").EnumValues())
+}
+
+// FileCategory is validated by the "file_category" binding tag
+type FileCategory string
+
+const (
+	FileCategoryAvatar      FileCategory = "avatar"
+	FileCategoryProperty    FileCategory = "property"
+	FileCategoryTask        FileCategory = "task"
+	FileCategoryMaintenance FileCategory = "maintenance"
+)
+
+// FileCategoryValues lists every FileCategory in declaration order
+var FileCategoryValues = []FileCategory{
+	FileCategoryAvatar,
+	FileCategoryProperty,
+	FileCategoryTask,
+	FileCategoryMaintenance,
+}
+
+// Valid reports whether v is a known FileCategory
+func (v FileCategory) Valid() bool {
+	switch v {
+	case FileCategoryAvatar, FileCategoryProperty, FileCategoryTask, FileCategoryMaintenance:
+		return true
+	}
+	return false
+}
+
+// EnumValues returns the allowed values as strings
+func (FileCategory) EnumValues() []string {
+	return []string{"avatar", "property", "task", "maintenance"}
+}
+
+// Value implements driver.Valuer
+func (v FileCategory) Value() (driver.Value, error) {
+	return enumValue(v)
+}
+
+// Scan implements sql.Scanner
+func (v *FileCategory) Scan(src interface{}) error {
+	return scanEnum(src, v)
+}
+
+// FileCategoryCheck returns the CHECK clause restricting column to FileCategory values
+func FileCategoryCheck(column string) string {
+	return checkClause(column, FileCategory("").EnumValues())
+}
+
+// FileStatus is validated by the "file_status" binding tag
+type FileStatus string
+
+const (
+	FileStatusPending     FileStatus = "pending"
+	FileStatusAvailable   FileStatus = "available"
+	FileStatusQuarantined FileStatus = "quarantined"
+)
+
+// FileStatusValues lists every FileStatus in declaration order
+var FileStatusValues = []FileStatus{
+	FileStatusPending,
+	FileStatusAvailable,
+	FileStatusQuarantined,
+}
+
+// Valid reports whether v is a known FileStatus
+func (v FileStatus) Valid() bool {
+	switch v {
+	case FileStatusPending, FileStatusAvailable, FileStatusQuarantined:
+		return true
+	}
+	return false
+}
+
+// EnumValues returns the allowed values as strings
+func (FileStatus) EnumValues() []string {
+	return []string{"pending", "available", "quarantined"}
+}
+
+// Value implements driver.Valuer
+func (v FileStatus) Value() (driver.Value, error) {
+	return enumValue(v)
+}
+
+// Scan implements sql.Scanner
+func (v *FileStatus) Scan(src interface{}) error {
+	return scanEnum(src, v)
+}
+
+// FileStatusCheck returns the CHECK clause restricting column to FileStatus values
+func FileStatusCheck(column string) string {
+	return checkClause(column, FileStatus("").EnumValues())
+}
+
+// CostCategory is validated by the "cost_category" binding tag
+type CostCategory string
+
+const (
+	CostCategoryParts CostCategory = "parts"
+	CostCategoryLabor CostCategory = "labor"
+	CostCategoryTax   CostCategory = "tax"
+	CostCategoryOther CostCategory = "other"
+)
+
+// CostCategoryValues lists every CostCategory in declaration order
+var CostCategoryValues = []CostCategory{
+	CostCategoryParts,
+	CostCategoryLabor,
+	CostCategoryTax,
+	CostCategoryOther,
+}
+
+// Valid reports whether v is a known CostCategory
+func (v CostCategory) Valid() bool {
+	switch v {
+	case CostCategoryParts, CostCategoryLabor, CostCategoryTax, CostCategoryOther:
+		return true
+	}
+	return false
+}
+
+// EnumValues returns the allowed values as strings
+func (CostCategory) EnumValues() []string {
+	return []string{"parts", "labor", "tax", "other"}
+}
+
+// Value implements driver.Valuer
+func (v CostCategory) Value() (driver.Value, error) {
+	return enumValue(v)
+}
+
+// Scan implements sql.Scanner
+func (v *CostCategory) Scan(src interface{}) error {
+	return scanEnum(src, v)
+}
+
+// CostCategoryCheck returns the CHECK clause restricting column to CostCategory values
+func CostCategoryCheck(column string) string {
+	return checkClause(column, CostCategory("").EnumValues())
+}
+
+// WebhookDeliveryStatus is validated by the "webhook_delivery_status" binding tag
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDeliveryStatusValues lists every WebhookDeliveryStatus in declaration order
+var WebhookDeliveryStatusValues = []WebhookDeliveryStatus{
+	WebhookDeliveryStatusPending,
+	WebhookDeliveryStatusSucceeded,
+	WebhookDeliveryStatusFailed,
+}
+
+// Valid reports whether v is a known WebhookDeliveryStatus
+func (v WebhookDeliveryStatus) Valid() bool {
+	switch v {
+	case WebhookDeliveryStatusPending, WebhookDeliveryStatusSucceeded, WebhookDeliveryStatusFailed:
+		return true
+	}
+	return false
+}
+
+// EnumValues returns the allowed values as strings
+func (WebhookDeliveryStatus) EnumValues() []string {
+	return []string{"pending", "succeeded", "failed"}
+}
+
+// Value implements driver.Valuer
+func (v WebhookDeliveryStatus) Value() (driver.Value, error) {
+	return enumValue(v)
+}
+
+// Scan implements sql.Scanner
+func (v *WebhookDeliveryStatus) Scan(src interface{}) error {
+	return scanEnum(src, v)
+}
+
+// WebhookDeliveryStatusCheck returns the CHECK clause restricting column to WebhookDeliveryStatus values
+func WebhookDeliveryStatusCheck(column string) string {
+	return checkClause(column, WebhookDeliveryStatus("").EnumValues())
+}
+
+// Enums maps each binding tag to the values it accepts
+var Enums = map[string][]string{
+	"task_status":             TaskStatus("").EnumValues(),
+	"task_priority":           TaskPriority("").EnumValues(),
+	"property_type":           PropertyType("").EnumValues(),
+	"room_type":               RoomType("").EnumValues(),
+	"notification_type":       NotificationType("").EnumValues(),
+	"notification_priority":   NotificationPriority("").EnumValues(),
+	"file_category":           FileCategory("").EnumValues(),
+	"file_status":             FileStatus("").EnumValues(),
+	"cost_category":           CostCategory("").EnumValues(),
+	"webhook_delivery_status": WebhookDeliveryStatus("").EnumValues(),
+}
+
+// enumColumns lists the columns constrained by each enum
+var enumColumns = []enumColumn{
+	{Table: "tasks", Column: "status", Values: TaskStatus("").EnumValues()},
+	{Table: "tasks", Column: "priority", Values: TaskPriority("").EnumValues()},
+	{Table: "properties", Column: "type", Values: PropertyType("").EnumValues()},
+	{Table: "rooms", Column: "type", Values: RoomType("").EnumValues()},
+	{Table: "notifications", Column: "type", Values: NotificationType("").EnumValues()},
+	{Table: "notifications", Column: "priority", Values: NotificationPriority("").EnumValues()},
+	{Table: "files", Column: "category", Values: FileCategory("").EnumValues()},
+	{Table: "files", Column: "status", Values: FileStatus("").EnumValues()},
+	{Table: "maintenance_cost_items", Column: "category", Values: CostCategory("").EnumValues()},
+	{Table: "property_budgets", Column: "category", Values: CostCategory("").EnumValues()},
+	{Table: "webhook_deliveries", Column: "status", Values: WebhookDeliveryStatus("").EnumValues()},
+}
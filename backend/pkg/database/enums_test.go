@@ -0,0 +1,136 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+func TestCheckClause(t *testing.T) {
+	got := checkClause("type", []string{"house", "o'brien"})
+	want := "CHECK (type IN ('house', 'o''brien'))"
+	if got != want {
+		t.Errorf("checkClause() = %q, want %q", got, want)
+	}
+}
+
+func TestConstraintValues(t *testing.T) {
+	tests := []struct {
+		definition string
+		want       []string
+	}{
+		{
+			// How Postgres prints an inline "CHECK (type IN (...))" on a VARCHAR
+			definition: "CHECK (((type)::text = ANY ((ARRAY['house'::character varying, " +
+				"'o''brien'::character varying])::text[])))",
+			want: []string{"house", "o'brien"},
+		},
+		{
+			definition: "CHECK (((status)::text = 'pending'::text))",
+			want:       []string{"pending"},
+		},
+		{definition: "CHECK ((amount > (0)::numeric))", want: nil},
+	}
+	for _, tt := range tests {
+		if got := constraintValues(tt.definition); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("constraintValues(%q) = %q, want %q", tt.definition, got, tt.want)
+		}
+	}
+}
+
+func TestSameValues(t *testing.T) {
+	if !sameValues([]string{"a", "b"}, []string{"b", "a"}) {
+		t.Error("sameValues() is order sensitive")
+	}
+	if sameValues([]string{"a", "b"}, []string{"a"}) || sameValues([]string{"a", "a"}, []string{"a", "b"}) {
+		t.Error("sameValues() matched different values")
+	}
+}
+
+func TestEnumConstraintsSQL(t *testing.T) {
+	up := enumConstraintsSQL()
+	for _, c := range enumColumns {
+		guard := fmt.Sprintf("table_name = '%s' AND column_name = '%s'", c.Table, c.Column)
+		add := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s;", c.Table, c.constraintName(),
+			checkClause(c.Column, c.Values))
+		if !strings.Contains(up, guard) || !strings.Contains(up, add) {
+			t.Errorf("enumConstraintsSQL() does not rebuild %s.%s", c.Table, c.Column)
+		}
+	}
+}
+
+// testSchema migrates a fresh schema in the database named by
+// TEST_DATABASE_URL, skipping the test when it is unset. The returned
+// handle uses a single connection whose search_path is the new schema.
+func testSchema(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	ctx := context.Background()
+	schema := fmt.Sprintf("enum_test_%d", time.Now().UnixNano())
+	if _, err := db.ExecContext(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	t.Cleanup(func() { db.ExecContext(ctx, "DROP SCHEMA "+schema+" CASCADE") })
+	if _, err := db.ExecContext(ctx, "SET search_path TO "+schema); err != nil {
+		t.Fatalf("failed to set search_path: %v", err)
+	}
+
+	if err := RunMigrationsContext(ctx, db); err != nil {
+		t.Fatalf("RunMigrationsContext() error = %v", err)
+	}
+	return db
+}
+
+func TestVerifyEnumConstraints(t *testing.T) {
+	db := testSchema(t)
+	ctx := context.Background()
+
+	if err := VerifyEnumConstraints(ctx, db); err != nil {
+		t.Fatalf("VerifyEnumConstraints() on a freshly migrated schema = %v", err)
+	}
+
+	_, err := db.ExecContext(ctx, `
+		ALTER TABLE tasks DROP CONSTRAINT tasks_status_check;
+		ALTER TABLE tasks ADD CONSTRAINT tasks_status_check CHECK (status IN ('pending'));
+		ALTER TABLE rooms DROP CONSTRAINT rooms_type_check;
+	`)
+	if err != nil {
+		t.Fatalf("failed to alter constraints: %v", err)
+	}
+
+	err = VerifyEnumConstraints(ctx, db)
+	if err == nil {
+		t.Fatal("VerifyEnumConstraints() = nil after constraints drifted")
+	}
+	for _, want := range []string{"tasks.status: schema allows [pending]", "rooms.type: no CHECK constraint"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("VerifyEnumConstraints() = %q, want it to report %q", err, want)
+		}
+	}
+
+	if _, err := db.ExecContext(ctx, enumConstraintsSQL()); err != nil {
+		t.Fatalf("enumConstraintsSQL() failed: %v", err)
+	}
+	if err := VerifyEnumConstraints(ctx, db); err != nil {
+		t.Errorf("VerifyEnumConstraints() after rebuilding = %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+// Command enumgen generates typed enums for the database package from
+// enums.json. Each enum gets a string type with constants, Valid and SQL
+// marshaling, a binding tag for request validation and a CHECK clause
+// builder for migrations, so Go and the schema share one definition. JSON
+// uses the underlying string; requests are checked by the binding tag so
+// invalid values are reported per field.
+//
+// Run it with go generate from pkg/database after editing enums.json.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// enumSpec is one entry in enums.json
+type enumSpec struct {
+	Name   string   `json:"name"`
+	Tag    string   `json:"tag"`
+	Values []string `json:"values"`
+	// Columns constrained by the enum, as "table.column"
+	Columns []string `json:"columns"`
+}
+
+// ConstName returns the constant name for value, e.g. TaskStatusInProgress
+func (e enumSpec) ConstName(value string) string {
+	var b strings.Builder
+	b.WriteString(e.Name)
+	for _, part := range strings.Split(value, "_") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// column is a table column constrained by an enum
+type column struct {
+	Enum   string
+	Table  string
+	Column string
+}
+
+func main() {
+	in := flag.String("in", "enums.json", "enum specification")
+	out := flag.String("out", "enums_gen.go", "generated Go file")
+	flag.Parse()
+
+	data, err := os.ReadFile(*in)
+	if err != nil {
+		log.Fatalf("enumgen: %v", err)
+	}
+	var specs []enumSpec
+	if err := json.Unmarshal(data, &specs); err != nil {
+		log.Fatalf("enumgen: failed to parse %s: %v", *in, err)
+	}
+
+	var columns []column
+	for _, spec := range specs {
+		if spec.Name == "" || spec.Tag == "" || len(spec.Values) == 0 {
+			log.Fatalf("enumgen: %s: name, tag and values are required", *in)
+		}
+		for _, c := range spec.Columns {
+			table, col, ok := strings.Cut(c, ".")
+			if !ok {
+				log.Fatalf("enumgen: %s: column %q must be table.column", spec.Name, c)
+			}
+			columns = append(columns, column{Enum: spec.Name, Table: table, Column: col})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := generated.Execute(&buf, struct {
+		Source  string
+		Enums   []enumSpec
+		Columns []column
+	}{*in, specs, columns}); err != nil {
+		log.Fatalf("enumgen: %v", err)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("enumgen: generated invalid Go: %v\n%s", err, buf.Bytes())
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatalf("enumgen: %v", err)
+	}
+	fmt.Printf("enumgen: wrote %d enums to %s\n", len(specs), *out)
+}
+
+var generated = template.Must(template.New("enums").Parse(`// Code generated by enumgen from {{.Source}}; DO NOT EDIT.
+
+package database
+
+import "database/sql/driver"
+{{range $e := .Enums}}
+// {{$e.Name}} is validated by the "{{$e.Tag}}" binding tag
+type {{$e.Name}} string
+
+const (
+{{- range $e.Values}}
+	{{$e.ConstName .}} {{$e.Name}} = "{{.}}"
+{{- end}}
+)
+
+// {{$e.Name}}Values lists every {{$e.Name}} in declaration order
+var {{$e.Name}}Values = []{{$e.Name}}{
+{{- range $e.Values}}
+	{{$e.ConstName .}},
+{{- end}}
+}
+
+// Valid reports whether v is a known {{$e.Name}}
+func (v {{$e.Name}}) Valid() bool {
+	switch v {
+	case {{range $i, $v := $e.Values}}{{if $i}}, {{end}}{{$e.ConstName $v}}{{end}}:
+		return true
+	}
+	return false
+}
+
+// EnumValues returns the allowed values as strings
+func ({{$e.Name}}) EnumValues() []string {
+	return []string{ {{- range $i, $v := $e.Values}}{{if $i}}, {{end}}"{{$v}}"{{end -}} }
+}
+
+// Value implements driver.Valuer
+func (v {{$e.Name}}) Value() (driver.Value, error) {
+	return enumValue(v)
+}
+
+// Scan implements sql.Scanner
+func (v *{{$e.Name}}) Scan(src interface{}) error {
+	return scanEnum(src, v)
+}
+
+// {{$e.Name}}Check returns the CHECK clause restricting column to {{$e.Name}} values
+func {{$e.Name}}Check(column string) string {
+	return checkClause(column, {{$e.Name}}("").EnumValues())
+}
+{{end}}
+// Enums maps each binding tag to the values it accepts
+var Enums = map[string][]string{
+{{- range .Enums}}
+	"{{.Tag}}": {{.Name}}("").EnumValues(),
+{{- end}}
+}
+
+// enumColumns lists the columns constrained by each enum
+var enumColumns = []enumColumn{
+{{- range .Columns}}
+	{Table: "{{.Table}}", Column: "{{.Column}}", Values: {{.Enum}}("").EnumValues()},
+{{- end}}
+}
+`))
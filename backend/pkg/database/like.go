@@ -0,0 +1,13 @@
+package database
+
+import "strings"
+
+// likeEscaper escapes the LIKE wildcards and Postgres's default escape
+// character so they match literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ContainsPattern returns a LIKE/ILIKE pattern matching values that contain
+// term literally, so a search for "100%" does not match every row
+func ContainsPattern(term string) string {
+	return "%" + likeEscaper.Replace(term) + "%"
+}
@@ -0,0 +1,22 @@
+package database
+
+import "testing"
+
+func TestContainsPattern(t *testing.T) {
+	tests := []struct {
+		term string
+		want string
+	}{
+		{"sink", `%sink%`},
+		{"100%", `%100\%%`},
+		{"hot_water", `%hot\_water%`},
+		{`C:\garage`, `%C:\\garage%`},
+		{`\%_`, `%\\\%\_%`},
+		{"", `%%`},
+	}
+	for _, tt := range tests {
+		if got := ContainsPattern(tt.term); got != tt.want {
+			t.Errorf("ContainsPattern(%q) = %q, want %q", tt.term, got, tt.want)
+		}
+	}
+}
@@ -1,9 +1,14 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
 // Migration represents a database migration
@@ -241,19 +246,303 @@ var migrations = []Migration{
 		`,
 		Down: `DROP TABLE IF EXISTS schema_migrations CASCADE;`,
 	},
+	{
+		Version: "011_create_outbox_table",
+		Up: `
+			CREATE TABLE IF NOT EXISTS outbox (
+				id BIGSERIAL PRIMARY KEY,
+				event_type VARCHAR(100) NOT NULL,
+				aggregate_type VARCHAR(50) NOT NULL,
+				aggregate_id INTEGER NOT NULL,
+				user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+				payload JSONB NOT NULL DEFAULT '{}',
+				occurred_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_outbox_event_type ON outbox(event_type);
+			CREATE INDEX IF NOT EXISTS idx_outbox_aggregate ON outbox(aggregate_type, aggregate_id);
+			CREATE INDEX IF NOT EXISTS idx_outbox_occurred_at ON outbox(occurred_at);
+
+			CREATE TABLE IF NOT EXISTS outbox_cursors (
+				name VARCHAR(100) PRIMARY KEY,
+				position BIGINT NOT NULL DEFAULT 0,
+				updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+			);
+		`,
+		Down: `
+			DROP TABLE IF EXISTS outbox_cursors CASCADE;
+			DROP TABLE IF EXISTS outbox CASCADE;
+		`,
+	},
+	{
+		Version: "012_create_webhooks_tables",
+		Up: `
+			CREATE TABLE IF NOT EXISTS webhooks (
+				id SERIAL PRIMARY KEY,
+				user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				url VARCHAR(2000) NOT NULL,
+				description VARCHAR(255),
+				secret VARCHAR(255) NOT NULL,
+				event_types JSONB NOT NULL DEFAULT '[]',
+				active BOOLEAN NOT NULL DEFAULT true,
+				consecutive_failures INTEGER NOT NULL DEFAULT 0,
+				disabled_at TIMESTAMP WITH TIME ZONE,
+				created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+				updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_webhooks_user_id ON webhooks(user_id);
+			CREATE INDEX IF NOT EXISTS idx_webhooks_active ON webhooks(active);
+
+			CREATE TABLE IF NOT EXISTS webhook_deliveries (
+				id BIGSERIAL PRIMARY KEY,
+				webhook_id INTEGER NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+				event_position BIGINT NOT NULL,
+				event_type VARCHAR(100) NOT NULL,
+				payload JSONB NOT NULL,
+				status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+				attempts INTEGER NOT NULL DEFAULT 0,
+				response_status INTEGER,
+				response_body TEXT,
+				last_error TEXT,
+				next_attempt_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+				delivered_at TIMESTAMP WITH TIME ZONE,
+				created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+				updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+				UNIQUE(webhook_id, event_position)
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id);
+			CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+		`,
+		Down: `
+			DROP TABLE IF EXISTS webhook_deliveries CASCADE;
+			DROP TABLE IF EXISTS webhooks CASCADE;
+		`,
+	},
+	{
+		Version: "013_create_idempotency_keys_table",
+		Up: `
+			CREATE TABLE IF NOT EXISTS idempotency_keys (
+				key VARCHAR(400) PRIMARY KEY,
+				fingerprint VARCHAR(64) NOT NULL,
+				completed BOOLEAN NOT NULL DEFAULT false,
+				status_code INTEGER,
+				headers JSONB,
+				body BYTEA,
+				created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+				expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+		`,
+		Down: `DROP TABLE IF EXISTS idempotency_keys CASCADE;`,
+	},
+	{
+		// Rebuilds every enum CHECK constraint from the generated Go enums.
+		// The constraints are only rebuilt when this runs; after changing
+		// enums.json, add a new migration with the same Up.
+		Version: "014_sync_enum_check_constraints",
+		Up:      enumConstraintsSQL(),
+		// The rebuilt constraints match those created by earlier migrations
+		Down: `SELECT 1;`,
+	},
+	{
+		// Files are stored under content-addressed keys in file_path and
+		// served through signed URLs, so url is no longer written
+		Version: "015_add_file_content_hash",
+		Up: `
+			ALTER TABLE files ADD COLUMN IF NOT EXISTS content_hash VARCHAR(64);
+			ALTER TABLE files ALTER COLUMN url DROP NOT NULL;
+
+			CREATE INDEX IF NOT EXISTS idx_files_content_hash ON files(content_hash);
+		`,
+		Down: `
+			DROP INDEX IF EXISTS idx_files_content_hash;
+			UPDATE files SET url = '' WHERE url IS NULL;
+			ALTER TABLE files ALTER COLUMN url SET NOT NULL;
+			ALTER TABLE files DROP COLUMN IF EXISTS content_hash;
+		`,
+	},
+	{
+		// attached_at marks files that have been attached at least once, so
+		// they can be cleaned up as soon as their last parent is deleted
+		Version: "016_create_attachment_tables",
+		Up: `
+			ALTER TABLE files ADD COLUMN IF NOT EXISTS attached_at TIMESTAMP WITH TIME ZONE;
+
+			CREATE TABLE IF NOT EXISTS task_attachments (
+				id SERIAL PRIMARY KEY,
+				task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+				file_id INTEGER NOT NULL REFERENCES files(id) ON DELETE CASCADE,
+				position INTEGER NOT NULL DEFAULT 0,
+				caption VARCHAR(500),
+				created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+				UNIQUE (task_id, file_id)
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_task_attachments_file_id ON task_attachments(file_id);
+
+			CREATE TABLE IF NOT EXISTS property_attachments (
+				id SERIAL PRIMARY KEY,
+				property_id INTEGER NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+				file_id INTEGER NOT NULL REFERENCES files(id) ON DELETE CASCADE,
+				position INTEGER NOT NULL DEFAULT 0,
+				caption VARCHAR(500),
+				created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+				UNIQUE (property_id, file_id)
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_property_attachments_file_id ON property_attachments(file_id);
+
+			CREATE TABLE IF NOT EXISTS room_attachments (
+				id SERIAL PRIMARY KEY,
+				room_id INTEGER NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+				file_id INTEGER NOT NULL REFERENCES files(id) ON DELETE CASCADE,
+				position INTEGER NOT NULL DEFAULT 0,
+				caption VARCHAR(500),
+				created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+				UNIQUE (room_id, file_id)
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_room_attachments_file_id ON room_attachments(file_id);
+
+			CREATE TABLE IF NOT EXISTS maintenance_record_attachments (
+				id SERIAL PRIMARY KEY,
+				maintenance_record_id INTEGER NOT NULL REFERENCES maintenance_records(id) ON DELETE CASCADE,
+				file_id INTEGER NOT NULL REFERENCES files(id) ON DELETE CASCADE,
+				position INTEGER NOT NULL DEFAULT 0,
+				caption VARCHAR(500),
+				created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+				UNIQUE (maintenance_record_id, file_id)
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_maintenance_record_attachments_file_id ON maintenance_record_attachments(file_id);
+		`,
+		Down: `
+			DROP TABLE IF EXISTS task_attachments;
+			DROP TABLE IF EXISTS property_attachments;
+			DROP TABLE IF EXISTS room_attachments;
+			DROP TABLE IF EXISTS maintenance_record_attachments;
+			ALTER TABLE files DROP COLUMN IF EXISTS attached_at;
+		`,
+	},
+	{
+		// Images are stored upright with their metadata stripped, alongside
+		// resized variants stored next to the original
+		Version: "017_add_file_image_variants",
+		Up: `
+			ALTER TABLE files ADD COLUMN IF NOT EXISTS width INTEGER;
+			ALTER TABLE files ADD COLUMN IF NOT EXISTS height INTEGER;
+			ALTER TABLE files ADD COLUMN IF NOT EXISTS variants JSONB NOT NULL DEFAULT '[]';
+		`,
+		Down: `
+			ALTER TABLE files DROP COLUMN IF EXISTS variants;
+			ALTER TABLE files DROP COLUMN IF EXISTS height;
+			ALTER TABLE files DROP COLUMN IF EXISTS width;
+		`,
+	},
+	{
+		// Files uploaded before scanning existed stay available. scan_threat
+		// names the signature that quarantined a file.
+		Version: "018_add_file_scan_status",
+		Up: `
+			ALTER TABLE files ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'available' ` + FileStatusCheck("status") + `;
+			ALTER TABLE files ADD COLUMN IF NOT EXISTS scan_threat VARCHAR(255);
+			ALTER TABLE files ADD COLUMN IF NOT EXISTS scanned_at TIMESTAMP WITH TIME ZONE;
+
+			CREATE INDEX IF NOT EXISTS idx_files_pending ON files(created_at) WHERE status = 'pending';
+		`,
+		Down: `
+			DROP INDEX IF EXISTS idx_files_pending;
+			ALTER TABLE files DROP COLUMN IF EXISTS scanned_at;
+			ALTER TABLE files DROP COLUMN IF EXISTS scan_threat;
+			ALTER TABLE files DROP COLUMN IF EXISTS status;
+		`,
+	},
+	{
+		// Existing costs become a single "other" line item in USD. cost
+		// stays on the record as the total of its line items. budget_alerts
+		// records each threshold alerted, so an alert is sent once however
+		// often spend is rechecked.
+		Version: "019_create_cost_tracking_tables",
+		Up: `
+			ALTER TABLE maintenance_records ALTER COLUMN cost TYPE NUMERIC(12,2);
+			ALTER TABLE maintenance_records ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+			CREATE TABLE IF NOT EXISTS maintenance_cost_items (
+				id SERIAL PRIMARY KEY,
+				maintenance_record_id INTEGER NOT NULL REFERENCES maintenance_records(id) ON DELETE CASCADE,
+				category VARCHAR(20) NOT NULL ` + CostCategoryCheck("category") + `,
+				description VARCHAR(255),
+				amount NUMERIC(12,2) NOT NULL CHECK (amount >= 0),
+				created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_maintenance_cost_items_record_id ON maintenance_cost_items(maintenance_record_id);
+
+			INSERT INTO maintenance_cost_items (maintenance_record_id, category, amount)
+			SELECT m.id, 'other', m.cost FROM maintenance_records m
+			WHERE m.cost > 0
+				AND NOT EXISTS (SELECT 1 FROM maintenance_cost_items i WHERE i.maintenance_record_id = m.id);
+
+			CREATE TABLE IF NOT EXISTS property_budgets (
+				id SERIAL PRIMARY KEY,
+				property_id INTEGER NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+				year INTEGER NOT NULL CHECK (year BETWEEN 2000 AND 2100),
+				category VARCHAR(20) NOT NULL ` + CostCategoryCheck("category") + `,
+				amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+				currency CHAR(3) NOT NULL DEFAULT 'USD',
+				thresholds INTEGER[] NOT NULL DEFAULT '{80,100}',
+				created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+				updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+				UNIQUE (property_id, year, category)
+			);
+
+			CREATE TABLE IF NOT EXISTS budget_alerts (
+				budget_id INTEGER NOT NULL REFERENCES property_budgets(id) ON DELETE CASCADE,
+				threshold INTEGER NOT NULL,
+				notification_id INTEGER REFERENCES notifications(id) ON DELETE SET NULL,
+				created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+				PRIMARY KEY (budget_id, threshold)
+			);
+		`,
+		Down: `
+			DROP TABLE IF EXISTS budget_alerts;
+			DROP TABLE IF EXISTS property_budgets;
+			DROP TABLE IF EXISTS maintenance_cost_items;
+			ALTER TABLE maintenance_records DROP COLUMN IF EXISTS currency;
+			ALTER TABLE maintenance_records ALTER COLUMN cost TYPE DECIMAL(10,2);
+		`,
+	},
 }
 
 // RunMigrations applies all pending migrations to the database
 func RunMigrations(db *sql.DB) error {
+	return RunMigrationsContext(context.Background(), db)
+}
+
+// RunMigrationsContext applies all pending migrations, tracing the run as a
+// span with one child span per applied migration
+func RunMigrationsContext(ctx context.Context, db *sql.DB) (err error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "db.migrate")
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
 	log.Println("Running database migrations...")
 
 	// Ensure migrations table exists first
-	if err := createMigrationsTable(db); err != nil {
+	if err := createMigrationsTable(ctx, db); err != nil {
 		return fmt.Errorf("failed to create migrations table: %w", err)
 	}
 
 	// Get already applied migrations
-	appliedMigrations, err := getAppliedMigrations(db)
+	appliedMigrations, err := getAppliedMigrations(ctx, db)
 	if err != nil {
 		return fmt.Errorf("failed to get applied migrations: %w", err)
 	}
@@ -266,7 +555,7 @@ func RunMigrations(db *sql.DB) error {
 		}
 
 		log.Printf("Applying migration %s...", migration.Version)
-		if err := applyMigration(db, migration); err != nil {
+		if err := applyMigration(ctx, db, migration); err != nil {
 			return fmt.Errorf("failed to apply migration %s: %w", migration.Version, err)
 		}
 		log.Printf("Migration %s applied successfully", migration.Version)
@@ -276,21 +565,39 @@ func RunMigrations(db *sql.DB) error {
 	return nil
 }
 
+// MigrationStatus returns applied and pending migration versions in order.
+// Applied versions not present in this build are ignored.
+func MigrationStatus(ctx context.Context, db *sql.DB) (applied, pending []string, err error) {
+	appliedMigrations, err := getAppliedMigrations(ctx, db)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, migration := range migrations {
+		if appliedMigrations[migration.Version] {
+			applied = append(applied, migration.Version)
+		} else {
+			pending = append(pending, migration.Version)
+		}
+	}
+	return applied, pending, nil
+}
+
 // createMigrationsTable creates the schema_migrations table if it doesn't exist
-func createMigrationsTable(db *sql.DB) error {
+func createMigrationsTable(ctx context.Context, db *sql.DB) error {
 	query := `
 		CREATE TABLE IF NOT EXISTS schema_migrations (
 			version VARCHAR(255) PRIMARY KEY,
 			applied_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 		);
 	`
-	_, err := db.Exec(query)
+	_, err := db.ExecContext(ctx, query)
 	return err
 }
 
 // getAppliedMigrations returns a set of already applied migration versions
-func getAppliedMigrations(db *sql.DB) (map[string]bool, error) {
-	rows, err := db.Query("SELECT version FROM schema_migrations")
+func getAppliedMigrations(ctx context.Context, db *sql.DB) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, "SELECT version FROM schema_migrations")
 	if err != nil {
 		return nil, err
 	}
@@ -309,20 +616,30 @@ func getAppliedMigrations(db *sql.DB) (map[string]bool, error) {
 }
 
 // applyMigration applies a single migration within a transaction
-func applyMigration(db *sql.DB, migration Migration) error {
-	tx, err := db.Begin()
+func applyMigration(ctx context.Context, db *sql.DB, migration Migration) (err error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "db.migrate "+migration.Version)
+	span.SetAttributes(attribute.String("db.migration.version", migration.Version))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
 	// Execute the migration
-	if _, err := tx.Exec(migration.Up); err != nil {
+	if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
 		return err
 	}
 
 	// Record the migration as applied
-	if _, err := tx.Exec("INSERT INTO schema_migrations (version) VALUES ($1)", migration.Version); err != nil {
+	if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES ($1)", migration.Version); err != nil {
 		return err
 	}
 
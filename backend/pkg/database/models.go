@@ -39,7 +39,7 @@ type Property struct {
 	UserID              int                   `json:"-" db:"user_id"`
 	Name                string                `json:"name" db:"name"`
 	Address             string                `json:"address" db:"address"`
-	Type                string                `json:"type" db:"type"`
+	Type                PropertyType          `json:"type" db:"type"`
 	YearBuilt           *int                  `json:"yearBuilt,omitempty" db:"year_built"`
 	SquareFootage       *int                  `json:"squareFootage,omitempty" db:"square_footage"`
 	Notes               *string               `json:"notes,omitempty" db:"notes"`
@@ -51,34 +51,34 @@ type Property struct {
 
 // Room represents a room within a property
 type Room struct {
-	ID          int     `json:"id" db:"id"`
-	PropertyID  int     `json:"-" db:"property_id"`
-	Name        string  `json:"name" db:"name"`
-	Type        string  `json:"type" db:"type"`
-	FloorArea   *int    `json:"floorArea,omitempty" db:"floor_area"`
-	Description *string `json:"description,omitempty" db:"description"`
+	ID          int       `json:"id" db:"id"`
+	PropertyID  int       `json:"-" db:"property_id"`
+	Name        string    `json:"name" db:"name"`
+	Type        RoomType  `json:"type" db:"type"`
+	FloorArea   *int      `json:"floorArea,omitempty" db:"floor_area"`
+	Description *string   `json:"description,omitempty" db:"description"`
 	CreatedAt   time.Time `json:"-" db:"created_at"`
 	UpdatedAt   time.Time `json:"-" db:"updated_at"`
 }
 
 // Task represents a maintenance task
 type Task struct {
-	ID             int        `json:"id" db:"id"`
-	UserID         int        `json:"-" db:"user_id"`
-	PropertyID     int        `json:"-" db:"property_id"`
-	Property       string     `json:"property" db:"-"` // Property name for display
-	Title          string     `json:"title" db:"title"`
-	Description    *string    `json:"description,omitempty" db:"description"`
-	Priority       string     `json:"priority" db:"priority"`
-	Status         string     `json:"status" db:"status"`
-	Category       string     `json:"category" db:"category"`
-	DueDate        *time.Time `json:"dueDate,omitempty" db:"due_date"`
-	EstimatedTime  *int       `json:"estimatedTime,omitempty" db:"estimated_time"`
-	Assignee       *string    `json:"assignee,omitempty" db:"assignee"`
-	Notes          *string    `json:"notes,omitempty" db:"notes"`
-	CreatedAt      time.Time  `json:"createdAt" db:"created_at"`
-	UpdatedAt      time.Time  `json:"-" db:"updated_at"`
-	CompletedAt    *time.Time `json:"completedAt,omitempty" db:"completed_at"`
+	ID             int          `json:"id" db:"id"`
+	UserID         int          `json:"-" db:"user_id"`
+	PropertyID     int          `json:"-" db:"property_id"`
+	Property       string       `json:"property" db:"-"` // Property name for display
+	Title          string       `json:"title" db:"title"`
+	Description    *string      `json:"description,omitempty" db:"description"`
+	Priority       TaskPriority `json:"priority" db:"priority"`
+	Status         TaskStatus   `json:"status" db:"status"`
+	Category       string       `json:"category" db:"category"`
+	DueDate        *time.Time   `json:"dueDate,omitempty" db:"due_date"`
+	EstimatedTime  *int         `json:"estimatedTime,omitempty" db:"estimated_time"`
+	Assignee       *string      `json:"assignee,omitempty" db:"assignee"`
+	Notes          *string      `json:"notes,omitempty" db:"notes"`
+	CreatedAt      time.Time    `json:"createdAt" db:"created_at"`
+	UpdatedAt      time.Time    `json:"-" db:"updated_at"`
+	CompletedAt    *time.Time   `json:"completedAt,omitempty" db:"completed_at"`
 }
 
 // MaintenanceRecord represents a completed maintenance record
@@ -89,28 +89,60 @@ type MaintenanceRecord struct {
 	Title         string    `json:"title" db:"title"`
 	Description   string    `json:"description" db:"description"`
 	CompletedDate time.Time `json:"completedDate" db:"completed_date"`
-	Cost          *float64  `json:"cost,omitempty" db:"cost"`
-	Contractor    *string   `json:"contractor,omitempty" db:"contractor"`
-	Notes         *string   `json:"notes,omitempty" db:"notes"`
-	CreatedAt     time.Time `json:"-" db:"created_at"`
-	UpdatedAt     time.Time `json:"-" db:"updated_at"`
+	// Cost is the total of LineItems, in Currency
+	Cost       *Amount        `json:"cost,omitempty" db:"cost"`
+	Currency   string         `json:"currency" db:"currency"`
+	LineItems  []CostLineItem `json:"lineItems" db:"-"`
+	Contractor *string        `json:"contractor,omitempty" db:"contractor"`
+	Notes      *string        `json:"notes,omitempty" db:"notes"`
+	CreatedAt  time.Time      `json:"-" db:"created_at"`
+	UpdatedAt  time.Time      `json:"-" db:"updated_at"`
+}
+
+// CostLineItem is one part of a maintenance record's cost
+type CostLineItem struct {
+	ID                  int          `json:"id" db:"id"`
+	MaintenanceRecordID int          `json:"-" db:"maintenance_record_id"`
+	Category            CostCategory `json:"category" db:"category"`
+	Description         *string      `json:"description,omitempty" db:"description"`
+	Amount              Amount       `json:"amount" db:"amount"`
+}
+
+// Budget is a property's spending limit for one cost category in a
+// calendar year
+type Budget struct {
+	ID         int          `json:"id" db:"id"`
+	PropertyID int          `json:"-" db:"property_id"`
+	Year       int          `json:"year" db:"year"`
+	Category   CostCategory `json:"category" db:"category"`
+	Amount     Amount       `json:"amount" db:"amount"`
+	Currency   string       `json:"currency" db:"currency"`
+	// Thresholds are the percentages of Amount at which an alert is sent
+	Thresholds []int64 `json:"thresholds" db:"thresholds"`
+	// Spent totals the line items in the budget's category and currency
+	// recorded during Year in the owner's time zone
+	Spent       Amount    `json:"spent" db:"-"`
+	Remaining   Amount    `json:"remaining" db:"-"`
+	PercentUsed int       `json:"percentUsed" db:"-"`
+	CreatedAt   time.Time `json:"-" db:"created_at"`
+	UpdatedAt   time.Time `json:"-" db:"updated_at"`
 }
 
 // Notification represents a system notification
 type Notification struct {
-	ID           int        `json:"id" db:"id"`
-	UserID       int        `json:"-" db:"user_id"`
-	Title        string     `json:"title" db:"title"`
-	Message      string     `json:"message" db:"message"`
-	Type         string     `json:"type" db:"type"`
-	Priority     string     `json:"priority" db:"priority"`
-	Read         bool       `json:"read" db:"read"`
-	TaskID       *int       `json:"taskId,omitempty" db:"task_id"`
-	PropertyID   *int       `json:"propertyId,omitempty" db:"property_id"`
-	ActionURL    *string    `json:"actionUrl,omitempty" db:"action_url"`
-	ScheduledFor *time.Time `json:"scheduledFor,omitempty" db:"scheduled_for"`
-	CreatedAt    time.Time  `json:"createdAt" db:"created_at"`
-	UpdatedAt    time.Time  `json:"-" db:"updated_at"`
+	ID           int                  `json:"id" db:"id"`
+	UserID       int                  `json:"-" db:"user_id"`
+	Title        string               `json:"title" db:"title"`
+	Message      string               `json:"message" db:"message"`
+	Type         NotificationType     `json:"type" db:"type"`
+	Priority     NotificationPriority `json:"priority" db:"priority"`
+	Read         bool                 `json:"read" db:"read"`
+	TaskID       *int                 `json:"taskId,omitempty" db:"task_id"`
+	PropertyID   *int                 `json:"propertyId,omitempty" db:"property_id"`
+	ActionURL    *string              `json:"actionUrl,omitempty" db:"action_url"`
+	ScheduledFor *time.Time           `json:"scheduledFor,omitempty" db:"scheduled_for"`
+	CreatedAt    time.Time            `json:"createdAt" db:"created_at"`
+	UpdatedAt    time.Time            `json:"-" db:"updated_at"`
 }
 
 // NotificationSettings represents user notification preferences
@@ -148,16 +180,91 @@ type RefreshToken struct {
 
 // File represents an uploaded file
 type File struct {
-	ID               int       `json:"-" db:"id"`
-	UserID           int       `json:"-" db:"user_id"`
-	Filename         string    `json:"filename" db:"filename"`
-	OriginalFilename string    `json:"-" db:"original_filename"`
-	MimeType         string    `json:"mimeType" db:"mime_type"`
-	SizeBytes        int64     `json:"size" db:"size_bytes"`
-	Category         string    `json:"-" db:"category"`
-	FilePath         string    `json:"-" db:"file_path"`
-	URL              string    `json:"url" db:"url"`
-	CreatedAt        time.Time `json:"-" db:"created_at"`
+	ID               int          `json:"-" db:"id"`
+	UserID           int          `json:"-" db:"user_id"`
+	Filename         string       `json:"filename" db:"filename"`
+	OriginalFilename string       `json:"-" db:"original_filename"`
+	MimeType         string       `json:"mimeType" db:"mime_type"`
+	SizeBytes        int64        `json:"size" db:"size_bytes"`
+	Category         FileCategory `json:"-" db:"category"`
+	FilePath         string       `json:"-" db:"file_path"`
+	ContentHash      string       `json:"contentHash" db:"content_hash"`
+	Width            *int         `json:"width,omitempty" db:"width"`
+	Height           *int         `json:"height,omitempty" db:"height"`
+	// Variants are resized copies of an image, smallest first
+	Variants FileVariants `json:"variants" db:"variants"`
+	// Status is pending until the malware scan passes; quarantined files
+	// are never served and ScanThreat names what was found
+	Status     FileStatus `json:"status" db:"status"`
+	ScanThreat *string    `json:"scanThreat,omitempty" db:"scan_threat"`
+	ScannedAt  *time.Time `json:"scannedAt,omitempty" db:"scanned_at"`
+	// URL is a signed download link valid until URLExpiresAt, generated for
+	// each response rather than stored. It is empty unless Status is
+	// available.
+	URL          string     `json:"url,omitempty" db:"-"`
+	URLExpiresAt *time.Time `json:"urlExpiresAt,omitempty" db:"-"`
+	CreatedAt    time.Time  `json:"-" db:"created_at"`
+}
+
+// FileVariant is a resized copy of an uploaded image
+type FileVariant struct {
+	Name      string `json:"name"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	MimeType  string `json:"mimeType"`
+	SizeBytes int64  `json:"size"`
+	// URL is a signed download link, generated for each response
+	URL string `json:"url,omitempty"`
+}
+
+// FileVariants is stored as a JSONB array
+type FileVariants []FileVariant
+
+// Attachment links a file to a task, property, room or maintenance record
+type Attachment struct {
+	ID        int       `json:"id" db:"id"`
+	FileID    int       `json:"-" db:"file_id"`
+	Position  int       `json:"position" db:"position"`
+	Caption   *string   `json:"caption,omitempty" db:"caption"`
+	File      File      `json:"file" db:"-"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+// Webhook represents an outgoing webhook endpoint registered by a user
+type Webhook struct {
+	ID                  int               `json:"id" db:"id"`
+	UserID              int               `json:"-" db:"user_id"`
+	URL                 string            `json:"url" db:"url"`
+	Description         *string           `json:"description,omitempty" db:"description"`
+	Secret              string            `json:"-" db:"secret"`
+	EventTypes          WebhookEventTypes `json:"eventTypes" db:"event_types"`
+	Active              bool              `json:"active" db:"active"`
+	ConsecutiveFailures int               `json:"consecutiveFailures" db:"consecutive_failures"`
+	DisabledAt          *time.Time        `json:"disabledAt,omitempty" db:"disabled_at"`
+	CreatedAt           time.Time         `json:"createdAt" db:"created_at"`
+	UpdatedAt           time.Time         `json:"-" db:"updated_at"`
+}
+
+// WebhookEventTypes is the list of event types a webhook subscribes to.
+// An empty list subscribes to every event.
+type WebhookEventTypes []string
+
+// WebhookDelivery represents a single event delivery to a webhook endpoint
+type WebhookDelivery struct {
+	ID             int64                 `json:"id" db:"id"`
+	WebhookID      int                   `json:"webhookId" db:"webhook_id"`
+	EventPosition  int64                 `json:"eventId" db:"event_position"`
+	EventType      string                `json:"eventType" db:"event_type"`
+	Payload        json.RawMessage       `json:"payload" db:"payload"`
+	Status         WebhookDeliveryStatus `json:"status" db:"status"`
+	Attempts       int                   `json:"attempts" db:"attempts"`
+	ResponseStatus *int                  `json:"responseStatus,omitempty" db:"response_status"`
+	ResponseBody   *string               `json:"responseBody,omitempty" db:"response_body"`
+	LastError      *string               `json:"lastError,omitempty" db:"last_error"`
+	NextAttemptAt  *time.Time            `json:"nextAttemptAt,omitempty" db:"next_attempt_at"`
+	DeliveredAt    *time.Time            `json:"deliveredAt,omitempty" db:"delivered_at"`
+	CreatedAt      time.Time             `json:"createdAt" db:"created_at"`
+	UpdatedAt      time.Time             `json:"-" db:"updated_at"`
 }
 
 // API Response and Request types
@@ -206,50 +313,219 @@ type LoginResponse struct {
 
 // CreateTaskRequest represents a task creation request
 type CreateTaskRequest struct {
-	Title         string     `json:"title" binding:"required,min=1,max=255"`
-	Description   *string    `json:"description,omitempty"`
-	PropertyID    int        `json:"propertyId" binding:"required"`
-	Priority      string     `json:"priority" binding:"required,oneof=low medium high"`
-	DueDate       *time.Time `json:"dueDate,omitempty"`
-	Category      string     `json:"category" binding:"required,min=1,max=100"`
-	EstimatedTime *int       `json:"estimatedTime,omitempty"`
-	Assignee      *string    `json:"assignee,omitempty"`
-	Notes         *string    `json:"notes,omitempty"`
+	Title         string       `json:"title" binding:"required,min=1,max=255"`
+	Description   *string      `json:"description,omitempty"`
+	PropertyID    int          `json:"propertyId" binding:"required"`
+	Priority      TaskPriority `json:"priority" binding:"required,task_priority"`
+	DueDate       *time.Time   `json:"dueDate,omitempty"`
+	Category      string       `json:"category" binding:"required,min=1,max=100"`
+	EstimatedTime *int         `json:"estimatedTime,omitempty"`
+	Assignee      *string      `json:"assignee,omitempty"`
+	Notes         *string      `json:"notes,omitempty"`
 }
 
 // UpdateTaskRequest represents a task update request
 type UpdateTaskRequest struct {
-	Title         *string    `json:"title,omitempty"`
-	Description   *string    `json:"description,omitempty"`
-	PropertyID    *int       `json:"propertyId,omitempty"`
-	Priority      *string    `json:"priority,omitempty"`
-	Status        *string    `json:"status,omitempty"`
-	DueDate       *time.Time `json:"dueDate,omitempty"`
-	Category      *string    `json:"category,omitempty"`
-	EstimatedTime *int       `json:"estimatedTime,omitempty"`
-	Assignee      *string    `json:"assignee,omitempty"`
-	Notes         *string    `json:"notes,omitempty"`
-	CompletedAt   *time.Time `json:"completedAt,omitempty"`
+	Title         *string       `json:"title,omitempty"`
+	Description   *string       `json:"description,omitempty"`
+	PropertyID    *int          `json:"propertyId,omitempty"`
+	Priority      *TaskPriority `json:"priority,omitempty" binding:"omitempty,task_priority"`
+	Status        *TaskStatus   `json:"status,omitempty" binding:"omitempty,task_status"`
+	DueDate       *time.Time    `json:"dueDate,omitempty"`
+	Category      *string       `json:"category,omitempty"`
+	EstimatedTime *int          `json:"estimatedTime,omitempty"`
+	Assignee      *string       `json:"assignee,omitempty"`
+	Notes         *string       `json:"notes,omitempty"`
+	CompletedAt   *time.Time    `json:"completedAt,omitempty"`
+}
+
+// CompleteTaskRequest marks a task completed, optionally recording the work
+// in the property's maintenance history
+type CompleteTaskRequest struct {
+	CompletedAt       *time.Time               `json:"completedAt,omitempty"`
+	MaintenanceRecord *CompleteTaskMaintenance `json:"maintenanceRecord,omitempty"`
+}
+
+// CompleteTaskMaintenance describes the maintenance record created when a
+// task is completed. Title and description default to the task's.
+type CompleteTaskMaintenance struct {
+	Title       *string `json:"title,omitempty" binding:"omitempty,min=1,max=255"`
+	Description *string `json:"description,omitempty"`
+	// Cost may be given alone or with line items, which it must then total
+	Cost       *Amount               `json:"cost,omitempty" binding:"omitempty,gte=0,cost_total,currency_units"`
+	Currency   string                `json:"currency,omitempty" binding:"omitempty,currency"`
+	LineItems  []CostLineItemRequest `json:"lineItems,omitempty" binding:"omitempty,max=50,currency_units,dive"`
+	Contractor *string               `json:"contractor,omitempty" binding:"omitempty,max=255"`
+	Notes      *string               `json:"notes,omitempty"`
+}
+
+// CompleteTaskResponse is the completed task and the maintenance record
+// created with it, if any
+type CompleteTaskResponse struct {
+	Task              *Task              `json:"task"`
+	MaintenanceRecord *MaintenanceRecord `json:"maintenanceRecord,omitempty"`
 }
 
 // CreatePropertyRequest represents a property creation request
 type CreatePropertyRequest struct {
-	Name          string  `json:"name" binding:"required,min=1,max=255"`
-	Address       string  `json:"address" binding:"required,min=1"`
-	Type          string  `json:"type" binding:"required,oneof=house apartment condo townhouse other"`
-	YearBuilt     *int    `json:"yearBuilt,omitempty"`
-	SquareFootage *int    `json:"squareFootage,omitempty"`
-	Notes         *string `json:"notes,omitempty"`
+	Name          string       `json:"name" binding:"required,min=1,max=255"`
+	Address       string       `json:"address" binding:"required,min=1"`
+	Type          PropertyType `json:"type" binding:"required,property_type"`
+	YearBuilt     *int         `json:"yearBuilt,omitempty"`
+	SquareFootage *int         `json:"squareFootage,omitempty"`
+	Notes         *string      `json:"notes,omitempty"`
 }
 
 // UpdatePropertyRequest represents a property update request
 type UpdatePropertyRequest struct {
-	Name          *string `json:"name,omitempty"`
-	Address       *string `json:"address,omitempty"`
-	Type          *string `json:"type,omitempty"`
-	YearBuilt     *int    `json:"yearBuilt,omitempty"`
-	SquareFootage *int    `json:"squareFootage,omitempty"`
-	Notes         *string `json:"notes,omitempty"`
+	Name          *string       `json:"name,omitempty"`
+	Address       *string       `json:"address,omitempty"`
+	Type          *PropertyType `json:"type,omitempty" binding:"omitempty,property_type"`
+	YearBuilt     *int          `json:"yearBuilt,omitempty"`
+	SquareFootage *int          `json:"squareFootage,omitempty"`
+	Notes         *string       `json:"notes,omitempty"`
+}
+
+// PropertyDependents counts the records deleted along with a property
+type PropertyDependents struct {
+	Rooms              int `json:"rooms"`
+	Tasks              int `json:"tasks"`
+	MaintenanceRecords int `json:"maintenanceRecords"`
+}
+
+// DeletePropertyFilters confirms deleting a property that has dependents
+type DeletePropertyFilters struct {
+	Confirm bool `form:"confirm"`
+}
+
+// CreateRoomRequest represents a room creation request
+type CreateRoomRequest struct {
+	Name        string   `json:"name" binding:"required,min=1,max=255"`
+	Type        RoomType `json:"type" binding:"required,room_type"`
+	FloorArea   *int     `json:"floorArea,omitempty" binding:"omitempty,gt=0"`
+	Description *string  `json:"description,omitempty"`
+}
+
+// UpdateRoomRequest represents a room update request
+type UpdateRoomRequest struct {
+	Name        *string   `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	Type        *RoomType `json:"type,omitempty" binding:"omitempty,room_type"`
+	FloorArea   *int      `json:"floorArea,omitempty" binding:"omitempty,gt=0"`
+	Description *string   `json:"description,omitempty"`
+}
+
+// CreateMaintenanceRecordRequest records maintenance done on a property
+type CreateMaintenanceRecordRequest struct {
+	TaskID        *int      `json:"taskId,omitempty"`
+	Title         string    `json:"title" binding:"required,min=1,max=255"`
+	Description   string    `json:"description" binding:"required,min=1"`
+	CompletedDate time.Time `json:"completedDate" binding:"required"`
+	// Cost may be given alone or with line items, which it must then total
+	Cost       *Amount               `json:"cost,omitempty" binding:"omitempty,gte=0,cost_total,currency_units"`
+	Currency   string                `json:"currency,omitempty" binding:"omitempty,currency"`
+	LineItems  []CostLineItemRequest `json:"lineItems,omitempty" binding:"omitempty,max=50,currency_units,dive"`
+	Contractor *string               `json:"contractor,omitempty" binding:"omitempty,max=255"`
+	Notes      *string               `json:"notes,omitempty"`
+}
+
+// CostLineItemRequest is one part of the cost of a maintenance record
+type CostLineItemRequest struct {
+	Category    CostCategory `json:"category" binding:"required,cost_category"`
+	Description *string      `json:"description,omitempty" binding:"omitempty,max=255"`
+	Amount      Amount       `json:"amount" binding:"gt=0"`
+}
+
+// CreateBudgetRequest sets a property's budget for a category and year.
+// Thresholds default to 80 and 100 percent.
+type CreateBudgetRequest struct {
+	Year       int          `json:"year" binding:"required,gte=2000,lte=2100"`
+	Category   CostCategory `json:"category" binding:"required,cost_category"`
+	Amount     Amount       `json:"amount" binding:"gt=0,currency_units"`
+	Currency   string       `json:"currency,omitempty" binding:"omitempty,currency"`
+	Thresholds []int64      `json:"thresholds,omitempty" binding:"omitempty,max=10,dive,gt=0,lte=1000"`
+}
+
+// UpdateBudgetRequest changes a budget's amount, currency or thresholds
+type UpdateBudgetRequest struct {
+	Amount     *Amount `json:"amount,omitempty" binding:"omitempty,gt=0,currency_units"`
+	Currency   *string `json:"currency,omitempty" binding:"omitempty,currency"`
+	Thresholds []int64 `json:"thresholds,omitempty" binding:"omitempty,max=10,dive,gt=0,lte=1000"`
+}
+
+// BudgetFilters narrows a property's budgets to one year
+type BudgetFilters struct {
+	Year *int `form:"year" binding:"omitempty,gte=2000,lte=2100"`
+}
+
+// AttachFileRequest attaches an uploaded file, appending it to the end of
+// the parent's attachments
+type AttachFileRequest struct {
+	Filename string  `json:"filename" binding:"required,max=255"`
+	Caption  *string `json:"caption,omitempty" binding:"omitempty,max=500"`
+}
+
+// UpdateAttachmentRequest changes an attachment's caption. An empty caption
+// removes it.
+type UpdateAttachmentRequest struct {
+	Caption *string `json:"caption" binding:"omitempty,max=500"`
+}
+
+// FileDownloadFilters selects the size of an image to download
+type FileDownloadFilters struct {
+	Size string `form:"size" binding:"omitempty,oneof=original thumbnail medium"`
+}
+
+// ReorderAttachmentsRequest lists every attachment of a parent in its new order
+type ReorderAttachmentsRequest struct {
+	AttachmentIDs []int `json:"attachmentIds" binding:"required,min=1,dive,gt=0"`
+}
+
+// UpdateNotificationSettingsRequest represents a notification settings update request
+type UpdateNotificationSettingsRequest struct {
+	EmailNotifications  *bool                    `json:"emailNotifications,omitempty"`
+	PushNotifications   *bool                    `json:"pushNotifications,omitempty"`
+	SMSNotifications    *bool                    `json:"smsNotifications,omitempty"`
+	ReminderAdvance     *int                     `json:"reminderAdvance,omitempty" binding:"omitempty,min=0,max=720"`
+	QuietHours          *UpdateQuietHoursRequest `json:"quietHours,omitempty"`
+	TaskReminders       *bool                    `json:"taskReminders,omitempty"`
+	MaintenanceAlerts   *bool                    `json:"maintenanceAlerts,omitempty"`
+	SystemNotifications *bool                    `json:"systemNotifications,omitempty"`
+}
+
+// UpdateQuietHoursRequest represents a quiet hours update. Times are HH:MM.
+type UpdateQuietHoursRequest struct {
+	Enabled *bool   `json:"enabled,omitempty"`
+	Start   *string `json:"start,omitempty" binding:"omitempty,datetime=15:04"`
+	End     *string `json:"end,omitempty" binding:"omitempty,datetime=15:04"`
+}
+
+// TestNotificationRequest asks for a test notification of Type, system by default
+type TestNotificationRequest struct {
+	Type NotificationType `json:"type" binding:"omitempty,notification_type"`
+}
+
+// NotificationDelivery reports the outcome of sending a notification on one channel
+type NotificationDelivery struct {
+	Channel string  `json:"channel"`
+	Status  string  `json:"status"` // sent, failed or unavailable
+	Error   *string `json:"error,omitempty"`
+}
+
+// TestNotificationResponse is the test notification and its delivery on each enabled channel
+type TestNotificationResponse struct {
+	Notification *Notification          `json:"notification"`
+	Deliveries   []NotificationDelivery `json:"deliveries"`
+}
+
+// NotificationList is a page of notifications with the user's total unread count
+type NotificationList struct {
+	PaginatedResponse[Notification]
+	UnreadCount int `json:"unreadCount"`
+}
+
+// MarkAllReadResponse reports how many notifications were marked read
+type MarkAllReadResponse struct {
+	Updated int `json:"updated"`
 }
 
 // ChangePasswordRequest represents a password change request
@@ -269,34 +545,172 @@ type ResetPasswordRequest struct {
 	NewPassword string `json:"newPassword" binding:"required,min=8"`
 }
 
+// CreateWebhookRequest represents a webhook registration request
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required,url,max=2000"`
+	Description *string  `json:"description,omitempty" binding:"omitempty,max=255"`
+	EventTypes  []string `json:"eventTypes,omitempty"`
+}
+
+// UpdateWebhookRequest represents a webhook update request
+type UpdateWebhookRequest struct {
+	URL         *string   `json:"url,omitempty" binding:"omitempty,url,max=2000"`
+	Description *string   `json:"description,omitempty" binding:"omitempty,max=255"`
+	EventTypes  *[]string `json:"eventTypes,omitempty"`
+	Active      *bool     `json:"active,omitempty"`
+}
+
+// CreateWebhookResponse represents a newly created webhook, including the
+// signing secret which is only ever returned once
+type CreateWebhookResponse struct {
+	Webhook Webhook `json:"webhook"`
+	Secret  string  `json:"secret"`
+}
+
+// WebhookDeliveryFilters represents filters for webhook delivery queries
+type WebhookDeliveryFilters struct {
+	Status *WebhookDeliveryStatus `form:"status"`
+	Page   int                    `form:"page"`
+	Limit  int                    `form:"limit"`
+}
+
 // TaskFilters represents filters for task queries
 type TaskFilters struct {
-	Status     *string    `form:"status"`
-	Priority   *string    `form:"priority"`
-	PropertyID *int       `form:"propertyId"`
-	Assignee   *string    `form:"assignee"`
-	DueAfter   *time.Time `form:"dueAfter"`
-	DueBefore  *time.Time `form:"dueBefore"`
-	Search     *string    `form:"search"`
-	Page       int        `form:"page"`
-	Limit      int        `form:"limit"`
+	Status     *TaskStatus   `form:"status" binding:"omitempty,task_status"`
+	Priority   *TaskPriority `form:"priority" binding:"omitempty,task_priority"`
+	PropertyID *int          `form:"propertyId"`
+	Assignee   *string       `form:"assignee"`
+	DueAfter   *time.Time    `form:"dueAfter"`
+	DueBefore  *time.Time    `form:"dueBefore"`
+	Search     *string       `form:"search"`
+	Page       int           `form:"page"`
+	Limit      int           `form:"limit"`
+}
+
+// UpcomingTaskFilters selects open tasks due within the next Days days
+type UpcomingTaskFilters struct {
+	Days int `form:"days" binding:"omitempty,min=1,max=365"`
 }
 
 // PropertyFilters represents filters for property queries
 type PropertyFilters struct {
-	Type   *string `form:"type"`
-	Search *string `form:"search"`
-	Page   int     `form:"page"`
-	Limit  int     `form:"limit"`
+	Type   *PropertyType `form:"type" binding:"omitempty,property_type"`
+	Search *string       `form:"search"`
+	Page   int           `form:"page"`
+	Limit  int           `form:"limit"`
 }
 
 // NotificationFilters represents filters for notification queries
 type NotificationFilters struct {
-	Read     *bool   `form:"read"`
-	Type     *string `form:"type"`
-	Priority *string `form:"priority"`
-	Page     int     `form:"page"`
-	Limit    int     `form:"limit"`
+	Read     *bool                 `form:"read"`
+	Type     *NotificationType     `form:"type" binding:"omitempty,notification_type"`
+	Priority *NotificationPriority `form:"priority" binding:"omitempty,notification_priority"`
+	Page     int                   `form:"page"`
+	Limit    int                   `form:"limit"`
+}
+
+// AnalyticsFilters selects the time window for analytics
+type AnalyticsFilters struct {
+	Period string `form:"period" binding:"omitempty,oneof=week month quarter year"`
+}
+
+// DashboardStats summarizes a user's tasks and properties
+type DashboardStats struct {
+	TotalTasks      int            `json:"totalTasks"`
+	CompletedTasks  int            `json:"completedTasks"`
+	PendingTasks    int            `json:"pendingTasks"`
+	OverdueTasks    int            `json:"overdueTasks"`
+	TotalProperties int            `json:"totalProperties"`
+	CompletionRate  float64        `json:"completionRate"`
+	RecentActivity  []ActivityItem `json:"recentActivity"`
+}
+
+// ActivityItem is a recent change to a task, property or maintenance record
+type ActivityItem struct {
+	ID        int       `json:"id"`
+	Type      string    `json:"type"` // task, property or maintenance
+	Title     string    `json:"title"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// TaskAnalytics aggregates a user's tasks over a period, bucketed in their time zone
+type TaskAnalytics struct {
+	Period   string `json:"period"`
+	Timezone string `json:"timezone"`
+	// Counts over all tasks, regardless of period
+	TasksByStatus   map[string]int `json:"tasksByStatus"`
+	TasksByPriority map[string]int `json:"tasksByPriority"`
+	TasksByCategory map[string]int `json:"tasksByCategory"`
+	// Share of tasks created in the period that are completed
+	CompletionRate float64 `json:"completionRate"`
+	// Mean hours from creation to completion of tasks completed in the period
+	MeanHoursToComplete *float64         `json:"meanHoursToComplete"`
+	CompletionTrend     []TaskTrendPoint `json:"completionTrend"`
+	OverdueTrend        []OverduePoint   `json:"overdueTrend"`
+}
+
+// TaskTrendPoint counts tasks created and completed in one bucket
+type TaskTrendPoint struct {
+	Date                string   `json:"date"`
+	Created             int      `json:"created"`
+	Completed           int      `json:"completed"`
+	MeanHoursToComplete *float64 `json:"meanHoursToComplete"`
+}
+
+// OverduePoint counts tasks that were overdue at the end of one bucket
+type OverduePoint struct {
+	Date    string `json:"date"`
+	Overdue int    `json:"overdue"`
+}
+
+// PropertyAnalytics aggregates maintenance spend and tasks per property
+type PropertyAnalytics struct {
+	Timezone         string                     `json:"timezone"`
+	PropertiesByType map[string]int             `json:"propertiesByType"`
+	MaintenanceCosts []PropertyMaintenanceCost  `json:"maintenanceCosts"`
+	MonthlySpend     []MonthlySpend             `json:"monthlySpend"`
+	TaskDistribution []PropertyTaskDistribution `json:"taskDistribution"`
+	TopContractors   []ContractorStats          `json:"topContractors"`
+}
+
+// PropertyMaintenanceCost totals the recorded maintenance cost of a property
+// in one currency, over the records that have a cost. A property without any
+// has a single zero entry in DefaultCurrency.
+type PropertyMaintenanceCost struct {
+	PropertyID   int    `json:"propertyId"`
+	PropertyName string `json:"propertyName"`
+	Currency     string `json:"currency"`
+	TotalCost    Amount `json:"totalCost"`
+	AverageCost  Amount `json:"averageCost"`
+	RecordCount  int    `json:"recordCount"`
+}
+
+// MonthlySpend is a property's maintenance spend in one month and currency
+type MonthlySpend struct {
+	PropertyID   int    `json:"propertyId"`
+	PropertyName string `json:"propertyName"`
+	Month        string `json:"month"` // YYYY-MM
+	Currency     string `json:"currency"`
+	TotalCost    Amount `json:"totalCost"`
+}
+
+// PropertyTaskDistribution counts a property's tasks
+type PropertyTaskDistribution struct {
+	PropertyID     int    `json:"propertyId"`
+	PropertyName   string `json:"propertyName"`
+	TaskCount      int    `json:"taskCount"`
+	CompletedTasks int    `json:"completedTasks"`
+	OverdueTasks   int    `json:"overdueTasks"`
+}
+
+// ContractorStats summarizes the maintenance work done by one contractor
+// and recorded in one currency
+type ContractorStats struct {
+	Contractor string    `json:"contractor"`
+	Currency   string    `json:"currency"`
+	Jobs       int       `json:"jobs"`
+	TotalCost  Amount    `json:"totalCost"`
+	LastJobAt  time.Time `json:"lastJobAt"`
 }
 
 // Custom JSON marshaling for time fields to match frontend expectations
@@ -349,64 +763,95 @@ func (p *UserPreferences) Scan(value interface{}) error {
 	}
 }
 
+// Value implements the driver.Valuer interface for database storage
+func (t WebhookEventTypes) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(t)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (t *WebhookEventTypes) Scan(value interface{}) error {
+	if value == nil {
+		*t = nil
+		return nil
+	}
+	
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	default:
+		return fmt.Errorf("cannot scan %T into WebhookEventTypes", value)
+	}
+}
+
+// Value implements the driver.Valuer interface for database storage. Signed
+// URLs are not stored.
+func (v FileVariants) Value() (driver.Value, error) {
+	stored := make(FileVariants, len(v))
+	for i, variant := range v {
+		variant.URL = ""
+		stored[i] = variant
+	}
+	return json.Marshal(stored)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (v *FileVariants) Scan(value interface{}) error {
+	if value == nil {
+		*v = FileVariants{}
+		return nil
+	}
+
+	switch data := value.(type) {
+	case []byte:
+		return json.Unmarshal(data, v)
+	case string:
+		return json.Unmarshal([]byte(data), v)
+	default:
+		return fmt.Errorf("cannot scan %T into FileVariants", value)
+	}
+}
+
+// Matches reports whether the webhook subscribes to eventType
+func (t WebhookEventTypes) Matches(eventType string) bool {
+	if len(t) == 0 {
+		return true
+	}
+	for _, et := range t {
+		if et == eventType {
+			return true
+		}
+	}
+	return false
+}
+
 // Validation methods
 
 // ValidateTaskStatus checks if a task status is valid
 func ValidateTaskStatus(status string) bool {
-	validStatuses := map[string]bool{
-		"pending":     true,
-		"in_progress": true,
-		"completed":   true,
-		"overdue":     true,
-	}
-	return validStatuses[status]
+	return TaskStatus(status).Valid()
 }
 
 // ValidateTaskPriority checks if a task priority is valid
 func ValidateTaskPriority(priority string) bool {
-	validPriorities := map[string]bool{
-		"low":    true,
-		"medium": true,
-		"high":   true,
-	}
-	return validPriorities[priority]
+	return TaskPriority(priority).Valid()
 }
 
 // ValidatePropertyType checks if a property type is valid
 func ValidatePropertyType(propertyType string) bool {
-	validTypes := map[string]bool{
-		"house":     true,
-		"apartment": true,
-		"condo":     true,
-		"townhouse": true,
-		"other":     true,
-	}
-	return validTypes[propertyType]
+	return PropertyType(propertyType).Valid()
 }
 
 // ValidateRoomType checks if a room type is valid
 func ValidateRoomType(roomType string) bool {
-	validTypes := map[string]bool{
-		"bedroom":  true,
-		"bathroom": true,
-		"kitchen":  true,
-		"living":   true,
-		"garage":   true,
-		"basement": true,
-		"attic":    true,
-		"office":   true,
-		"other":    true,
-	}
-	return validTypes[roomType]
+	return RoomType(roomType).Valid()
 }
 
 // ValidateNotificationType checks if a notification type is valid
 func ValidateNotificationType(notificationType string) bool {
-	validTypes := map[string]bool{
-		"task_reminder":   true,
-		"maintenance_due": true,
-		"system":          true,
-		"alert":           true,
-	}
-	return validTypes[notificationType]
-}
\ No newline at end of file
+	return NotificationType(notificationType).Valid()
+}
@@ -0,0 +1,96 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Type identifies the kind of a domain event
+type Type string
+
+// Domain event types
+const (
+	TaskCreated         Type = "task.created"
+	TaskUpdated         Type = "task.updated"
+	TaskDeleted         Type = "task.deleted"
+	TaskCompleted       Type = "task.completed"
+	PropertyCreated     Type = "property.created"
+	PropertyUpdated     Type = "property.updated"
+	PropertyDeleted     Type = "property.deleted"
+	RoomCreated         Type = "room.created"
+	RoomUpdated         Type = "room.updated"
+	RoomDeleted         Type = "room.deleted"
+	MaintenanceRecorded Type = "maintenance.recorded"
+	NotificationCreated Type = "notification.created"
+)
+
+// Types lists every domain event type
+var Types = []Type{
+	TaskCreated,
+	TaskUpdated,
+	TaskDeleted,
+	TaskCompleted,
+	PropertyCreated,
+	PropertyUpdated,
+	PropertyDeleted,
+	RoomCreated,
+	RoomUpdated,
+	RoomDeleted,
+	MaintenanceRecorded,
+	NotificationCreated,
+}
+
+// Valid checks if t is a known event type
+func (t Type) Valid() bool {
+	for _, known := range Types {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Aggregate types that events can refer to
+const (
+	AggregateTask         = "task"
+	AggregateProperty     = "property"
+	AggregateRoom         = "room"
+	AggregateMaintenance  = "maintenance_record"
+	AggregateNotification = "notification"
+)
+
+// Event represents a domain event recorded in the outbox. Payloads of task,
+// room and maintenance events carry the owning "propertyId" so consumers can
+// scope their work without a lookup.
+type Event struct {
+	Position      int64           `json:"position"`
+	Type          Type            `json:"type"`
+	AggregateType string          `json:"aggregateType"`
+	AggregateID   int             `json:"aggregateId"`
+	UserID        int             `json:"userId"`
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurredAt"`
+}
+
+// New builds an event for the given aggregate, marshaling payload to JSON
+func New(eventType Type, aggregateType string, aggregateID, userID int, payload interface{}) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, fmt.Errorf("failed to marshal %s payload: %w", eventType, err)
+	}
+
+	return Event{
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		UserID:        userID,
+		Payload:       data,
+		OccurredAt:    time.Now().UTC(),
+	}, nil
+}
+
+// Decode unmarshals the event payload into v
+func (e Event) Decode(v interface{}) error {
+	return json.Unmarshal(e.Payload, v)
+}
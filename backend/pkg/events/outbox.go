@@ -0,0 +1,98 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// outboxLockKey is the advisory lock taken by every transaction that appends
+// to the outbox. Holding it until commit makes outbox ids become visible in
+// the order they were assigned, so a reader advancing a cursor never skips an
+// event whose transaction committed late.
+const outboxLockKey = 0x686f6d65 // "home"
+
+// Append records events in the outbox as part of tx. The events become
+// visible to publishers only if tx commits, so they can never describe a data
+// change that was rolled back.
+func Append(ctx context.Context, tx *sql.Tx, evts ...Event) error {
+	if len(evts) == 0 {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", outboxLockKey); err != nil {
+		return fmt.Errorf("failed to lock outbox: %w", err)
+	}
+
+	query := `
+		INSERT INTO outbox (event_type, aggregate_type, aggregate_id, user_id, payload, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	for i := range evts {
+		evt := &evts[i]
+		var userID interface{}
+		if evt.UserID != 0 {
+			userID = evt.UserID
+		}
+
+		err := tx.QueryRowContext(ctx, query,
+			string(evt.Type), evt.AggregateType, evt.AggregateID, userID, []byte(evt.Payload), evt.OccurredAt,
+		).Scan(&evt.Position)
+		if err != nil {
+			return fmt.Errorf("failed to append %s event: %w", evt.Type, err)
+		}
+	}
+
+	return nil
+}
+
+// Read returns up to limit events with a position greater than after, in order
+func Read(ctx context.Context, db *sql.DB, after int64, limit int) ([]Event, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, event_type, aggregate_type, aggregate_id, COALESCE(user_id, 0), payload, occurred_at
+		FROM outbox
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+	`, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var evts []Event
+	for rows.Next() {
+		var evt Event
+		var eventType string
+		var payload []byte
+		if err := rows.Scan(&evt.Position, &eventType, &evt.AggregateType, &evt.AggregateID, &evt.UserID, &payload, &evt.OccurredAt); err != nil {
+			return nil, err
+		}
+		evt.Type = Type(eventType)
+		evt.Payload = payload
+		evts = append(evts, evt)
+	}
+
+	return evts, rows.Err()
+}
+
+// loadCursor returns the last delivered position for the named consumer
+func loadCursor(ctx context.Context, db *sql.DB, name string) (int64, error) {
+	var position int64
+	err := db.QueryRowContext(ctx, "SELECT position FROM outbox_cursors WHERE name = $1", name).Scan(&position)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return position, err
+}
+
+// saveCursor stores the last delivered position for the named consumer
+func saveCursor(ctx context.Context, db *sql.DB, name string, position int64) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO outbox_cursors (name, position, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (name) DO UPDATE SET position = EXCLUDED.position, updated_at = NOW()
+	`, name, position)
+	return err
+}
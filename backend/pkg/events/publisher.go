@@ -0,0 +1,177 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/myideascope/HomeGenie/backend/pkg/metrics"
+)
+
+// Handler processes a single event. Delivery is at-least-once, so handlers
+// must tolerate seeing the same event position more than once.
+type Handler func(ctx context.Context, evt Event) error
+
+// subscription pairs a handler with the event types it wants
+type subscription struct {
+	handler Handler
+	types   map[Type]bool
+}
+
+func (s subscription) wants(t Type) bool {
+	return len(s.types) == 0 || s.types[t]
+}
+
+// Publisher relays committed outbox events to in-process subscribers in
+// outbox order. Its position is persisted in outbox_cursors under its name,
+// and only advances once every subscriber has accepted an event, so a crash
+// or handler error causes redelivery rather than loss.
+type Publisher struct {
+	db            *sql.DB
+	name          string
+	batchSize     int
+	pollInterval  time.Duration
+	retryInterval time.Duration
+
+	mu            sync.RWMutex
+	subscriptions []subscription
+
+	wake chan struct{}
+}
+
+// NewPublisher creates a publisher that tracks its position under name
+func NewPublisher(db *sql.DB, name string) *Publisher {
+	return &Publisher{
+		db:            db,
+		name:          name,
+		batchSize:     100,
+		pollInterval:  time.Second,
+		retryInterval: 5 * time.Second,
+		wake:          make(chan struct{}, 1),
+	}
+}
+
+// Subscribe registers handler for the given event types, or for every event
+// when no types are given. Subscribers are called in registration order.
+func (p *Publisher) Subscribe(handler Handler, types ...Type) {
+	sub := subscription{handler: handler}
+	if len(types) > 0 {
+		sub.types = make(map[Type]bool, len(types))
+		for _, t := range types {
+			sub.types[t] = true
+		}
+	}
+
+	p.mu.Lock()
+	p.subscriptions = append(p.subscriptions, sub)
+	p.mu.Unlock()
+}
+
+// Notify wakes the publisher so events committed by the caller are relayed
+// without waiting for the next poll
+func (p *Publisher) Notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run relays events until ctx is cancelled
+func (p *Publisher) Run(ctx context.Context) error {
+	position, err := loadCursor(ctx, p.db, p.name)
+	if err != nil {
+		return fmt.Errorf("failed to load outbox cursor %s: %w", p.name, err)
+	}
+
+	for {
+		wait := p.pollInterval
+
+		next, err := p.relay(ctx, position)
+		if next > position {
+			if saveErr := saveCursor(ctx, p.db, p.name, next); saveErr != nil {
+				log.Printf("Failed to save outbox cursor %s at %d: %v", p.name, next, saveErr)
+			} else {
+				position = next
+			}
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Printf("Outbox publisher %s stopped at position %d: %v", p.name, next, err)
+			wait = p.retryInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.wake:
+		case <-time.After(wait):
+		}
+	}
+}
+
+// relay delivers every event after position and returns the new position.
+// On error the returned position is that of the last fully delivered event.
+func (p *Publisher) relay(ctx context.Context, position int64) (int64, error) {
+	for {
+		evts, err := Read(ctx, p.db, position, p.batchSize)
+		if err != nil {
+			return position, fmt.Errorf("failed to read outbox: %w", err)
+		}
+
+		for _, evt := range evts {
+			metrics.ObserveSchedulerLag("outbox:"+p.name, time.Since(evt.OccurredAt))
+			if err := p.dispatch(ctx, evt); err != nil {
+				return position, err
+			}
+			position = evt.Position
+		}
+
+		if len(evts) < p.batchSize {
+			return position, nil
+		}
+	}
+}
+
+// dispatch hands evt to every interested subscriber
+func (p *Publisher) dispatch(ctx context.Context, evt Event) error {
+	p.mu.RLock()
+	subs := p.subscriptions
+	p.mu.RUnlock()
+
+	for _, sub := range subs {
+		if !sub.wants(evt.Type) {
+			continue
+		}
+		if err := sub.handler(ctx, evt); err != nil {
+			return fmt.Errorf("handler failed for %s event %d: %w", evt.Type, evt.Position, err)
+		}
+	}
+	return nil
+}
+
+// Replay delivers every event after from to handler, in order, up to the
+// current end of the outbox. It does not move the publisher's cursor.
+func Replay(ctx context.Context, db *sql.DB, from int64, handler Handler) error {
+	position := from
+	for {
+		evts, err := Read(ctx, db, position, 500)
+		if err != nil {
+			return fmt.Errorf("failed to read outbox: %w", err)
+		}
+		if len(evts) == 0 {
+			return nil
+		}
+
+		for _, evt := range evts {
+			if err := handler(ctx, evt); err != nil {
+				return fmt.Errorf("replay stopped at %s event %d: %w", evt.Type, evt.Position, err)
+			}
+			position = evt.Position
+		}
+	}
+}
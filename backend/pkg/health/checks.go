@@ -0,0 +1,109 @@
+package health
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+	"github.com/myideascope/HomeGenie/backend/pkg/scanner"
+	"github.com/redis/go-redis/v9"
+)
+
+// Database checks that Postgres accepts queries
+func Database(db *sql.DB) Check {
+	return Check{
+		Name:     "database",
+		Critical: true,
+		Run: func(ctx context.Context) error {
+			return db.PingContext(ctx)
+		},
+	}
+}
+
+// Migrations checks that every migration known to this build has been
+// applied, so a new release is not routed traffic against an old schema
+func Migrations(db *sql.DB) Check {
+	return Check{
+		Name:     "migrations",
+		Critical: true,
+		Run: func(ctx context.Context) error {
+			_, pending, err := database.MigrationStatus(ctx, db)
+			if err != nil {
+				return err
+			}
+			if len(pending) > 0 {
+				return fmt.Errorf("%d pending migrations: %s", len(pending), strings.Join(pending, ", "))
+			}
+			return nil
+		},
+	}
+}
+
+// EnumConstraints checks that the schema's CHECK constraints still accept
+// exactly the values of the Go enums. Drift means some writes will fail, but
+// most requests are unaffected, so it is not critical.
+func EnumConstraints(db *sql.DB) Check {
+	return Check{
+		Name: "enum_constraints",
+		Run: func(ctx context.Context) error {
+			return database.VerifyEnumConstraints(ctx, db)
+		},
+	}
+}
+
+// Redis checks that Redis answers PING. Redis only backs caching and rate
+// limiting, both of which fail open, so it is not critical.
+func Redis(client redis.UniversalClient) Check {
+	return Check{
+		Name: "redis",
+		Run: func(ctx context.Context) error {
+			return client.Ping(ctx).Err()
+		},
+	}
+}
+
+// SMTP checks that the mail server at addr accepts connections and sends a
+// 220 greeting. Email is one notification channel among several, so it is
+// not critical.
+func SMTP(addr string) Check {
+	return Check{
+		Name:    "mail",
+		Timeout: 5 * time.Second,
+		Run: func(ctx context.Context) error {
+			var dialer net.Dialer
+			conn, err := dialer.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			if deadline, ok := ctx.Deadline(); ok {
+				conn.SetDeadline(deadline)
+			}
+			greeting, err := bufio.NewReader(conn).ReadString('\n')
+			if err != nil {
+				return fmt.Errorf("failed to read SMTP greeting: %w", err)
+			}
+			if !strings.HasPrefix(greeting, "220") {
+				return fmt.Errorf("unexpected SMTP greeting: %s", strings.TrimSpace(greeting))
+			}
+			fmt.Fprint(conn, "QUIT\r\n")
+			return nil
+		},
+	}
+}
+
+// Scanner checks that the malware scanner answers. Uploads wait as pending
+// while it is down rather than failing, so it is not critical.
+func Scanner(s scanner.Scanner) Check {
+	return Check{
+		Name:    "scanner",
+		Timeout: 5 * time.Second,
+		Run:     s.Ping,
+	}
+}
@@ -0,0 +1,157 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Default timeout for a check that does not set its own
+const defaultTimeout = 2 * time.Second
+
+// Status of a single dependency
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// Overall report status, matching the frontend's HealthService contract
+const (
+	Healthy   = "healthy"
+	Unhealthy = "unhealthy"
+)
+
+// ErrShuttingDown is reported once the server has started draining
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// Check probes one dependency. A failing critical check makes the service
+// unready; a failing optional check is reported but does not.
+type Check struct {
+	Name     string
+	Critical bool
+	Timeout  time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Status   Status `json:"status"`
+	Critical bool   `json:"critical"`
+	// Latency in milliseconds
+	Latency int64  `json:"latency"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Report is the JSON body served by the readiness endpoints
+type Report struct {
+	Status    string            `json:"status"`
+	Version   string            `json:"version"`
+	Timestamp string            `json:"timestamp"`
+	Services  map[string]Status `json:"services"`
+	Checks    map[string]Result `json:"checks"`
+}
+
+// Ready reports whether every critical check passed
+func (r Report) Ready() bool {
+	return r.Status == Healthy
+}
+
+// Checker runs the registered dependency checks and tracks shutdown state
+type Checker struct {
+	version string
+
+	mu     sync.RWMutex
+	checks []Check
+
+	shuttingDown atomic.Bool
+}
+
+// NewChecker creates a checker that reports version in every report
+func NewChecker(version string) *Checker {
+	return &Checker{version: version}
+}
+
+// Register adds a check. Checks run concurrently, each under its own timeout.
+func (c *Checker) Register(checks ...Check) {
+	c.mu.Lock()
+	c.checks = append(c.checks, checks...)
+	c.mu.Unlock()
+}
+
+// BeginShutdown marks the service unready so load balancers stop routing to
+// it. Call it when a termination signal arrives, before http.Server.Shutdown,
+// and allow a probe interval to pass so in-flight traffic drains.
+func (c *Checker) BeginShutdown() {
+	c.shuttingDown.Store(true)
+}
+
+// ShuttingDown reports whether BeginShutdown has been called
+func (c *Checker) ShuttingDown() bool {
+	return c.shuttingDown.Load()
+}
+
+// Run executes every registered check and builds a report
+func (c *Checker) Run(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := c.checks
+	c.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = Probe(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:    Healthy,
+		Version:   c.version,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Services:  make(map[string]Status, len(checks)),
+		Checks:    make(map[string]Result, len(checks)),
+	}
+	for i, check := range checks {
+		report.Services[check.Name] = results[i].Status
+		report.Checks[check.Name] = results[i]
+		if check.Critical && results[i].Status == StatusDown {
+			report.Status = Unhealthy
+		}
+	}
+
+	if c.ShuttingDown() {
+		report.Status = Unhealthy
+		report.Checks["shutdown"] = Result{Status: StatusDown, Critical: true, Error: ErrShuttingDown.Error()}
+	}
+	return report
+}
+
+// Probe executes a single check under its timeout
+func Probe(ctx context.Context, check Check) Result {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Run(ctx)
+	result := Result{
+		Status:   StatusUp,
+		Critical: check.Critical,
+		Latency:  time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,223 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // registers the WebP decoder
+)
+
+// ErrUnsupported is returned for content types Process does not handle
+var ErrUnsupported = errors.New("unsupported image format")
+
+// ErrInvalid is returned when an image cannot be decoded
+var ErrInvalid = errors.New("image could not be decoded")
+
+// ErrTooLarge is returned for images whose dimensions exceed the pixel
+// limit, checked before decoding so small files cannot claim huge canvases
+var ErrTooLarge = errors.New("image dimensions are too large")
+
+// maxPixels caps the decoded size of an image at about 200MB of pixels
+const maxPixels = 50_000_000
+
+// jpegQuality is used for every JPEG written
+const jpegQuality = 85
+
+// Size is a variant that fits within MaxDimension pixels on its longer side
+type Size struct {
+	Name         string
+	MaxDimension int
+}
+
+// Sizes are the variants built for every image, smallest first
+var Sizes = []Size{
+	{Name: "thumbnail", MaxDimension: 256},
+	{Name: "medium", MaxDimension: 1024},
+}
+
+// Image is an encoded image
+type Image struct {
+	Name        string
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// Result is a sanitized image and its variants. Variants only exist for the
+// sizes the image is larger than.
+type Result struct {
+	Original Image
+	Variants []Image
+}
+
+// Supported reports whether Process handles contentType. GIFs are left
+// alone so animations survive.
+func Supported(contentType string) bool {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/webp":
+		return true
+	}
+	return false
+}
+
+// Process decodes an image read from r, rotates it upright according to its EXIF
+// orientation and builds its variants. Re-encoding drops EXIF, including GPS
+// coordinates, and every other kind of embedded metadata. JPEGs and PNGs keep their format; WebP, which has no
+// pure Go encoder, becomes JPEG, or PNG when it has transparency.
+func Process(r io.ReadSeeker, contentType string) (*Result, error) {
+	if !Supported(contentType) {
+		return nil, ErrUnsupported
+	}
+
+	if err := rewind(r); err != nil {
+		return nil, err
+	}
+	cfg, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+	if err := rewind(r); err != nil {
+		return nil, err
+	}
+	decoded, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	img := toNRGBA(decoded)
+	if contentType == "image/jpeg" {
+		if err := rewind(r); err != nil {
+			return nil, err
+		}
+		img = orient(img, jpegOrientation(r))
+	}
+
+	outType := contentType
+	if contentType == "image/webp" {
+		outType = "image/jpeg"
+		if !img.Opaque() {
+			outType = "image/png"
+		}
+	}
+
+	original, err := encode("original", img, outType)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Original: original, Variants: []Image{}}
+
+	for _, size := range Sizes {
+		w, h, ok := fit(original.Width, original.Height, size.MaxDimension)
+		if !ok {
+			continue
+		}
+		scaled := image.NewNRGBA(image.Rect(0, 0, w, h))
+		draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, img.Bounds(), draw.Src, nil)
+
+		variant, err := encode(size.Name, scaled, outType)
+		if err != nil {
+			return nil, err
+		}
+		result.Variants = append(result.Variants, variant)
+	}
+	return result, nil
+}
+
+func rewind(r io.Seeker) error {
+	_, err := r.Seek(0, io.SeekStart)
+	return err
+}
+
+// fit scales w×h to fit within limit on its longer side, reporting false when
+// it already does
+func fit(w, h, limit int) (int, int, bool) {
+	if w <= limit && h <= limit {
+		return w, h, false
+	}
+	if w >= h {
+		return limit, scaleSide(h, limit, w), true
+	}
+	return scaleSide(w, limit, h), limit, true
+}
+
+// scaleSide returns side*num/den rounded, but at least one pixel
+func scaleSide(side, num, den int) int {
+	if scaled := (side*num + den/2) / den; scaled > 0 {
+		return scaled
+	}
+	return 1
+}
+
+func encode(name string, img *image.NRGBA, contentType string) (Image, error) {
+	var buf bytes.Buffer
+	var err error
+	if contentType == "image/png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	}
+	if err != nil {
+		return Image{}, err
+	}
+	b := img.Bounds()
+	return Image{Name: name, Data: buf.Bytes(), ContentType: contentType, Width: b.Dx(), Height: b.Dy()}, nil
+}
+
+// toNRGBA copies img into an NRGBA image with its origin at 0,0
+func toNRGBA(img image.Image) *image.NRGBA {
+	b := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	return dst
+}
+
+// orient applies an EXIF orientation, returning an upright image. 2, 3 and
+// 4 are mirrored or upside down; 5 to 8 are also turned a quarter.
+func orient(img *image.NRGBA, orientation int) *image.NRGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	// dest maps a source pixel to its upright position
+	var dest func(x, y int) (int, int)
+	switch orientation {
+	case 2:
+		dest = func(x, y int) (int, int) { return w - 1 - x, y }
+	case 3:
+		dest = func(x, y int) (int, int) { return w - 1 - x, h - 1 - y }
+	case 4:
+		dest = func(x, y int) (int, int) { return x, h - 1 - y }
+	case 5:
+		dest = func(x, y int) (int, int) { return y, x }
+	case 6:
+		dest = func(x, y int) (int, int) { return h - 1 - y, x }
+	case 7:
+		dest = func(x, y int) (int, int) { return h - 1 - y, w - 1 - x }
+	case 8:
+		dest = func(x, y int) (int, int) { return y, w - 1 - x }
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			to, from := dst.PixOffset(dest(x, y)), img.PixOffset(x, y)
+			copy(dst.Pix[to:to+4], img.Pix[from:from+4])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,277 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// gpsMarker is stored in the GPS IFD of test images so tests can check it
+// does not survive processing
+const gpsMarker = "HOMEGENIE-GPS"
+
+// exifTIFF builds a big-endian TIFF structure with an orientation tag and a
+// GPS IFD holding gpsMarker as its map datum
+func exifTIFF(orientation uint16) []byte {
+	var b bytes.Buffer
+	w := func(v interface{}) { binary.Write(&b, binary.BigEndian, v) }
+
+	b.WriteString("MM")
+	w(uint16(42))
+	w(uint32(8))
+
+	// IFD0 at 8: orientation and a pointer to the GPS IFD at 38
+	w(uint16(2))
+	w([]uint16{0x0112, 3})
+	w(uint32(1))
+	w([]uint16{orientation, 0})
+	w([]uint16{0x8825, 4})
+	w(uint32(1))
+	w(uint32(38))
+	w(uint32(0))
+
+	// GPS IFD at 38: latitude reference and a map datum stored at 68
+	w(uint16(2))
+	w([]uint16{0x0001, 2})
+	w(uint32(2))
+	b.WriteString("N\x00\x00\x00")
+	w([]uint16{0x0012, 2})
+	w(uint32(len(gpsMarker) + 1))
+	w(uint32(68))
+	w(uint32(0))
+	b.WriteString(gpsMarker + "\x00")
+	return b.Bytes()
+}
+
+// quadrants returns a 32×16 image that is red in its top-left quadrant and
+// blue elsewhere
+func quadrants() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 32, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 32; x++ {
+			c := color.NRGBA{B: 255, A: 255}
+			if x < 16 && y < 8 {
+				c = color.NRGBA{R: 255, A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// exifJPEG encodes img as a JPEG with an EXIF segment right after SOI
+func exifJPEG(t *testing.T, img image.Image, orientation uint16) []byte {
+	t.Helper()
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatalf("jpeg.Encode() error = %v", err)
+	}
+	payload := append([]byte("Exif\x00\x00"), exifTIFF(orientation)...)
+
+	var b bytes.Buffer
+	b.Write(encoded.Bytes()[:2])
+	b.Write([]byte{0xFF, 0xE1})
+	binary.Write(&b, binary.BigEndian, uint16(len(payload)+2))
+	b.Write(payload)
+	b.Write(encoded.Bytes()[2:])
+	return b.Bytes()
+}
+
+// exifPNG encodes img as a PNG with an eXIf chunk before its image data
+func exifPNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, img); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	data := encoded.Bytes()
+	idat := bytes.Index(data, []byte("IDAT")) - 4
+
+	chunk := append([]byte("eXIf"), exifTIFF(1)...)
+	var b bytes.Buffer
+	b.Write(data[:idat])
+	binary.Write(&b, binary.BigEndian, uint32(len(chunk)-4))
+	b.Write(chunk)
+	binary.Write(&b, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+	b.Write(data[idat:])
+	return b.Bytes()
+}
+
+// jpegSegments lists the markers of a JPEG's segments before its first scan
+func jpegSegments(data []byte) []byte {
+	var markers []byte
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		markers = append(markers, data[i+1])
+		if data[i+1] == 0xDA {
+			break
+		}
+		i += 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+	}
+	return markers
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	for o := uint16(1); o <= 8; o++ {
+		if got := jpegOrientation(bytes.NewReader(exifJPEG(t, quadrants(), o))); got != int(o) {
+			t.Errorf("jpegOrientation() = %d, want %d", got, o)
+		}
+	}
+
+	valid := exifJPEG(t, quadrants(), 6)
+	var plain bytes.Buffer
+	if err := jpeg.Encode(&plain, quadrants(), nil); err != nil {
+		t.Fatalf("jpeg.Encode() error = %v", err)
+	}
+	malformed := map[string][]byte{
+		"empty":            nil,
+		"not a JPEG":       []byte("GIF89a"),
+		"truncated":        valid[:30],
+		"out of range":     exifJPEG(t, quadrants(), 9),
+		"no EXIF":          plain.Bytes(),
+		"bad segment size": append([]byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x01}, valid[6:]...),
+	}
+	for name, data := range malformed {
+		if got := jpegOrientation(bytes.NewReader(data)); got != 1 {
+			t.Errorf("jpegOrientation(%s) = %d, want 1", name, got)
+		}
+	}
+}
+
+func TestProcessStripsEXIF(t *testing.T) {
+	data := exifJPEG(t, quadrants(), 1)
+	if !bytes.Contains(data, []byte(gpsMarker)) {
+		t.Fatal("test image does not carry the GPS marker")
+	}
+
+	result, err := Process(bytes.NewReader(data), "image/jpeg")
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	for _, img := range append([]Image{result.Original}, result.Variants...) {
+		if bytes.Contains(img.Data, []byte(gpsMarker)) || bytes.Contains(img.Data, []byte("Exif")) {
+			t.Errorf("%s still carries EXIF or GPS data", img.Name)
+		}
+		if bytes.Contains(jpegSegments(img.Data), []byte{0xE1}) {
+			t.Errorf("%s still has an APP1 segment", img.Name)
+		}
+	}
+}
+
+func TestProcessStripsPNGMetadata(t *testing.T) {
+	data := exifPNG(t, quadrants())
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		t.Fatalf("test image is not a valid PNG: %v", err)
+	}
+
+	result, err := Process(bytes.NewReader(data), "image/png")
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if result.Original.ContentType != "image/png" {
+		t.Errorf("Original.ContentType = %s, want image/png", result.Original.ContentType)
+	}
+	if bytes.Contains(result.Original.Data, []byte("eXIf")) || bytes.Contains(result.Original.Data, []byte(gpsMarker)) {
+		t.Error("processed PNG still carries its eXIf chunk")
+	}
+}
+
+func TestProcessOrientation(t *testing.T) {
+	// Where the red quadrant, top-left as stored, appears once upright
+	tests := []struct {
+		orientation   uint16
+		width, height int
+		redX, redY    int
+	}{
+		{1, 32, 16, 0, 0},
+		{2, 32, 16, 1, 0},
+		{3, 32, 16, 1, 1},
+		{4, 32, 16, 0, 1},
+		{5, 16, 32, 0, 0},
+		{6, 16, 32, 1, 0},
+		{7, 16, 32, 1, 1},
+		{8, 16, 32, 0, 1},
+	}
+	for _, tt := range tests {
+		result, err := Process(bytes.NewReader(exifJPEG(t, quadrants(), tt.orientation)), "image/jpeg")
+		if err != nil {
+			t.Fatalf("Process(orientation %d) error = %v", tt.orientation, err)
+		}
+		if result.Original.Width != tt.width || result.Original.Height != tt.height {
+			t.Errorf("orientation %d: size = %d×%d, want %d×%d", tt.orientation,
+				result.Original.Width, result.Original.Height, tt.width, tt.height)
+			continue
+		}
+		img, err := jpeg.Decode(bytes.NewReader(result.Original.Data))
+		if err != nil {
+			t.Fatalf("orientation %d: output does not decode: %v", tt.orientation, err)
+		}
+		if got := jpegOrientation(bytes.NewReader(result.Original.Data)); got != 1 {
+			t.Errorf("orientation %d: output orientation = %d, want 1", tt.orientation, got)
+		}
+
+		// Sample the centre of each quadrant
+		for qy := 0; qy < 2; qy++ {
+			for qx := 0; qx < 2; qx++ {
+				r, _, b, _ := img.At(tt.width/4+qx*tt.width/2, tt.height/4+qy*tt.height/2).RGBA()
+				red := r>>8 > 200 && b>>8 < 60
+				if want := qx == tt.redX && qy == tt.redY; red != want {
+					t.Errorf("orientation %d: quadrant (%d,%d) red = %v, want %v", tt.orientation, qx, qy, red, want)
+				}
+			}
+		}
+	}
+}
+
+func TestProcessVariants(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 600, 300))
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+
+	result, err := Process(bytes.NewReader(b.Bytes()), "image/png")
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if len(result.Variants) != 1 {
+		t.Fatalf("got %d variants, want only the thumbnail", len(result.Variants))
+	}
+	if v := result.Variants[0]; v.Name != "thumbnail" || v.Width != 256 || v.Height != 128 {
+		t.Errorf("variant = %s %d×%d, want thumbnail 256×128", v.Name, v.Width, v.Height)
+	}
+}
+
+func TestProcessRejects(t *testing.T) {
+	// A PNG header claiming 100000×100000 pixels, with no image data
+	var huge bytes.Buffer
+	huge.WriteString("\x89PNG\r\n\x1a\n")
+	ihdr := []byte("IHDR")
+	ihdr = binary.BigEndian.AppendUint32(ihdr, 100000)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, 100000)
+	ihdr = append(ihdr, 8, 6, 0, 0, 0)
+	binary.Write(&huge, binary.BigEndian, uint32(len(ihdr)-4))
+	huge.Write(ihdr)
+	binary.Write(&huge, binary.BigEndian, crc32.ChecksumIEEE(ihdr))
+
+	tests := []struct {
+		name        string
+		data        []byte
+		contentType string
+		want        error
+	}{
+		{"gif", []byte("GIF89a"), "image/gif", ErrUnsupported},
+		{"garbage", []byte("not an image"), "image/png", ErrInvalid},
+		{"huge canvas", huge.Bytes(), "image/png", ErrTooLarge},
+	}
+	for _, tt := range tests {
+		if _, err := Process(bytes.NewReader(tt.data), tt.contentType); !errors.Is(err, tt.want) {
+			t.Errorf("Process(%s) error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Config configures the application logger
+type Config struct {
+	Level  string    // debug, info, warn or error
+	Format string    // json or text
+	Output io.Writer // defaults to stdout
+}
+
+// ConfigFromEnv reads LOG_LEVEL and LOG_FORMAT
+func ConfigFromEnv() Config {
+	return Config{
+		Level:  os.Getenv("LOG_LEVEL"),
+		Format: os.Getenv("LOG_FORMAT"),
+	}
+}
+
+// New creates a logger from config. Unknown levels fall back to info and
+// unknown formats to JSON.
+func New(config Config) *slog.Logger {
+	output := config.Output
+	if output == nil {
+		output = os.Stdout
+	}
+
+	opts := &slog.HandlerOptions{Level: ParseLevel(config.Level)}
+
+	var handler slog.Handler
+	if strings.EqualFold(config.Format, "text") {
+		handler = slog.NewTextHandler(output, opts)
+	} else {
+		handler = slog.NewJSONHandler(output, opts)
+	}
+	return slog.New(handler)
+}
+
+// Setup creates a logger from config and installs it as the default for both
+// slog and the standard log package, so existing log.Printf calls are
+// emitted through the same handler
+func Setup(config Config) *slog.Logger {
+	logger := New(config)
+	slog.SetDefault(logger)
+	log.SetFlags(0)
+	return logger
+}
+
+// ParseLevel converts a LOG_LEVEL value to a slog level
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+type contextKey struct{}
+
+// WithLogger returns a copy of ctx carrying logger
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger stored in ctx, or the default logger
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
@@ -0,0 +1,179 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Redacted replaces masked values in logs
+const Redacted = "[REDACTED]"
+
+// RedactionConfig lists what must never reach the logs
+type RedactionConfig struct {
+	// Keys are JSON field and query parameter names whose values are masked.
+	// Matching ignores case, "_" and "-", so "apiKey" also covers "api_key".
+	Keys []string
+	// KeySuffixes mask every name ending in one of them, so "token" covers
+	// "refreshToken" and "reset_token" but not "tokenCount"
+	KeySuffixes []string
+	// Headers are HTTP header names whose values are masked
+	Headers []string
+	// SkipBodyPaths are path.Match patterns for endpoints whose bodies are
+	// never logged at all, e.g. "/api/v1/auth/*-password"
+	SkipBodyPaths []string
+}
+
+// DefaultRedactionConfig returns the redaction rules used by the logging middleware
+func DefaultRedactionConfig() RedactionConfig {
+	return RedactionConfig{
+		Keys: []string{
+			"authorization", "cookie", "passwordHash", "phone", "phoneNumber", "email", "emailAddress",
+			"address",
+		},
+		KeySuffixes: []string{"password", "token", "secret", "apiKey"},
+		Headers: []string{
+			"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie",
+			"X-Api-Key", "X-HomeGenie-Signature",
+		},
+		SkipBodyPaths: []string{
+			"/api/v1/auth/login",
+			"/api/v1/auth/register",
+			"/api/v1/auth/*-password",
+		},
+	}
+}
+
+// Redactor masks sensitive values in request data before it is logged
+type Redactor struct {
+	keys          map[string]bool
+	keySuffixes   []string
+	headers       map[string]bool
+	skipBodyPaths []string
+}
+
+// NewRedactor creates a redactor from config
+func NewRedactor(config RedactionConfig) *Redactor {
+	r := &Redactor{
+		keys:          make(map[string]bool, len(config.Keys)),
+		headers:       make(map[string]bool, len(config.Headers)),
+		skipBodyPaths: config.SkipBodyPaths,
+	}
+	for _, key := range config.Keys {
+		if k := normalizeKey(key); k != "" {
+			r.keys[k] = true
+		}
+	}
+	for _, suffix := range config.KeySuffixes {
+		if k := normalizeKey(suffix); k != "" {
+			r.keySuffixes = append(r.keySuffixes, k)
+		}
+	}
+	for _, header := range config.Headers {
+		r.headers[http.CanonicalHeaderKey(header)] = true
+	}
+	return r
+}
+
+// SkipBody reports whether bodies for urlPath must not be logged
+func (r *Redactor) SkipBody(urlPath string) bool {
+	for _, pattern := range r.skipBodyPaths {
+		if ok, _ := path.Match(pattern, urlPath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSensitiveKey reports whether values under name must be masked
+func (r *Redactor) IsSensitiveKey(name string) bool {
+	n := normalizeKey(name)
+	if r.keys[n] {
+		return true
+	}
+	for _, suffix := range r.keySuffixes {
+		if strings.HasSuffix(n, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// JSON returns body with every sensitive field masked, at any depth. Bodies
+// that are not valid JSON cannot be inspected and are dropped entirely.
+func (r *Redactor) JSON(body []byte) string {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return ""
+	}
+
+	redacted, err := json.Marshal(r.redactValue(value))
+	if err != nil {
+		return ""
+	}
+	return string(redacted)
+}
+
+// redactValue walks a decoded JSON value and masks sensitive fields
+func (r *Redactor) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if r.IsSensitiveKey(key) {
+				v[key] = Redacted
+			} else {
+				v[key] = r.redactValue(child)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = r.redactValue(child)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// Query returns rawQuery with sensitive parameter values masked
+func (r *Redactor) Query(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return Redacted
+	}
+	for key := range values {
+		if r.IsSensitiveKey(key) {
+			values[key] = []string{Redacted}
+		}
+	}
+	return values.Encode()
+}
+
+// Headers returns a flattened copy of header with sensitive values masked
+func (r *Redactor) Headers(header http.Header) map[string]string {
+	out := make(map[string]string, len(header))
+	for name, values := range header {
+		if r.headers[http.CanonicalHeaderKey(name)] {
+			out[name] = Redacted
+		} else {
+			out[name] = strings.Join(values, ", ")
+		}
+	}
+	return out
+}
+
+// normalizeKey lowercases name and strips separators
+func normalizeKey(name string) string {
+	return strings.NewReplacer("_", "", "-", "", " ", "").Replace(strings.ToLower(name))
+}
@@ -0,0 +1,149 @@
+package logging
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestIsSensitiveKey(t *testing.T) {
+	r := NewRedactor(DefaultRedactionConfig())
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"password", true},
+		{"newPassword", true},
+		{"current_password", true},
+		{"passwordHash", true},
+		{"token", true},
+		{"refreshToken", true},
+		{"reset_token", true},
+		{"api_key", true},
+		{"X-Api-Key", true},
+		{"clientSecret", true},
+		{"Authorization", true},
+		{"email", true},
+		{"Email", true},
+		{"email_address", true},
+		{"phone", true},
+		{"phoneNumber", true},
+		{"address", true},
+		{"emailNotifications", false},
+		{"ipAddress", false},
+		{"tokenCount", false},
+		{"passwordChangedAt", false},
+		{"smsNotifications", false},
+		{"title", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := r.IsSensitiveKey(tt.name); got != tt.want {
+			t.Errorf("IsSensitiveKey(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	r := NewRedactor(DefaultRedactionConfig())
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "top level",
+			body: `{"email":"a@example.com","password":"hunter2","emailNotifications":true}`,
+			want: `{"email":"[REDACTED]","emailNotifications":true,"password":"[REDACTED]"}`,
+		},
+		{
+			name: "nested objects and arrays",
+			body: `{"user":{"refreshToken":"abc","name":"Ann"},"contacts":[{"phone":"555"},{"ipAddress":"10.0.0.1"}]}`,
+			want: `{"contacts":[{"phone":"[REDACTED]"},{"ipAddress":"10.0.0.1"}],"user":{"name":"Ann","refreshToken":"[REDACTED]"}}`,
+		},
+		{
+			name: "whole object under a sensitive key",
+			body: `{"address":{"street":"1 Main St"},"cost":12.50}`,
+			want: `{"address":"[REDACTED]","cost":12.50}`,
+		},
+		{name: "array root", body: `[{"token":"x"}]`, want: `[{"token":"[REDACTED]"}]`},
+		{name: "not JSON", body: `password=hunter2`, want: ``},
+		{name: "empty", body: ``, want: ``},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.JSON([]byte(tt.body)); got != tt.want {
+				t.Errorf("JSON(%s) = %s, want %s", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuery(t *testing.T) {
+	r := NewRedactor(DefaultRedactionConfig())
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"", ""},
+		{"page=2&limit=10", "limit=10&page=2"},
+		{"token=abc&page=2", "page=2&token=%5BREDACTED%5D"},
+		{"access_token=a&access_token=b", "access_token=%5BREDACTED%5D"},
+		{"ipAddress=10.0.0.1", "ipAddress=10.0.0.1"},
+		{"token=%zz", Redacted},
+	}
+	for _, tt := range tests {
+		if got := r.Query(tt.raw); got != tt.want {
+			t.Errorf("Query(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	r := NewRedactor(DefaultRedactionConfig())
+	header := http.Header{
+		"Authorization":         {"Bearer abc"},
+		"Cookie":                {"session=1"},
+		"x-homegenie-signature": {"sha256=def"},
+		"Accept":                {"application/json", "text/plain"},
+	}
+
+	got := r.Headers(header)
+	want := map[string]string{
+		"Authorization":         Redacted,
+		"Cookie":                Redacted,
+		"x-homegenie-signature": Redacted,
+		"Accept":                "application/json, text/plain",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Headers() = %v, want %v", got, want)
+	}
+	for name, value := range want {
+		if got[name] != value {
+			t.Errorf("Headers()[%q] = %q, want %q", name, got[name], value)
+		}
+	}
+	if header.Get("Authorization") != "Bearer abc" {
+		t.Error("Headers() modified the request header")
+	}
+}
+
+func TestSkipBody(t *testing.T) {
+	r := NewRedactor(DefaultRedactionConfig())
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/api/v1/auth/login", true},
+		{"/api/v1/auth/register", true},
+		{"/api/v1/auth/change-password", true},
+		{"/api/v1/auth/reset-password", true},
+		{"/api/v1/auth/login/extra", false},
+		{"/api/v1/auth/refresh", false},
+		{"/api/v1/tasks", false},
+	}
+	for _, tt := range tests {
+		if got := r.SkipBody(tt.path); got != tt.want {
+			t.Errorf("SkipBody(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
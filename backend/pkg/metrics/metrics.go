@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds every HomeGenie metric plus the Go runtime and process collectors
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests counts handled requests. Names and labels match the
+	// alert rules in deploy/observability/prometheus/rules/homegenie.yml.
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests handled, by method, route template and status code.",
+	}, []string{"method", "route", "code"})
+
+	// HTTPRequestDuration observes request latency
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency, by method, route template and status code.",
+		Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10},
+	}, []string{"method", "route", "code"})
+
+	// HTTPRequestsInFlight tracks requests currently being served
+	HTTPRequestsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "HTTP requests currently being served.",
+	})
+
+	notificationDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "homegenie_notification_deliveries_total",
+		Help: "Notification delivery attempts, by channel and result.",
+	}, []string{"channel", "result"})
+
+	schedulerLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "homegenie_scheduler_lag_seconds",
+		Help: "Delay between when background work became due and when it ran, by job.",
+	}, []string{"job"})
+
+	// websocketConnections is kept up to date by the WebSocket handler
+	// through WebSocketConnected and WebSocketDisconnected. Until that
+	// handler is added it stays at zero.
+	websocketConnections = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "homegenie_websocket_connections",
+		Help: "Open WebSocket connections.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		HTTPRequestsInFlight,
+		notificationDeliveries,
+		schedulerLag,
+		websocketConnections,
+	)
+}
+
+// Handler serves the registry in the Prometheus exposition format. The
+// server mounts it at /metrics through v1.RegisterMetricsRoutes.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RegisterDB exposes connection pool statistics and migration state for db
+func RegisterDB(db *sql.DB, name string) error {
+	if err := Registry.Register(collectors.NewDBStatsCollector(db, name)); err != nil {
+		return err
+	}
+	return Registry.Register(&migrationCollector{db: db})
+}
+
+// RecordNotificationDelivery counts a delivery attempt on channel
+func RecordNotificationDelivery(channel string, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	notificationDeliveries.WithLabelValues(channel, result).Inc()
+}
+
+// ObserveSchedulerLag records how late a background job ran
+func ObserveSchedulerLag(job string, lag time.Duration) {
+	if lag < 0 {
+		lag = 0
+	}
+	schedulerLag.WithLabelValues(job).Set(lag.Seconds())
+}
+
+// WebSocketConnected increments the open WebSocket connection count
+func WebSocketConnected() {
+	websocketConnections.Inc()
+}
+
+// WebSocketDisconnected decrements the open WebSocket connection count
+func WebSocketDisconnected() {
+	websocketConnections.Dec()
+}
+
+var (
+	migrationsAppliedDesc = prometheus.NewDesc(
+		"homegenie_schema_migrations_applied",
+		"Number of applied schema migrations.", nil, nil)
+	migrationsPendingDesc = prometheus.NewDesc(
+		"homegenie_schema_migrations_pending",
+		"Number of schema migrations known to this build but not yet applied.", nil, nil)
+	migrationVersionDesc = prometheus.NewDesc(
+		"homegenie_schema_migration_info",
+		"Latest applied schema migration version.", []string{"version"}, nil)
+)
+
+// migrationCollector reads migration state from the database at scrape time
+type migrationCollector struct {
+	db *sql.DB
+}
+
+func (m *migrationCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- migrationsAppliedDesc
+	ch <- migrationsPendingDesc
+	ch <- migrationVersionDesc
+}
+
+func (m *migrationCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	applied, pending, err := database.MigrationStatus(ctx, m.db)
+	if err != nil {
+		log.Printf("Failed to collect migration metrics: %v", err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(migrationsAppliedDesc, prometheus.GaugeValue, float64(len(applied)))
+	ch <- prometheus.MustNewConstMetric(migrationsPendingDesc, prometheus.GaugeValue, float64(len(pending)))
+	if len(applied) > 0 {
+		ch <- prometheus.MustNewConstMetric(migrationVersionDesc, prometheus.GaugeValue, 1, applied[len(applied)-1])
+	}
+}
@@ -1,7 +1,6 @@
 package middleware
 
 import (
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -17,24 +16,14 @@ func AuthRequired(authService AuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token := extractToken(c)
 		if token == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":     "Authorization token required",
-				"code":      "AUTHENTICATION_ERROR",
-				"timestamp": getCurrentTimestamp(),
-			})
-			c.Abort()
+			writeError(c, AuthenticationError("Authorization token required"))
 			return
 		}
 
 		userID, err := authService.ValidateToken(token)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":     "Invalid or expired token",
-				"code":      "AUTHENTICATION_ERROR",
-				"timestamp": getCurrentTimestamp(),
-				"details":   gin.H{"validation_error": err.Error()},
-			})
-			c.Abort()
+			writeError(c, AuthenticationError("Invalid or expired token").
+				WithDetails(gin.H{"validation_error": err.Error()}))
 			return
 		}
 
@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// streamingContentTypes are response and request types that are never
+// buffered: they are large, binary or open-ended
+var streamingContentTypes = []string{
+	"text/event-stream",
+	"application/octet-stream",
+	"application/x-ndjson",
+	"application/pdf",
+	"application/zip",
+	"application/gzip",
+	"multipart/",
+	"image/",
+	"video/",
+	"audio/",
+}
+
+// isStreamingContentType checks if a content type must not be captured
+func isStreamingContentType(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, prefix := range streamingContentTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// responseWriter wraps gin.ResponseWriter to capture the response body.
+// Capture stops after limit bytes (zero means unlimited) and is abandoned
+// entirely once the response turns out to be a stream: a streaming content
+// type, an explicit Flush or a hijacked connection.
+type responseWriter struct {
+	gin.ResponseWriter
+	body      *bytes.Buffer
+	limit     int
+	checked   bool
+	truncated bool
+	streaming bool
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Flush passes through to the underlying writer. Flushing means the handler
+// is streaming, so capture stops.
+func (w *responseWriter) Flush() {
+	w.stopCapture()
+	w.ResponseWriter.Flush()
+}
+
+// Hijack passes through to the underlying writer, e.g. for WebSocket upgrades
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.stopCapture()
+	return w.ResponseWriter.Hijack()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// complete reports whether the captured body is the entire response
+func (w *responseWriter) complete() bool {
+	return !w.streaming && !w.truncated
+}
+
+func (w *responseWriter) capture(b []byte) {
+	if w.streaming {
+		return
+	}
+	if !w.checked {
+		w.checked = true
+		if isStreamingContentType(w.Header().Get("Content-Type")) {
+			w.stopCapture()
+			return
+		}
+	}
+
+	if w.limit > 0 {
+		remaining := w.limit - w.body.Len()
+		if len(b) > remaining {
+			w.body.Write(b[:remaining])
+			w.truncated = true
+			return
+		}
+	}
+	w.body.Write(b)
+}
+
+func (w *responseWriter) stopCapture() {
+	w.streaming = true
+	w.body.Reset()
+}
+
+// captureRequestBody reads up to limit bytes of the request body for logging
+// and puts them back in front of the unread remainder, so handlers still see
+// the whole body without it ever being fully buffered. It reports whether
+// the body was longer than limit.
+func captureRequestBody(req *http.Request, limit int) ([]byte, bool) {
+	if req.Body == nil || req.Body == http.NoBody || isStreamingContentType(req.Header.Get("Content-Type")) {
+		return nil, false
+	}
+
+	prefix, _ := io.ReadAll(io.LimitReader(req.Body, int64(limit)+1))
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(prefix), req.Body), req.Body}
+
+	if len(prefix) > limit {
+		return nil, true
+	}
+	return prefix, false
+}
@@ -0,0 +1,259 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/myideascope/HomeGenie/backend/pkg/validation"
+)
+
+// ErrorCode identifies the kind of failure. Values match ERROR_CODES in the
+// frontend's src/config/api.ts so clients can branch on them.
+type ErrorCode string
+
+const (
+	CodeValidation         ErrorCode = "VALIDATION_ERROR"
+	CodeAuthentication     ErrorCode = "AUTHENTICATION_ERROR"
+	CodeAuthorization      ErrorCode = "AUTHORIZATION_ERROR"
+	CodeNotFound           ErrorCode = "NOT_FOUND"
+	CodeConflict           ErrorCode = "CONFLICT"
+	CodeRateLimited        ErrorCode = "RATE_LIMITED"
+	CodeTimeout            ErrorCode = "TIMEOUT_ERROR"
+	CodeServiceUnavailable ErrorCode = "SERVICE_UNAVAILABLE"
+	CodeUnknown            ErrorCode = "UNKNOWN_ERROR"
+)
+
+// APIError is an error with the HTTP status, code and client-facing message
+// to respond with. Err keeps the underlying cause for logs and traces; it is
+// never sent to the client.
+type APIError struct {
+	Status  int
+	Code    ErrorCode
+	Message string
+	Details interface{}
+	Err     error
+}
+
+// NewAPIError creates an APIError
+func NewAPIError(status int, code ErrorCode, message string) *APIError {
+	return &APIError{Status: status, Code: code, Message: message}
+}
+
+// ValidationError reports invalid input (400)
+func ValidationError(message string) *APIError {
+	return NewAPIError(http.StatusBadRequest, CodeValidation, message)
+}
+
+// AuthenticationError reports missing or invalid credentials (401)
+func AuthenticationError(message string) *APIError {
+	return NewAPIError(http.StatusUnauthorized, CodeAuthentication, message)
+}
+
+// AuthorizationError reports an authenticated user lacking access (403)
+func AuthorizationError(message string) *APIError {
+	return NewAPIError(http.StatusForbidden, CodeAuthorization, message)
+}
+
+// NotFoundError reports a missing resource (404)
+func NotFoundError(message string) *APIError {
+	return NewAPIError(http.StatusNotFound, CodeNotFound, message)
+}
+
+// ConflictError reports a request that conflicts with current state (409)
+func ConflictError(message string) *APIError {
+	return NewAPIError(http.StatusConflict, CodeConflict, message)
+}
+
+// InternalError wraps an unexpected failure (500) without exposing it
+func InternalError(err error) *APIError {
+	return &APIError{
+		Status:  http.StatusInternalServerError,
+		Code:    CodeUnknown,
+		Message: "Internal server error",
+		Err:     err,
+	}
+}
+
+func (e *APIError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// WithDetails returns a copy of e carrying details for the client
+func (e *APIError) WithDetails(details interface{}) *APIError {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+// WithCause returns a copy of e recording err as the underlying cause
+func (e *APIError) WithCause(err error) *APIError {
+	copied := *e
+	copied.Err = err
+	return &copied
+}
+
+// ErrorBody is the JSON shape of every error response, matching the
+// frontend's ApiError interface. Details holds per-field errors for
+// validation failures.
+type ErrorBody struct {
+	Error     string      `json:"error"`
+	Code      ErrorCode   `json:"code"`
+	Details   interface{} `json:"details,omitempty"`
+	Timestamp string      `json:"timestamp"`
+	RequestID string      `json:"requestId,omitempty"`
+}
+
+// getCurrentTimestamp returns the current time in the format used by error bodies
+func getCurrentTimestamp() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+// AsAPIError converts any error into an APIError. Errors that already are
+// one pass through; Postgres constraint violations, sql.ErrNoRows and
+// timeouts are translated; anything else becomes a 500.
+func AsAPIError(err error) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		if translated := translatePostgresError(pqErr); translated != nil {
+			return translated.WithCause(err)
+		}
+	}
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return NotFoundError("Resource not found").WithCause(err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return NewAPIError(http.StatusGatewayTimeout, CodeTimeout, "Request timed out").WithCause(err)
+	}
+	return InternalError(err)
+}
+
+// translatePostgresError maps constraint and input errors to client errors.
+// It returns nil for errors that indicate a server-side problem.
+func translatePostgresError(err *pq.Error) *APIError {
+	switch err.Code {
+	case "23505": // unique_violation
+		return ConflictError("A record with the same value already exists").
+			WithDetails(gin.H{"constraint": err.Constraint})
+	case "23503": // foreign_key_violation
+		// Deleting a row that is still referenced, as opposed to inserting
+		// a reference to a row that does not exist
+		if strings.HasPrefix(err.Message, "update or delete") {
+			return ConflictError("The record is still referenced by other records").
+				WithDetails(gin.H{"constraint": err.Constraint})
+		}
+		return ValidationError("A referenced record does not exist").
+			WithDetails(gin.H{"constraint": err.Constraint})
+	case "23514": // check_violation
+		return ValidationError("A value is outside the allowed range").
+			WithDetails(gin.H{"constraint": err.Constraint})
+	case "23502": // not_null_violation
+		return ValidationError("A required value is missing").
+			WithDetails(gin.H{"field": err.Column})
+	case "22P02", "22007", "22008": // invalid_text_representation, invalid/out of range datetime
+		return ValidationError("A value has an invalid format")
+	case "57014": // query_canceled, e.g. statement_timeout
+		return NewAPIError(http.StatusGatewayTimeout, CodeTimeout, "Request timed out")
+	}
+	return nil
+}
+
+// BindError converts a request binding error into a 400 listing each invalid
+// field, with messages in the language the client asked for
+func BindError(c *gin.Context, err error) *APIError {
+	locale := validation.Locale(c.GetHeader("Accept-Language"))
+	if fields, ok := validation.Translate(err, locale); ok {
+		return ValidationError(validation.Message(locale, "invalid", "", "")).
+			WithDetails(gin.H{"fields": fields}).
+			WithCause(err)
+	}
+	return ValidationError(validation.Message(locale, "malformed", "", "")).WithCause(err)
+}
+
+// AbortWithError records err on the context and writes it as an error
+// response. Handlers can call this directly, or call c.Error(err) and
+// return, leaving ErrorHandler to respond.
+func AbortWithError(c *gin.Context, err error) {
+	apiErr := AsAPIError(err)
+	_ = c.Error(err)
+	writeError(c, apiErr)
+}
+
+// writeError writes apiErr unless a response has already started
+func writeError(c *gin.Context, apiErr *APIError) {
+	if c.Writer.Written() {
+		c.Abort()
+		return
+	}
+	c.AbortWithStatusJSON(apiErr.Status, ErrorBody{
+		Error:     apiErr.Message,
+		Code:      apiErr.Code,
+		Details:   apiErr.Details,
+		Timestamp: getCurrentTimestamp(),
+		RequestID: getRequestID(c),
+	})
+}
+
+// ErrorHandler middleware that turns errors attached with c.Error and panics
+// into the standard error response. It replaces gin.Recovery and should run
+// after RequestID and Logger so failures are logged with the request ID.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// net/http uses this panic to abort a response deliberately
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			err, ok := recovered.(error)
+			if !ok {
+				err = fmt.Errorf("%v", recovered)
+			}
+			GetLogger(c).Error("Panic recovered",
+				slog.Any("error", err),
+				slog.String("stack", string(debug.Stack())),
+			)
+			_ = c.Error(err)
+			writeError(c, InternalError(err))
+		}()
+
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		// The request log already records every entry in c.Errors
+		last := c.Errors.Last()
+		if last.IsType(gin.ErrorTypeBind) {
+			writeError(c, BindError(c, last.Err))
+			return
+		}
+		writeError(c, AsAPIError(last.Err))
+	}
+}
@@ -0,0 +1,276 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyKeyHeader is the request header carrying the client's key
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotentBody is the largest request body that is fingerprinted.
+// Larger requests (uploads) are passed through without idempotency.
+const maxIdempotentBody = 1024 * 1024
+
+// IdempotencyRecord is a stored idempotent request and, once completed, its response
+type IdempotencyRecord struct {
+	Fingerprint string
+	Completed   bool
+	StatusCode  int
+	Header      http.Header
+	Body        []byte
+}
+
+// IdempotencyStore persists idempotency keys
+type IdempotencyStore interface {
+	// Reserve claims key for a new request. It returns nil if the caller now
+	// owns the key, or the existing record if the key is already in use.
+	// Reservations that are never completed lapse after lockTimeout.
+	Reserve(ctx context.Context, key, fingerprint string, lockTimeout time.Duration) (*IdempotencyRecord, error)
+	// Complete stores the response for a reserved key, keeping it for ttl
+	Complete(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) error
+	// Release drops a reservation so the request can be retried
+	Release(ctx context.Context, key string) error
+}
+
+// IdempotencyConfig configures the idempotency middleware
+type IdempotencyConfig struct {
+	Store       IdempotencyStore
+	TTL         time.Duration // how long completed responses are replayed
+	LockTimeout time.Duration // how long an unfinished request holds its key
+}
+
+// skippedReplayHeaders lists response headers that are not stored for replay
+var skippedReplayHeaders = map[string]bool{
+	"Set-Cookie":            true,
+	"X-Request-Id":          true,
+	"X-Ratelimit-Limit":     true,
+	"X-Ratelimit-Remaining": true,
+	"X-Ratelimit-Reset":     true,
+	"Retry-After":           true,
+	"Content-Length":        true,
+	"Date":                  true,
+}
+
+// Idempotency middleware that honors the Idempotency-Key header on POST,
+// PATCH and DELETE. The first response for a key is stored and replayed for
+// retries; reusing a key with a different request body is a conflict. Keys
+// are scoped per user, so the middleware must run after AuthRequired.
+func Idempotency(config IdempotencyConfig) gin.HandlerFunc {
+	if config.TTL == 0 {
+		config.TTL = 24 * time.Hour
+	}
+	if config.LockTimeout == 0 {
+		config.LockTimeout = time.Minute
+	}
+
+	return func(c *gin.Context) {
+		method := c.Request.Method
+		if method != http.MethodPost && method != http.MethodPatch && method != http.MethodDelete {
+			c.Next()
+			return
+		}
+
+		clientKey := c.GetHeader(IdempotencyKeyHeader)
+		if clientKey == "" {
+			c.Next()
+			return
+		}
+		if !validIdempotencyKey(clientKey) {
+			writeError(c, ValidationError("Idempotency-Key must be 1-255 printable ASCII characters"))
+			return
+		}
+
+		if c.Request.ContentLength > maxIdempotentBody {
+			c.Next()
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			body, err = io.ReadAll(io.LimitReader(c.Request.Body, maxIdempotentBody+1))
+			if err != nil {
+				writeError(c, ValidationError("Failed to read request body"))
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+			if len(body) > maxIdempotentBody {
+				c.Next()
+				return
+			}
+		}
+
+		scope := "ip:" + c.ClientIP()
+		if userID, ok := GetUserID(c); ok {
+			scope = "user:" + strconv.Itoa(userID)
+		}
+		key := scope + ":" + clientKey
+		fingerprint := requestFingerprint(method, c.Request.URL.Path, body)
+
+		existing, err := config.Store.Reserve(c.Request.Context(), key, fingerprint, config.LockTimeout)
+		if err != nil {
+			// Fail open: losing deduplication is better than refusing writes
+			log.Printf("Idempotency store error: %v", err)
+			c.Next()
+			return
+		}
+
+		if existing != nil {
+			switch {
+			case existing.Fingerprint != fingerprint:
+				writeError(c, ConflictError("Idempotency-Key was already used with a different request"))
+			case !existing.Completed:
+				c.Header("Retry-After", "1")
+				writeError(c, ConflictError("A request with this Idempotency-Key is still being processed"))
+			default:
+				replayResponse(c, existing)
+			}
+			return
+		}
+
+		responseBody := &bytes.Buffer{}
+		writer := &responseWriter{
+			ResponseWriter: c.Writer,
+			body:           responseBody,
+		}
+		c.Writer = writer
+
+		c.Next()
+
+		// Server errors are not cached so the client can retry them, and
+		// streamed responses cannot be replayed
+		status := c.Writer.Status()
+		if status >= 500 || !writer.complete() {
+			if err := config.Store.Release(context.WithoutCancel(c.Request.Context()), key); err != nil {
+				log.Printf("Failed to release idempotency key: %v", err)
+			}
+			return
+		}
+
+		record := IdempotencyRecord{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			StatusCode:  status,
+			Header:      make(http.Header),
+			Body:        responseBody.Bytes(),
+		}
+		for name, values := range c.Writer.Header() {
+			if !skippedReplayHeaders[http.CanonicalHeaderKey(name)] {
+				record.Header[name] = values
+			}
+		}
+
+		if err := config.Store.Complete(context.WithoutCancel(c.Request.Context()), key, record, config.TTL); err != nil {
+			log.Printf("Failed to store idempotent response: %v", err)
+		}
+	}
+}
+
+// replayResponse writes a stored response and stops the chain
+func replayResponse(c *gin.Context, record *IdempotencyRecord) {
+	header := c.Writer.Header()
+	for name, values := range record.Header {
+		header[name] = values
+	}
+	header.Set("Idempotent-Replayed", "true")
+
+	c.Status(record.StatusCode)
+	if len(record.Body) > 0 {
+		c.Writer.Write(record.Body)
+	}
+	c.Abort()
+}
+
+// requestFingerprint hashes the parts of a request that must match for a replay
+func requestFingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// validIdempotencyKey checks a client key is printable ASCII and reasonably short
+func validIdempotencyKey(key string) bool {
+	if len(key) == 0 || len(key) > 255 {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// memoryIdempotencyEntry is a record with its expiry
+type memoryIdempotencyEntry struct {
+	record    IdempotencyRecord
+	expiresAt time.Time
+}
+
+// MemoryIdempotencyStore keeps idempotency keys in process memory
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryIdempotencyEntry
+}
+
+// NewMemoryIdempotencyStore creates an in-memory idempotency store
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{entries: make(map[string]memoryIdempotencyEntry)}
+}
+
+// Reserve claims key unless an unexpired record exists
+func (s *MemoryIdempotencyStore) Reserve(_ context.Context, key, fingerprint string, lockTimeout time.Duration) (*IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if e, ok := s.entries[key]; ok && now.Before(e.expiresAt) {
+		record := e.record
+		return &record, nil
+	}
+
+	s.entries[key] = memoryIdempotencyEntry{
+		record:    IdempotencyRecord{Fingerprint: fingerprint},
+		expiresAt: now.Add(lockTimeout),
+	}
+
+	for k, e := range s.entries {
+		if now.After(e.expiresAt) {
+			delete(s.entries, k)
+		}
+	}
+	return nil, nil
+}
+
+// Complete stores the response for key
+func (s *MemoryIdempotencyStore) Complete(_ context.Context, key string, record IdempotencyRecord, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = memoryIdempotencyEntry{record: record, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+// Release drops key
+func (s *MemoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// SQLIdempotencyStore keeps idempotency keys in the idempotency_keys table so
+// they are shared across replicas and survive restarts
+type SQLIdempotencyStore struct {
+	db *sql.DB
+}
+
+// NewSQLIdempotencyStore creates a database-backed idempotency store
+func NewSQLIdempotencyStore(db *sql.DB) *SQLIdempotencyStore {
+	return &SQLIdempotencyStore{db: db}
+}
+
+// Reserve claims key, taking over an expired record if there is one
+func (s *SQLIdempotencyStore) Reserve(ctx context.Context, key, fingerprint string, lockTimeout time.Duration) (*IdempotencyRecord, error) {
+	var reserved string
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO idempotency_keys (key, fingerprint, expires_at)
+		VALUES ($1, $2, NOW() + $3 * INTERVAL '1 millisecond')
+		ON CONFLICT (key) DO UPDATE
+			SET fingerprint = EXCLUDED.fingerprint, completed = false, status_code = NULL,
+				headers = NULL, body = NULL, created_at = NOW(), expires_at = EXCLUDED.expires_at
+			WHERE idempotency_keys.expires_at < NOW()
+		RETURNING key
+	`, key, fingerprint, lockTimeout.Milliseconds()).Scan(&reserved)
+	if err == nil {
+		return nil, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	var record IdempotencyRecord
+	var status sql.NullInt64
+	var headers []byte
+	err = s.db.QueryRowContext(ctx, `
+		SELECT fingerprint, completed, status_code, headers, body
+		FROM idempotency_keys
+		WHERE key = $1
+	`, key).Scan(&record.Fingerprint, &record.Completed, &status, &headers, &record.Body)
+	if err == sql.ErrNoRows {
+		// Released between our insert and select; let the caller proceed
+		// without deduplication rather than loop
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	record.StatusCode = int(status.Int64)
+	if len(headers) > 0 {
+		if err := json.Unmarshal(headers, &record.Header); err != nil {
+			return nil, err
+		}
+	}
+	return &record, nil
+}
+
+// Complete stores the response for key
+func (s *SQLIdempotencyStore) Complete(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) error {
+	header := record.Header
+	if header == nil {
+		header = http.Header{}
+	}
+	headers, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE idempotency_keys
+		SET completed = true, status_code = $1, headers = $2, body = $3,
+			expires_at = NOW() + $4 * INTERVAL '1 millisecond'
+		WHERE key = $5
+	`, record.StatusCode, headers, record.Body, ttl.Milliseconds(), key)
+	return err
+}
+
+// Release drops key
+func (s *SQLIdempotencyStore) Release(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE key = $1", key)
+	return err
+}
+
+// PurgeExpired deletes expired keys and returns how many were removed
+func (s *SQLIdempotencyStore) PurgeExpired(ctx context.Context) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE expires_at < NOW()")
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
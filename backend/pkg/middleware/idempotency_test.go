@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// idempotentRouter serves POST /tasks through the idempotency middleware,
+// answering with handle and counting the calls that reach it
+func idempotentRouter(handle gin.HandlerFunc) (*gin.Engine, *atomic.Int32) {
+	gin.SetMode(gin.TestMode)
+	calls := &atomic.Int32{}
+	router := gin.New()
+	router.Use(Idempotency(IdempotencyConfig{Store: NewMemoryIdempotencyStore()}))
+	router.POST("/tasks", func(c *gin.Context) {
+		calls.Add(1)
+		handle(c)
+	})
+	return router, calls
+}
+
+func postTask(router http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	req.RemoteAddr = "203.0.113.1:1234"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func created(c *gin.Context) {
+	c.Header("X-Request-Id", "req-1")
+	c.Header("Location", "/tasks/1")
+	c.JSON(http.StatusCreated, gin.H{"id": 1})
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	router, calls := idempotentRouter(created)
+
+	first := postTask(router, "key-1", `{"title":"Fix sink"}`)
+	second := postTask(router, "key-1", `{"title":"Fix sink"}`)
+
+	if calls.Load() != 1 {
+		t.Fatalf("handler ran %d times, want once", calls.Load())
+	}
+	if second.Code != first.Code || second.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %s, want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" || first.Header().Get("Idempotent-Replayed") != "" {
+		t.Error("only the replayed response should carry Idempotent-Replayed")
+	}
+	if second.Header().Get("Location") != "/tasks/1" {
+		t.Errorf("replayed Location = %q, want /tasks/1", second.Header().Get("Location"))
+	}
+	if second.Header().Get("X-Request-Id") != "" {
+		t.Error("replay repeated the original request ID")
+	}
+
+	// A new key, or no key, runs the handler again
+	postTask(router, "key-2", `{"title":"Fix sink"}`)
+	postTask(router, "", `{"title":"Fix sink"}`)
+	if calls.Load() != 3 {
+		t.Errorf("handler ran %d times, want 3", calls.Load())
+	}
+}
+
+func TestIdempotencyConflict(t *testing.T) {
+	router, calls := idempotentRouter(created)
+
+	postTask(router, "key-1", `{"title":"Fix sink"}`)
+	w := postTask(router, "key-1", `{"title":"Paint fence"}`)
+	if w.Code != http.StatusConflict {
+		t.Errorf("reused key with a different body = %d, want 409", w.Code)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("handler ran %d times, want once", calls.Load())
+	}
+}
+
+func TestIdempotencyInFlight(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	router, calls := idempotentRouter(func(c *gin.Context) {
+		close(entered)
+		<-release
+		created(c)
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- postTask(router, "key-1", `{"title":"Fix sink"}`) }()
+	<-entered
+
+	w := postTask(router, "key-1", `{"title":"Fix sink"}`)
+	if w.Code != http.StatusConflict || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("request during the first = %d, Retry-After %q, want 409 and 1", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	close(release)
+	if first := <-done; first.Code != http.StatusCreated {
+		t.Errorf("first request = %d, want 201", first.Code)
+	}
+	if w := postTask(router, "key-1", `{"title":"Fix sink"}`); w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("retry after the first finished was not replayed")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("handler ran %d times, want once", calls.Load())
+	}
+}
+
+func TestIdempotencyReleasesOnServerError(t *testing.T) {
+	fail := true
+	router, calls := idempotentRouter(func(c *gin.Context) {
+		if fail {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database unavailable"})
+			return
+		}
+		created(c)
+	})
+
+	if w := postTask(router, "key-1", `{"title":"Fix sink"}`); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("first request = %d, want 503", w.Code)
+	}
+	fail = false
+	w := postTask(router, "key-1", `{"title":"Fix sink"}`)
+	if w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("retry after a server error = %d, replayed %q, want a fresh 201", w.Code, w.Header().Get("Idempotent-Replayed"))
+	}
+	if calls.Load() != 2 {
+		t.Errorf("handler ran %d times, want twice", calls.Load())
+	}
+}
+
+func TestIdempotencyClientErrorsAreReplayed(t *testing.T) {
+	router, calls := idempotentRouter(func(c *gin.Context) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title is required"})
+	})
+
+	postTask(router, "key-1", `{}`)
+	if w := postTask(router, "key-1", `{}`); w.Code != http.StatusBadRequest || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("retry of a 400 = %d, replayed %q, want the stored 400", w.Code, w.Header().Get("Idempotent-Replayed"))
+	}
+	if calls.Load() != 1 {
+		t.Errorf("handler ran %d times, want once", calls.Load())
+	}
+}
+
+func TestIdempotencyInvalidKey(t *testing.T) {
+	router, calls := idempotentRouter(created)
+
+	for _, key := range []string{"has space", strings.Repeat("k", 256), "tab\tkey"} {
+		if w := postTask(router, key, `{}`); w.Code != http.StatusBadRequest {
+			t.Errorf("key %q = %d, want 400", key, w.Code)
+		}
+	}
+	if calls.Load() != 0 {
+		t.Errorf("handler ran %d times for invalid keys", calls.Load())
+	}
+}
@@ -2,58 +2,92 @@ package middleware
 
 import (
 	"bytes"
-	"encoding/json"
-	"io"
-	"log"
+	"context"
+	"log/slog"
+	"math/rand"
+	"net/url"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"go.opentelemetry.io/otel"
+	"github.com/myideascope/HomeGenie/backend/pkg/logging"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 )
 
-// responseWriter wraps gin.ResponseWriter to capture response body
-type responseWriter struct {
-	gin.ResponseWriter
-	body *bytes.Buffer
-}
-
-func (w responseWriter) Write(b []byte) (int, error) {
-	w.body.Write(b)
-	return w.ResponseWriter.Write(b)
+// LoggerConfig configures the request logging middleware
+type LoggerConfig struct {
+	// Logger receives request logs; defaults to slog.Default()
+	Logger *slog.Logger
+	// SampleRates maps route templates (e.g. "/api/v1/tasks") to the fraction
+	// of successful requests that are logged. Requests that fail with a 4xx or
+	// 5xx status are always logged.
+	SampleRates map[string]float64
+	// DefaultSampleRate applies to routes without an entry in SampleRates.
+	// Zero means log every request.
+	DefaultSampleRate float64
+	// Redactor masks secrets and PII in logged bodies, queries and headers;
+	// defaults to logging.DefaultRedactionConfig()
+	Redactor *logging.Redactor
+	// LogHeaders adds (redacted) request headers to each entry
+	LogHeaders bool
+	// MaxBodyBytes bounds how much of each request and response body is
+	// captured for logging; defaults to 10KB
+	MaxBodyBytes int
 }
 
 // Logger middleware that logs HTTP requests and responses with OpenTelemetry integration
 func Logger() gin.HandlerFunc {
+	return LoggerWithConfig(LoggerConfig{})
+}
+
+// LoggerWithConfig returns the request logging middleware with custom settings
+func LoggerWithConfig(config LoggerConfig) gin.HandlerFunc {
+	if config.Redactor == nil {
+		config.Redactor = logging.NewRedactor(logging.DefaultRedactionConfig())
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = 10240
+	}
+
 	return func(c *gin.Context) {
 		start := time.Now()
 		
 		// Get request ID from context
 		requestID := getRequestID(c)
 		
-		// Capture request body for logging (be careful with large payloads)
+		// WebSocket upgrades are logged but their bodies are never captured
+		websocket := c.IsWebsocket()
+
+		// Capture a bounded prefix of the request body for logging
 		var requestBody []byte
-		if c.Request.Body != nil && c.Request.ContentLength > 0 && c.Request.ContentLength < 1024*1024 { // Only log bodies < 1MB
-			requestBody, _ = io.ReadAll(c.Request.Body)
-			c.Request.Body = io.NopCloser(bytes.NewBuffer(requestBody))
+		var requestTruncated bool
+		if !websocket {
+			requestBody, requestTruncated = captureRequestBody(c.Request, config.MaxBodyBytes)
 		}
 
-		// Wrap response writer to capture response body
+		// Wrap response writer to capture a bounded prefix of the response body
 		responseBody := &bytes.Buffer{}
-		writer := &responseWriter{
-			ResponseWriter: c.Writer,
-			body:          responseBody,
+		var writer *responseWriter
+		if !websocket {
+			writer = &responseWriter{
+				ResponseWriter: c.Writer,
+				body:           responseBody,
+				limit:          config.MaxBodyBytes,
+			}
+			c.Writer = writer
 		}
-		c.Writer = writer
 
 		// Get OpenTelemetry span from context
 		span := trace.SpanFromContext(c.Request.Context())
+
+		// Make a request-scoped logger available to handlers
+		logger := requestLogger(config.Logger, requestID, span.SpanContext())
+		c.Request = c.Request.WithContext(logging.WithLogger(c.Request.Context(), logger))
 		
 		// Add request attributes to span
 		span.SetAttributes(
 			attribute.String("http.method", c.Request.Method),
-			attribute.String("http.url", c.Request.URL.String()),
+			attribute.String("http.url", redactedURL(config.Redactor, c.Request.URL)),
 			attribute.String("http.user_agent", c.Request.UserAgent()),
 			attribute.String("http.remote_addr", c.ClientIP()),
 			attribute.String("request.id", requestID),
@@ -78,7 +112,7 @@ func Logger() gin.HandlerFunc {
 			RequestID:      requestID,
 			Method:         c.Request.Method,
 			Path:           c.Request.URL.Path,
-			Query:          c.Request.URL.RawQuery,
+			Query:          config.Redactor.Query(c.Request.URL.RawQuery),
 			StatusCode:     c.Writer.Status(),
 			Latency:        latency,
 			ClientIP:       c.ClientIP(),
@@ -90,18 +124,30 @@ func Logger() gin.HandlerFunc {
 			SpanID:         span.SpanContext().SpanID().String(),
 		}
 
-		// Add request body to log if present and not sensitive
-		if len(requestBody) > 0 && !isSensitiveEndpoint(c.Request.URL.Path) {
-			logEntry.RequestBody = string(requestBody)
+		// Bodies are only logged with sensitive fields masked, and never for
+		// endpoints that carry credentials
+		skipBody := config.Redactor.SkipBody(c.Request.URL.Path)
+
+		// Add request body to log if present and not sensitive. A truncated
+		// body cannot be parsed for redaction, so only the marker is logged.
+		logEntry.RequestBodyTruncated = requestTruncated
+		if len(requestBody) > 0 && !skipBody {
+			logEntry.RequestBody = config.Redactor.JSON(requestBody)
 		}
 
-		// Add response body to log if it's JSON and not too large
-		if responseBody.Len() > 0 && responseBody.Len() < 10240 { // < 10KB
+		// Add response body to log if it's JSON and was captured in full
+		if writer != nil && writer.truncated {
+			logEntry.ResponseBodyTruncated = true
+		} else if writer != nil && writer.complete() && !skipBody && responseBody.Len() > 0 {
 			if isJSONResponse(c.Writer.Header().Get("Content-Type")) {
-				logEntry.ResponseBody = responseBody.String()
+				logEntry.ResponseBody = config.Redactor.JSON(responseBody.Bytes())
 			}
 		}
 
+		if config.LogHeaders {
+			logEntry.Headers = config.Redactor.Headers(c.Request.Header)
+		}
+
 		// Add error information if present
 		if len(c.Errors) > 0 {
 			logEntry.Errors = make([]string, len(c.Errors))
@@ -110,74 +156,132 @@ func Logger() gin.HandlerFunc {
 			}
 		}
 
+		// Successful requests may be sampled; failures are always logged
+		if logEntry.StatusCode < 400 && !sampled(config, c.FullPath()) {
+			return
+		}
+
 		// Log the entry
-		logHTTPRequest(logEntry)
+		logHTTPRequest(c.Request.Context(), logger, logEntry)
+	}
+}
+
+// GetLogger returns the request-scoped logger set up by the logging middleware
+func GetLogger(c *gin.Context) *slog.Logger {
+	return logging.FromContext(c.Request.Context())
+}
+
+// requestLogger derives a logger carrying the request and trace identifiers
+func requestLogger(base *slog.Logger, requestID string, sc trace.SpanContext) *slog.Logger {
+	if base == nil {
+		base = slog.Default()
+	}
+
+	logger := base.With(slog.String("request_id", requestID))
+	if sc.IsValid() {
+		logger = logger.With(
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
 	}
+	return logger
+}
+
+// sampled decides whether a successful request on route is logged
+func sampled(config LoggerConfig, route string) bool {
+	rate, ok := config.SampleRates[route]
+	if !ok {
+		rate = config.DefaultSampleRate
+		if rate == 0 {
+			return true
+		}
+	}
+	return rate >= 1 || (rate > 0 && rand.Float64() < rate)
 }
 
 // LogEntry represents a structured log entry for HTTP requests
 type LogEntry struct {
-	RequestID    string        `json:"request_id"`
-	Method       string        `json:"method"`
-	Path         string        `json:"path"`
-	Query        string        `json:"query,omitempty"`
-	StatusCode   int           `json:"status_code"`
-	Latency      time.Duration `json:"latency_ns"`
-	LatencyMs    float64       `json:"latency_ms"`
-	ClientIP     string        `json:"client_ip"`
-	UserAgent    string        `json:"user_agent"`
-	RequestSize  int64         `json:"request_size"`
-	ResponseSize int64         `json:"response_size"`
-	Timestamp    time.Time     `json:"timestamp"`
-	TraceID      string        `json:"trace_id,omitempty"`
-	SpanID       string        `json:"span_id,omitempty"`
-	RequestBody  string        `json:"request_body,omitempty"`
-	ResponseBody string        `json:"response_body,omitempty"`
-	Errors       []string      `json:"errors,omitempty"`
+	RequestID    string            `json:"request_id"`
+	Method       string            `json:"method"`
+	Path         string            `json:"path"`
+	Query        string            `json:"query,omitempty"`
+	StatusCode   int               `json:"status_code"`
+	Latency      time.Duration     `json:"latency_ns"`
+	LatencyMs    float64           `json:"latency_ms"`
+	ClientIP     string            `json:"client_ip"`
+	UserAgent    string            `json:"user_agent"`
+	RequestSize  int64             `json:"request_size"`
+	ResponseSize int64             `json:"response_size"`
+	Timestamp    time.Time         `json:"timestamp"`
+	TraceID      string            `json:"trace_id,omitempty"`
+	SpanID       string            `json:"span_id,omitempty"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	RequestBody  string            `json:"request_body,omitempty"`
+	ResponseBody string            `json:"response_body,omitempty"`
+	Errors       []string          `json:"errors,omitempty"`
+
+	RequestBodyTruncated  bool `json:"request_body_truncated,omitempty"`
+	ResponseBodyTruncated bool `json:"response_body_truncated,omitempty"`
 }
 
-// logHTTPRequest logs an HTTP request entry as structured JSON
-func logHTTPRequest(entry LogEntry) {
+// logHTTPRequest logs an HTTP request entry at a level based on its status code.
+// Request and trace IDs are already attached to logger.
+func logHTTPRequest(ctx context.Context, logger *slog.Logger, entry LogEntry) {
 	// Convert latency to milliseconds for easier reading
 	entry.LatencyMs = float64(entry.Latency.Nanoseconds()) / 1e6
 
-	// Marshal to JSON
-	logData, err := json.Marshal(entry)
-	if err != nil {
-		log.Printf("Failed to marshal log entry: %v", err)
-		return
+	attrs := []slog.Attr{
+		slog.String("method", entry.Method),
+		slog.String("path", entry.Path),
+		slog.Int("status_code", entry.StatusCode),
+		slog.Int64("latency_ns", int64(entry.Latency)),
+		slog.Float64("latency_ms", entry.LatencyMs),
+		slog.String("client_ip", entry.ClientIP),
+		slog.String("user_agent", entry.UserAgent),
+		slog.Int64("request_size", entry.RequestSize),
+		slog.Int64("response_size", entry.ResponseSize),
+		slog.Time("request_time", entry.Timestamp),
+	}
+	if entry.Query != "" {
+		attrs = append(attrs, slog.String("query", entry.Query))
+	}
+	if len(entry.Headers) > 0 {
+		attrs = append(attrs, slog.Any("headers", entry.Headers))
+	}
+	if entry.RequestBody != "" {
+		attrs = append(attrs, slog.String("request_body", entry.RequestBody))
+	}
+	if entry.ResponseBody != "" {
+		attrs = append(attrs, slog.String("response_body", entry.ResponseBody))
+	}
+	if entry.RequestBodyTruncated {
+		attrs = append(attrs, slog.Bool("request_body_truncated", true))
+	}
+	if entry.ResponseBodyTruncated {
+		attrs = append(attrs, slog.Bool("response_body_truncated", true))
+	}
+	if len(entry.Errors) > 0 {
+		attrs = append(attrs, slog.Any("errors", entry.Errors))
 	}
 
 	// Determine log level based on status code
-	statusCode := entry.StatusCode
+	level := slog.LevelInfo
 	switch {
-	case statusCode >= 500:
-		log.Printf("ERROR: %s", string(logData))
-	case statusCode >= 400:
-		log.Printf("WARN: %s", string(logData))
-	case statusCode >= 300:
-		log.Printf("INFO: %s", string(logData))
-	default:
-		log.Printf("INFO: %s", string(logData))
+	case entry.StatusCode >= 500:
+		level = slog.LevelError
+	case entry.StatusCode >= 400:
+		level = slog.LevelWarn
 	}
-}
 
-// isSensitiveEndpoint checks if an endpoint might contain sensitive data
-func isSensitiveEndpoint(path string) bool {
-	sensitiveEndpoints := []string{
-		"/api/v1/auth/login",
-		"/api/v1/auth/register",
-		"/api/v1/auth/change-password",
-		"/api/v1/auth/reset-password",
-		"/api/v1/auth/forgot-password",
-	}
+	logger.LogAttrs(ctx, level, "http request", attrs...)
+}
 
-	for _, endpoint := range sensitiveEndpoints {
-		if path == endpoint {
-			return true
-		}
-	}
-	return false
+// redactedURL renders u for span attributes with sensitive query values masked
+func redactedURL(r *logging.Redactor, u *url.URL) string {
+	masked := *u
+	masked.RawQuery = r.Query(u.RawQuery)
+	masked.User = nil
+	return masked.String()
 }
 
 // isJSONResponse checks if the response content type is JSON
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+	"github.com/myideascope/HomeGenie/backend/pkg/logging"
+)
+
+func TestLoggerRedactsSecrets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var logs bytes.Buffer
+	router := gin.New()
+	router.Use(LoggerWithConfig(LoggerConfig{
+		Logger:     slog.New(slog.NewJSONHandler(&logs, nil)),
+		LogHeaders: true,
+	}))
+	phone := "+1-555-0100"
+	router.POST("/api/v1/auth/refresh", func(c *gin.Context) {
+		c.JSON(http.StatusOK, database.LoginResponse{
+			User:         database.User{ID: 1, Email: "ana@example.com", Phone: &phone},
+			Token:        "response-access-token",
+			RefreshToken: "response-refresh-token",
+		})
+	})
+
+	secrets := []string{
+		"Bearer header-access-token", "session-cookie-value", "query-token-value",
+		"body-password-value", "body-refresh-token", "+1-555-0199", "42 Elm Street",
+		"response-access-token", "response-refresh-token", "ana@example.com", "+1-555-0100",
+	}
+	body := `{"password":"body-password-value","refreshToken":"body-refresh-token",` +
+		`"phone":"+1-555-0199","address":"42 Elm Street","deviceName":"kitchen tablet"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh?token=query-token-value&page=2",
+		strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer header-access-token")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "session-cookie-value"})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "response-refresh-token") {
+		t.Fatalf("response = %d %s, want the handler's unredacted body", w.Code, w.Body)
+	}
+
+	output := logs.String()
+	for _, secret := range secrets {
+		if strings.Contains(output, secret) {
+			t.Errorf("log contains %q: %s", secret, output)
+		}
+	}
+
+	// The entry is still logged, with the harmless fields intact
+	var entry map[string]interface{}
+	if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+		t.Fatalf("log is not one JSON entry: %v: %s", err, output)
+	}
+	if entry["msg"] != "http request" || entry["path"] != "/api/v1/auth/refresh" {
+		t.Errorf("log entry = %v, want the request", entry)
+	}
+	for field, want := range map[string]string{
+		"request_body":  "kitchen tablet",
+		"query":         "page=2",
+		"response_body": logging.Redacted,
+	} {
+		if got, _ := entry[field].(string); !strings.Contains(got, want) {
+			t.Errorf("log %s = %q, want it to contain %q", field, got, want)
+		}
+	}
+}
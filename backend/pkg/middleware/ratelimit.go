@@ -0,0 +1,234 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit describes a token bucket: Requests tokens are added every Period,
+// up to a maximum of Burst tokens
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// perSecond returns the refill rate in tokens per second
+func (r RateLimit) perSecond() float64 {
+	return float64(r.Requests) / r.Period.Seconds()
+}
+
+// validate reports a limit that would divide by zero or never refill
+func (r RateLimit) validate() error {
+	if r.Requests <= 0 || r.Period <= 0 {
+		return fmt.Errorf("requests %d per %v: both must be positive", r.Requests, r.Period)
+	}
+	return nil
+}
+
+// idle returns how long a bucket must go untouched before it is certainly
+// full again: the time to refill from empty, and at least an hour
+func (r RateLimit) idle() time.Duration {
+	idle := max(time.Hour, r.Period)
+	return max(idle, secondsToDuration(float64(r.capacity())/r.perSecond()))
+}
+
+// capacity returns the bucket size, defaulting to Requests when Burst is unset
+func (r RateLimit) capacity() int {
+	if r.Burst > 0 {
+		return r.Burst
+	}
+	return r.Requests
+}
+
+// RateLimitResult is the outcome of taking a token from a bucket
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration // until the next token is available
+	ResetAfter time.Duration // until the bucket is full again
+}
+
+// RateLimitStore holds token buckets
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error)
+}
+
+// RateLimitConfig configures the rate limiting middleware
+type RateLimitConfig struct {
+	Enabled bool
+	Default RateLimit
+	// Routes overrides Default for specific route templates, e.g.
+	// "/api/v1/auth/login". Each override has its own buckets.
+	Routes map[string]RateLimit
+	Store  RateLimitStore
+}
+
+// RateLimitConfigFromEnv builds a config from RATE_LIMIT_ENABLED and
+// MAX_REQUESTS_PER_MINUTE, with stricter limits on credential endpoints
+func RateLimitConfigFromEnv(store RateLimitStore) RateLimitConfig {
+	perMinute, err := strconv.Atoi(os.Getenv("MAX_REQUESTS_PER_MINUTE"))
+	if err != nil || perMinute <= 0 {
+		perMinute = 60
+	}
+	enabled, _ := strconv.ParseBool(os.Getenv("RATE_LIMIT_ENABLED"))
+
+	strict := RateLimit{Requests: 5, Period: time.Minute}
+	return RateLimitConfig{
+		Enabled: enabled,
+		Default: RateLimit{Requests: perMinute, Period: time.Minute},
+		Routes: map[string]RateLimit{
+			"/api/v1/auth/login":           strict,
+			"/api/v1/auth/register":        strict,
+			"/api/v1/auth/forgot-password": strict,
+			"/api/v1/auth/reset-password":  strict,
+		},
+		Store: store,
+	}
+}
+
+// RateLimiter middleware that limits requests per authenticated user, or per
+// client IP for anonymous requests. It must run after AuthRequired or
+// OptionalAuth for user buckets to apply. It panics if a limit does not have
+// positive Requests and Period.
+func RateLimiter(config RateLimitConfig) gin.HandlerFunc {
+	if !config.Enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+	if err := config.Default.validate(); err != nil {
+		panic("middleware: default rate limit: " + err.Error())
+	}
+	for route, limit := range config.Routes {
+		if err := limit.validate(); err != nil {
+			panic("middleware: rate limit for " + route + ": " + err.Error())
+		}
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryRateLimitStore()
+	}
+
+	return func(c *gin.Context) {
+		limit, scope := config.Default, "default"
+		if override, ok := config.Routes[c.FullPath()]; ok {
+			limit, scope = override, c.FullPath()
+		}
+
+		identity := "ip:" + c.ClientIP()
+		if userID, ok := GetUserID(c); ok {
+			identity = "user:" + strconv.Itoa(userID)
+		}
+
+		result, err := config.Store.Take(c.Request.Context(), "ratelimit:"+scope+":"+identity, limit)
+		if err != nil {
+			// Fail open: an unavailable store must not take the API down
+			log.Printf("Rate limit store error: %v", err)
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		header.Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		header.Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+		if !result.Allowed {
+			header.Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			writeError(c, NewAPIError(http.StatusTooManyRequests, CodeRateLimited, "Too many requests").
+				WithDetails(gin.H{"retry_after_seconds": ceilSeconds(result.RetryAfter)}))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ceilSeconds rounds a duration up to whole seconds
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// bucket is an in-memory token bucket
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	idle    time.Duration // after which the bucket is full and can be dropped
+}
+
+// MemoryRateLimitStore keeps buckets in process memory. It is suitable for
+// single-replica deployments; use RedisRateLimitStore when running several.
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+	swept   time.Time
+}
+
+// NewMemoryRateLimitStore creates an in-memory rate limit store
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Take removes a token from the bucket for key if one is available
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, limit RateLimit) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	rate := limit.perSecond()
+	capacity := float64(limit.capacity())
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now, idle: limit.idle()}
+		s.buckets[key] = b
+	} else {
+		b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updated).Seconds()*rate)
+		b.updated = now
+		b.idle = limit.idle()
+	}
+
+	result := RateLimitResult{Limit: limit.capacity()}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.ResetAfter = secondsToDuration((capacity - b.tokens) / rate)
+
+	s.sweep(now)
+	return result, nil
+}
+
+// sweep drops buckets that have refilled completely, at most once a minute
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.swept) < time.Minute {
+		return
+	}
+	s.swept = now
+
+	for key, b := range s.buckets {
+		if now.Sub(b.updated) > b.idle {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// secondsToDuration converts fractional seconds to a duration
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
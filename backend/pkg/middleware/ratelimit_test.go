@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeClock is a manually advanced time source for stores under test
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestRateLimitStore() (*MemoryRateLimitStore, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s := NewMemoryRateLimitStore()
+	s.now = clock.now
+	return s, clock
+}
+
+func TestMemoryRateLimitStoreBurst(t *testing.T) {
+	s, _ := newTestRateLimitStore()
+	limit := RateLimit{Requests: 2, Period: time.Second, Burst: 5}
+
+	for i := 4; i >= 0; i-- {
+		result, _ := s.Take(context.Background(), "k", limit)
+		if !result.Allowed || result.Remaining != i || result.Limit != 5 {
+			t.Fatalf("Take() = %+v, want allowed with %d remaining of 5", result, i)
+		}
+	}
+	result, _ := s.Take(context.Background(), "k", limit)
+	if result.Allowed {
+		t.Fatal("Take() beyond the burst was allowed")
+	}
+	if result.RetryAfter != 500*time.Millisecond || result.ResetAfter != 2500*time.Millisecond {
+		t.Errorf("Take() retry after %v, reset after %v, want 500ms and 2.5s", result.RetryAfter, result.ResetAfter)
+	}
+
+	// Buckets are independent per key
+	if result, _ := s.Take(context.Background(), "other", limit); !result.Allowed {
+		t.Error("Take() on a fresh key was refused")
+	}
+}
+
+func TestMemoryRateLimitStoreRefill(t *testing.T) {
+	tests := []struct {
+		name    string
+		wait    time.Duration
+		allowed int
+	}{
+		{"no wait", 0, 0},
+		{"under one token", 400 * time.Millisecond, 0},
+		{"one token", 500 * time.Millisecond, 1},
+		{"two tokens", time.Second, 2},
+		{"capped at burst", time.Hour, 3},
+	}
+	for _, tt := range tests {
+		s, clock := newTestRateLimitStore()
+		limit := RateLimit{Requests: 2, Period: time.Second, Burst: 3}
+		for i := 0; i < 3; i++ {
+			s.Take(context.Background(), "k", limit)
+		}
+
+		clock.advance(tt.wait)
+		allowed := 0
+		for i := 0; i < 10; i++ {
+			if result, _ := s.Take(context.Background(), "k", limit); result.Allowed {
+				allowed++
+			}
+		}
+		if allowed != tt.allowed {
+			t.Errorf("%s: %d requests allowed after refill, want %d", tt.name, allowed, tt.allowed)
+		}
+	}
+}
+
+func TestMemoryRateLimitStoreDefaultBurst(t *testing.T) {
+	s, _ := newTestRateLimitStore()
+	limit := RateLimit{Requests: 3, Period: time.Minute}
+
+	allowed := 0
+	for i := 0; i < 5; i++ {
+		if result, _ := s.Take(context.Background(), "k", limit); result.Allowed {
+			allowed++
+		}
+	}
+	if allowed != 3 {
+		t.Errorf("%d requests allowed, want Requests (3) when Burst is unset", allowed)
+	}
+}
+
+func TestMemoryRateLimitStoreSweep(t *testing.T) {
+	s, clock := newTestRateLimitStore()
+	hourly := RateLimit{Requests: 1, Period: time.Minute}
+	daily := RateLimit{Requests: 1, Period: 24 * time.Hour}
+	s.Take(context.Background(), "hourly", hourly)
+	s.Take(context.Background(), "daily", daily)
+
+	clock.advance(2 * time.Hour)
+	s.Take(context.Background(), "trigger", hourly)
+	if _, ok := s.buckets["hourly"]; ok {
+		t.Error("bucket idle for two hours was not swept")
+	}
+	if _, ok := s.buckets["daily"]; !ok {
+		t.Fatal("bucket with a daily period was swept before refilling")
+	}
+
+	// The daily bucket is still empty, so a new request must be refused
+	if result, _ := s.Take(context.Background(), "daily", daily); result.Allowed {
+		t.Error("sweeping reset a daily limit early")
+	}
+
+	clock.advance(25 * time.Hour)
+	s.Take(context.Background(), "trigger", hourly)
+	if _, ok := s.buckets["daily"]; ok {
+		t.Error("daily bucket was not swept once full")
+	}
+}
+
+func TestRateLimiterRejectsInvalidLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		config RateLimitConfig
+	}{
+		{"zero requests", RateLimitConfig{Enabled: true, Default: RateLimit{Period: time.Minute}}},
+		{"zero period", RateLimitConfig{Enabled: true, Default: RateLimit{Requests: 10}}},
+		{"negative period", RateLimitConfig{Enabled: true, Default: RateLimit{Requests: 10, Period: -time.Second}}},
+		{"invalid route", RateLimitConfig{
+			Enabled: true,
+			Default: RateLimit{Requests: 10, Period: time.Minute},
+			Routes:  map[string]RateLimit{"/api/v1/auth/login": {Requests: 0, Period: time.Minute}},
+		}},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RateLimiter(%s) did not panic", tt.name)
+				}
+			}()
+			RateLimiter(tt.config)
+		}()
+	}
+
+	// Disabled limiting ignores the limits
+	RateLimiter(RateLimitConfig{})
+}
+
+func TestRateLimiter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store, _ := newTestRateLimitStore()
+	router := gin.New()
+	router.Use(RateLimiter(RateLimitConfig{
+		Enabled: true,
+		Default: RateLimit{Requests: 10, Period: time.Minute},
+		Routes:  map[string]RateLimit{"/login": {Requests: 1, Period: time.Minute}},
+		Store:   store,
+	}))
+	router.GET("/login", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	router.GET("/tasks", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	get := func(path, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get("/login", "203.0.113.1"); w.Code != http.StatusNoContent || w.Header().Get("X-RateLimit-Limit") != "1" {
+		t.Fatalf("first login = %d with limit %q, want 204 and 1", w.Code, w.Header().Get("X-RateLimit-Limit"))
+	}
+	w := get("/login", "203.0.113.1")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "60" {
+		t.Errorf("second login = %d, Retry-After %q, want 429 and 60", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := get("/tasks", "203.0.113.1"); w.Code != http.StatusNoContent {
+		t.Errorf("route without an override = %d, want its own default bucket", w.Code)
+	}
+	if w := get("/login", "203.0.113.2"); w.Code != http.StatusNoContent {
+		t.Errorf("login from another client = %d, want 204", w.Code)
+	}
+}
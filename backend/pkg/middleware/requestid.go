@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/myideascope/HomeGenie/backend/pkg/requestid"
+)
+
+// RequestID middleware that assigns every request a correlation ID. A valid
+// incoming X-Request-ID is reused so IDs can span services; otherwise a new
+// ULID is generated. The ID is echoed in the response and stored both in the
+// gin context and the request's context.Context.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		c.Set("requestID", id)
+		c.Request = c.Request.WithContext(requestid.WithID(c.Request.Context(), id))
+		c.Header(requestid.Header, id)
+
+		c.Next()
+	}
+}
+
+// getRequestID returns the request ID assigned by RequestID, if any
+func getRequestID(c *gin.Context) string {
+	if id, exists := c.Get("requestID"); exists {
+		if s, ok := id.(string); ok {
+			return s
+		}
+	}
+	return requestid.FromContext(c.Request.Context())
+}
+
+// GetRequestID is a helper function to get the current request ID from context
+func GetRequestID(c *gin.Context) string {
+	return getRequestID(c)
+}
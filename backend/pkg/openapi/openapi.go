@@ -0,0 +1,217 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version of the OpenAPI specification documents are written against
+const Version = "3.1.0"
+
+// Document is an OpenAPI document. Only the parts HomeGenie uses are modelled.
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+
+	reflector *reflector
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Server is a base URL the API is served under
+type Server struct {
+	URL string `json:"url"`
+}
+
+// Components holds the reusable schemas and security schemes
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes how requests authenticate
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// PathItem holds the operations on one path, keyed by lowercase method
+type PathItem map[string]*OperationObject
+
+// OperationObject is a single documented operation
+type OperationObject struct {
+	OperationID string                `json:"operationId,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter is a path or query parameter
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody is a JSON request body
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response is one documented response
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType wraps the schema for one content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Operation declares an endpoint in terms of Go types. Request and Response
+// are zero values of the body types, Query a struct with form tags; any of
+// them may be nil.
+type Operation struct {
+	Method  string
+	Path    string // gin syntax, e.g. "/tasks/:id"
+	Summary string
+	Tag     string
+	Auth    bool
+
+	Query    interface{}
+	Request  interface{}
+	Response interface{}
+	// Status of the success response, http.StatusOK when zero
+	Status int
+}
+
+// New creates an empty document. ErrorBody is registered as the ApiError
+// schema used for every error response.
+func New(info Info, errorBody interface{}, servers ...Server) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Servers: servers,
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			Schemas: make(map[string]*Schema),
+			SecuritySchemes: map[string]*SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+	doc.reflector = newReflector(doc.Components.Schemas)
+	doc.reflector.named(reflect.TypeOf(errorBody), "ApiError")
+	return doc
+}
+
+// ginParam matches gin path parameters such as ":id" and "*filepath"
+var ginParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// Add documents each operation
+func (d *Document) Add(ops ...Operation) {
+	for _, op := range ops {
+		d.add(op)
+	}
+}
+
+func (d *Document) add(op Operation) {
+	path := ginParam.ReplaceAllString(op.Path, "{$1}")
+	item, ok := d.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+
+	obj := &OperationObject{
+		OperationID: operationID(op.Method, op.Path),
+		Summary:     op.Summary,
+		Responses:   make(map[string]*Response),
+	}
+	if op.Tag != "" {
+		obj.Tags = []string{op.Tag}
+	}
+	if op.Auth {
+		obj.Security = []map[string][]string{{"bearerAuth": {}}}
+	}
+
+	for _, match := range ginParam.FindAllStringSubmatch(op.Path, -1) {
+		schema := &Schema{Type: "string"}
+		if match[1] == "id" || strings.HasSuffix(match[1], "Id") {
+			schema = &Schema{Type: "integer", Minimum: float(1)}
+		}
+		obj.Parameters = append(obj.Parameters, Parameter{Name: match[1], In: "path", Required: true, Schema: schema})
+	}
+	if op.Query != nil {
+		obj.Parameters = append(obj.Parameters, d.reflector.queryParameters(reflect.TypeOf(op.Query))...)
+	}
+	if op.Request != nil {
+		obj.RequestBody = &RequestBody{
+			Required: true,
+			Content:  jsonContent(d.reflector.schema(reflect.TypeOf(op.Request))),
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := &Response{Description: http.StatusText(status)}
+	if op.Response != nil {
+		success.Content = jsonContent(d.reflector.schema(reflect.TypeOf(op.Response)))
+	}
+	obj.Responses[strconv.Itoa(status)] = success
+
+	errorResponse := func(status int) {
+		obj.Responses[strconv.Itoa(status)] = &Response{
+			Description: http.StatusText(status),
+			Content:     jsonContent(&Schema{Ref: schemaRef("ApiError")}),
+		}
+	}
+	if op.Request != nil || op.Query != nil || len(obj.Parameters) > 0 {
+		errorResponse(http.StatusBadRequest)
+	}
+	if op.Auth {
+		errorResponse(http.StatusUnauthorized)
+	}
+	if strings.Contains(op.Path, ":") {
+		errorResponse(http.StatusNotFound)
+	}
+	errorResponse(http.StatusInternalServerError)
+
+	(*item)[strings.ToLower(op.Method)] = obj
+}
+
+// operationID derives a stable ID such as "getTasksId" from method and path
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == ':' || r == '*' || r == '-' || r == '_' || r == '.'
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+func jsonContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{"application/json": {Schema: schema}}
+}
@@ -0,0 +1,339 @@
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema (draft 2020-12) as used by OpenAPI 3.1
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"` // string, or []string when nullable
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+}
+
+func schemaRef(name string) string {
+	return "#/components/schemas/" + name
+}
+
+// enum is implemented by the generated database enum types
+type enum interface {
+	EnumValues() []string
+}
+
+// typed is implemented by types whose JSON form differs from their Go kind,
+// such as decimal amounts held as integers
+type typed interface {
+	JSONSchemaType() (typ, format string)
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	enumType       = reflect.TypeOf((*enum)(nil)).Elem()
+	typedType      = reflect.TypeOf((*typed)(nil)).Elem()
+)
+
+// reflector builds schemas from Go types, registering named structs as
+// components so they are described once and referenced everywhere else
+type reflector struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newReflector(schemas map[string]*Schema) *reflector {
+	return &reflector{schemas: schemas, names: make(map[reflect.Type]string)}
+}
+
+// schema returns the schema for t, a $ref for named structs
+func (r *reflector) schema(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		return r.schema(t.Elem())
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	case t.Implements(enumType):
+		return &Schema{Type: "string", Enum: enumValues(reflect.Zero(t).Interface().(enum).EnumValues())}
+	case t.Implements(typedType):
+		typ, format := reflect.Zero(t).Interface().(typed).JSONSchemaType()
+		return &Schema{Type: typ, Format: format}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.object(t)
+		}
+		return &Schema{Ref: schemaRef(r.named(t, ""))}
+	}
+	// interface{} and anything else accepts any value
+	return &Schema{}
+}
+
+// named registers struct t as a component, under name or a name derived
+// from the type, and returns the component name
+func (r *reflector) named(t reflect.Type, name string) string {
+	if existing, ok := r.names[t]; ok {
+		return existing
+	}
+	if name == "" {
+		name = schemaName(t)
+		// Qualify same-named types from different packages, e.g. HealthResult
+		if _, taken := r.schemas[name]; taken {
+			pkg := path.Base(t.PkgPath())
+			name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+		}
+	}
+	r.names[t] = name
+	// Reserve the name before recursing so self-references terminate
+	r.schemas[name] = &Schema{}
+	*r.schemas[name] = *r.object(t)
+	return name
+}
+
+// object describes the fields of struct t, flattening embedded structs the
+// way encoding/json does
+func (r *reflector) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	r.addFields(schema, t)
+	return schema
+}
+
+func (r *reflector) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, omitempty := jsonName(field)
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				r.addFields(schema, embedded)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop, required := r.field(field)
+		if field.Type.Kind() == reflect.Pointer {
+			prop = nullable(prop)
+		}
+		schema.Properties[name] = prop
+
+		// Request fields are required when bound as such; response fields
+		// are always present unless omitted when empty
+		_, hasBinding := field.Tag.Lookup("binding")
+		if required || (!hasBinding && !omitempty && field.Type.Kind() != reflect.Pointer) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// field builds the schema for one struct field, applying its binding rules.
+// It reports whether the field is bound as required.
+func (r *reflector) field(field reflect.StructField) (*Schema, bool) {
+	schema := r.schema(field.Type)
+	rules := field.Tag.Get("binding")
+	if rules == "" {
+		return schema, false
+	}
+
+	// Constraints cannot sit beside a $ref, so copy inline primitives only
+	if schema.Ref != "" {
+		return schema, strings.Contains(","+rules+",", ",required,")
+	}
+	constrained := *schema
+
+	kind := field.Type.Kind()
+	if kind == reflect.Pointer {
+		kind = field.Type.Elem().Kind()
+	}
+	required := false
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			constrained.Format = "email"
+		case "url":
+			constrained.Format = "uri"
+		case "oneof":
+			constrained.Enum = enumValues(strings.Fields(param))
+		case "min", "max", "len", "gte", "lte", "gt", "lt":
+			applyLimit(&constrained, kind, name, param)
+		}
+	}
+	return &constrained, required
+}
+
+// applyLimit maps a size rule onto the keyword for the field's kind
+func applyLimit(s *Schema, kind reflect.Kind, rule, param string) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	size := int(n)
+
+	switch kind {
+	case reflect.String:
+		switch rule {
+		case "min", "gte":
+			s.MinLength = &size
+		case "max", "lte":
+			s.MaxLength = &size
+		case "len":
+			s.MinLength, s.MaxLength = &size, &size
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		switch rule {
+		case "min", "gte":
+			s.MinItems = &size
+		case "max", "lte":
+			s.MaxItems = &size
+		case "len":
+			s.MinItems, s.MaxItems = &size, &size
+		}
+	default:
+		switch rule {
+		case "min", "gte":
+			s.Minimum = &n
+		case "max", "lte":
+			s.Maximum = &n
+		case "gt":
+			s.ExclusiveMinimum = &n
+		case "lt":
+			s.ExclusiveMaximum = &n
+		case "len":
+			s.Minimum, s.Maximum = &n, &n
+		}
+	}
+}
+
+// queryParameters describes the form-tagged fields of a query struct
+func (r *reflector) queryParameters(t reflect.Type) []Parameter {
+	var params []Parameter
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.SplitN(field.Tag.Get("form"), ",", 2)[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		schema, required := r.field(field)
+		params = append(params, Parameter{Name: name, In: "query", Required: required, Schema: schema})
+	}
+	return params
+}
+
+// jsonName returns the json tag name and whether omitempty is set
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	name, opts, _ := strings.Cut(tag, ",")
+	return name, strings.Contains(","+opts+",", ",omitempty,")
+}
+
+// nullable allows null in addition to s. References are left as they are:
+// optional object fields are simply omitted.
+func nullable(s *Schema) *Schema {
+	typ, ok := s.Type.(string)
+	if !ok {
+		return s
+	}
+	copied := *s
+	copied.Type = []string{typ, "null"}
+	if len(s.Enum) > 0 {
+		copied.Enum = append(append([]interface{}(nil), s.Enum...), nil)
+	}
+	return &copied
+}
+
+var (
+	packagePath = regexp.MustCompile(`[\w.\-/]*/`)
+	packageName = regexp.MustCompile(`\w+\.`)
+	sliceArg    = regexp.MustCompile(`\[\](\w+)`)
+	mapArg      = regexp.MustCompile(`map\[\w+\](\w+)`)
+)
+
+// schemaName turns a Go type name into a component name. Generic
+// instantiations are flattened: APIResponse[[]database.Task] becomes
+// APIResponseTaskList.
+func schemaName(t reflect.Type) string {
+	name := t.Name()
+	if !strings.Contains(name, "[") {
+		return name
+	}
+	name = packagePath.ReplaceAllString(name, "")
+	name = packageName.ReplaceAllString(name, "")
+	name = strings.ReplaceAll(name, "interface {}", "Any")
+	name = mapArg.ReplaceAllString(name, "${1}Map")
+	name = sliceArg.ReplaceAllString(name, "${1}List")
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool {
+		return r == '[' || r == ']' || r == ',' || r == '*' || r == ' '
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+func enumValues(values []string) []interface{} {
+	enum := make([]interface{}, len(values))
+	for i, v := range values {
+		enum[i] = v
+	}
+	return enum
+}
+
+// float returns a pointer to f
+func float(f float64) *float64 {
+	return &f
+}
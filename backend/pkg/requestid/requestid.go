@@ -0,0 +1,87 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// Header is the HTTP header used to accept and propagate request IDs
+const Header = "X-Request-ID"
+
+// maxLength bounds accepted incoming IDs
+const maxLength = 128
+
+type contextKey struct{}
+
+// New generates a new request ID (a ULID, so IDs sort by creation time)
+func New() string {
+	return ulid.Make().String()
+}
+
+// Valid checks that an incoming ID is short and only uses characters that
+// are safe to echo in headers, logs and SQL comments
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-' || r == '_' || r == '.' || r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// WithID returns a copy of ctx carrying id
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID in ctx, or "" if there is none
+func FromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(contextKey{}).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// SQLComment prefixes query with a comment naming the request ID in ctx, so
+// slow query logs and pg_stat_activity can be traced back to a request
+func SQLComment(ctx context.Context, query string) string {
+	id := FromContext(ctx)
+	if id == "" || strings.HasPrefix(query, "/*") {
+		return query
+	}
+	// Valid IDs cannot contain "*/", so the comment cannot be closed early
+	return "/* request_id=" + id + " */ " + query
+}
+
+// Transport is an http.RoundTripper that forwards the request ID from the
+// outgoing request's context
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	id := FromContext(req.Context())
+	if id == "" || req.Header.Get(Header) != "" {
+		return base.RoundTrip(req)
+	}
+
+	// RoundTrippers must not modify the caller's request
+	clone := req.Clone(req.Context())
+	clone.Header.Set(Header, id)
+	return base.RoundTrip(clone)
+}
@@ -0,0 +1,144 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// chunkSize is how much is sent per INSTREAM chunk. clamd's StreamMaxLength
+// limits the total, not the chunk.
+const chunkSize = 64 << 10
+
+// ClamAV scans content with a clamd daemon using its INSTREAM command
+type ClamAV struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamAV creates a scanner for the clamd at address, tcp://host:port or
+// unix:///path/to/clamd.sock. Each command must finish within timeout.
+func NewClamAV(address string, timeout time.Duration) (*ClamAV, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ClamAV address %q: %w", address, err)
+	}
+	switch {
+	case u.Scheme == "tcp" && u.Host != "":
+		return &ClamAV{network: "tcp", address: u.Host, timeout: timeout}, nil
+	case u.Scheme == "unix" && u.Path != "":
+		return &ClamAV{network: "unix", address: u.Path, timeout: timeout}, nil
+	}
+	return nil, fmt.Errorf("invalid ClamAV address %q: expected tcp://host:port or unix:///path", address)
+}
+
+// Ping implements Scanner
+func (c *ClamAV) Ping(ctx context.Context) error {
+	reply, err := c.command(ctx, "PING", nil)
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("unexpected ClamAV reply to PING: %s", reply)
+	}
+	return nil
+}
+
+// Scan implements Scanner
+func (c *ClamAV) Scan(ctx context.Context, r io.Reader) (Result, error) {
+	reply, err := c.command(ctx, "INSTREAM", func(w io.Writer) error {
+		buf := make([]byte, chunkSize+4)
+		for {
+			n, err := io.ReadFull(r, buf[4:])
+			if n > 0 {
+				binary.BigEndian.PutUint32(buf, uint32(n))
+				if _, err := w.Write(buf[:4+n]); err != nil {
+					return err
+				}
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+		}
+		// A zero-length chunk ends the stream
+		_, err := w.Write([]byte{0, 0, 0, 0})
+		return err
+	})
+	if err != nil {
+		return Result{}, err
+	}
+
+	verdict := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case verdict == "OK":
+		return Result{}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return Result{Infected: true, Threat: strings.TrimSuffix(verdict, " FOUND")}, nil
+	}
+	return Result{}, fmt.Errorf("ClamAV scan failed: %s", verdict)
+}
+
+// command sends a null-terminated clamd command, streams its body if any,
+// and returns the reply without its terminator
+func (c *ClamAV) command(ctx context.Context, name string, body func(io.Writer) error) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to ClamAV: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	w := bufio.NewWriterSize(conn, chunkSize+4)
+	_, err = w.WriteString("z" + name + "\x00")
+	if err == nil && body != nil {
+		err = body(w)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+
+	// clamd answers early and hangs up when it rejects a stream, for
+	// example once StreamMaxLength is exceeded, so its reply is read even
+	// if sending failed. Closing our side first keeps clamd from waiting
+	// for the rest of a stream we could not read.
+	if err != nil {
+		if hc, ok := conn.(interface{ CloseWrite() error }); ok {
+			hc.CloseWrite()
+		}
+	}
+	reply, readErr := bufio.NewReader(conn).ReadString(0)
+	reply = strings.TrimSpace(strings.TrimSuffix(reply, "\x00"))
+	if readErr != nil && !(errors.Is(readErr, io.EOF) && reply != "") {
+		switch {
+		case ctx.Err() != nil:
+			err = ctx.Err()
+		case errors.Is(readErr, os.ErrDeadlineExceeded):
+			// The connection deadline can pass just before ctx notices
+			err = context.DeadlineExceeded
+		case err == nil:
+			err = readErr
+		}
+		return "", fmt.Errorf("ClamAV %s failed: %w", name, err)
+	}
+	return reply, nil
+}
@@ -0,0 +1,308 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// handler serves one clamd connection after its command has been read
+type handler func(f *fakeClamd, conn net.Conn, r *bufio.Reader, command string)
+
+// fakeClamd is a clamd stand-in recording the commands and INSTREAM chunks
+// it receives
+type fakeClamd struct {
+	ln     net.Listener
+	handle handler
+
+	mu       sync.Mutex
+	commands []string
+	chunks   []int
+	received []byte
+}
+
+// newFakeClamd listens on network and returns a scanner connected to it
+func newFakeClamd(t *testing.T, network string, timeout time.Duration, handle handler) (*fakeClamd, *ClamAV) {
+	t.Helper()
+	address := "127.0.0.1:0"
+	if network == "unix" {
+		address = filepath.Join(t.TempDir(), "clamd.sock")
+	}
+	ln, err := net.Listen(network, address)
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	f := &fakeClamd{ln: ln, handle: handle}
+	t.Cleanup(func() { ln.Close() })
+	go f.serve()
+
+	url := "tcp://" + ln.Addr().String()
+	if network == "unix" {
+		url = "unix://" + address
+	}
+	c, err := NewClamAV(url, timeout)
+	if err != nil {
+		t.Fatalf("NewClamAV(%q) error = %v", url, err)
+	}
+	return f, c
+}
+
+func (f *fakeClamd) serve() {
+	for {
+		conn, err := f.ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			r := bufio.NewReader(conn)
+			command, err := r.ReadString(0)
+			if err != nil {
+				return
+			}
+			command = strings.TrimSuffix(command, "\x00")
+			f.mu.Lock()
+			f.commands = append(f.commands, command)
+			f.mu.Unlock()
+			f.handle(f, conn, r, command)
+		}()
+	}
+}
+
+// readChunk reads one INSTREAM chunk, recording it, and reports whether it
+// was the terminating zero-length chunk
+func (f *fakeClamd) readChunk(r *bufio.Reader) (n int, last bool, err error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return 0, false, err
+	}
+	n = int(binary.BigEndian.Uint32(size[:]))
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return 0, false, err
+	}
+	f.mu.Lock()
+	f.chunks = append(f.chunks, n)
+	f.received = append(f.received, data...)
+	f.mu.Unlock()
+	return n, n == 0, nil
+}
+
+// recorded returns the commands and chunk sizes received so far
+func (f *fakeClamd) recorded() ([]string, []int, []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.commands, f.chunks, f.received
+}
+
+// replyAfterStream answers PING, and INSTREAM with reply once the whole
+// stream has been read
+func replyAfterStream(reply string) handler {
+	return func(f *fakeClamd, conn net.Conn, r *bufio.Reader, command string) {
+		switch command {
+		case "zPING":
+			conn.Write([]byte("PONG\x00"))
+		case "zINSTREAM":
+			for {
+				_, last, err := f.readChunk(r)
+				if err != nil {
+					return
+				}
+				if last {
+					break
+				}
+			}
+			conn.Write([]byte(reply + "\x00"))
+		default:
+			conn.Write([]byte("UNKNOWN COMMAND\x00"))
+		}
+	}
+}
+
+func TestNewClamAV(t *testing.T) {
+	tests := []struct {
+		address string
+		network string
+		target  string
+	}{
+		{"tcp://clamav:3310", "tcp", "clamav:3310"},
+		{"unix:///var/run/clamav/clamd.sock", "unix", "/var/run/clamav/clamd.sock"},
+	}
+	for _, tt := range tests {
+		c, err := NewClamAV(tt.address, time.Second)
+		if err != nil || c.network != tt.network || c.address != tt.target {
+			t.Errorf("NewClamAV(%q) = %+v, %v, want %s %s", tt.address, c, err, tt.network, tt.target)
+		}
+	}
+
+	for _, address := range []string{"clamav:3310", "tcp://", "unix://", "http://clamav:3310", "%zz"} {
+		if _, err := NewClamAV(address, time.Second); err == nil {
+			t.Errorf("NewClamAV(%q) succeeded, want an error", address)
+		}
+	}
+}
+
+func TestPing(t *testing.T) {
+	for _, network := range []string{"tcp", "unix"} {
+		fake, c := newFakeClamd(t, network, time.Second, replyAfterStream("stream: OK"))
+
+		if err := c.Ping(context.Background()); err != nil {
+			t.Errorf("Ping() over %s error = %v", network, err)
+		}
+		if commands, _, _ := fake.recorded(); len(commands) != 1 || commands[0] != "zPING" {
+			t.Errorf("clamd received %q, want [zPING]", commands)
+		}
+	}
+}
+
+func TestPingUnexpectedReply(t *testing.T) {
+	_, c := newFakeClamd(t, "tcp", time.Second, func(_ *fakeClamd, conn net.Conn, _ *bufio.Reader, _ string) {
+		conn.Write([]byte("PANG\x00"))
+	})
+	if err := c.Ping(context.Background()); err == nil || !strings.Contains(err.Error(), "PANG") {
+		t.Errorf("Ping() error = %v, want the unexpected reply", err)
+	}
+}
+
+func TestPingUnreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	address := ln.Addr().String()
+	ln.Close()
+
+	c, _ := NewClamAV("tcp://"+address, time.Second)
+	if err := c.Ping(context.Background()); err == nil {
+		t.Error("Ping() of a closed port succeeded")
+	}
+}
+
+func TestScanVerdicts(t *testing.T) {
+	tests := []struct {
+		reply   string
+		want    Result
+		wantErr string
+	}{
+		{reply: "stream: OK", want: Result{}},
+		{reply: "stream: Eicar-Test-Signature FOUND", want: Result{Infected: true, Threat: "Eicar-Test-Signature"}},
+		{reply: "stream: Win.Test.EICAR_HDB-1 FOUND\n", want: Result{Infected: true, Threat: "Win.Test.EICAR_HDB-1"}},
+		{reply: "stream: Can't allocate memory ERROR", wantErr: "Can't allocate memory ERROR"},
+		{reply: "UNKNOWN COMMAND", wantErr: "UNKNOWN COMMAND"},
+	}
+	for _, tt := range tests {
+		_, c := newFakeClamd(t, "tcp", time.Second, replyAfterStream(tt.reply))
+
+		got, err := c.Scan(context.Background(), strings.NewReader("X5O!P%@AP[4\\PZX54(P^)7CC)7}$EICAR"))
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Scan() with reply %q error = %v, want %q", tt.reply, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Scan() with reply %q = %+v, %v, want %+v", tt.reply, got, err, tt.want)
+		}
+	}
+}
+
+func TestScanChunkFraming(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		chunks []int
+	}{
+		{"empty", 0, []int{0}},
+		{"small", 10, []int{10, 0}},
+		{"exact chunk", chunkSize, []int{chunkSize, 0}},
+		{"several chunks", 2*chunkSize + 10, []int{chunkSize, chunkSize, 10, 0}},
+	}
+	for _, tt := range tests {
+		fake, c := newFakeClamd(t, "tcp", time.Second, replyAfterStream("stream: OK"))
+
+		data := bytes.Repeat([]byte("0123456789abcdef"), tt.size/16+1)[:tt.size]
+		if _, err := c.Scan(context.Background(), bytes.NewReader(data)); err != nil {
+			t.Fatalf("Scan(%s) error = %v", tt.name, err)
+		}
+
+		commands, chunks, received := fake.recorded()
+		if len(commands) != 1 || commands[0] != "zINSTREAM" {
+			t.Errorf("Scan(%s) sent %q, want [zINSTREAM]", tt.name, commands)
+		}
+		if !slices.Equal(chunks, tt.chunks) {
+			t.Errorf("Scan(%s) sent chunks %v, want %v", tt.name, chunks, tt.chunks)
+		}
+		if !bytes.Equal(received, data) {
+			t.Errorf("Scan(%s) sent %d bytes that differ from the input", tt.name, len(received))
+		}
+	}
+}
+
+// TestScanStreamLimit has clamd reject a stream larger than its
+// StreamMaxLength part way through and hang up, as the real daemon does
+func TestScanStreamLimit(t *testing.T) {
+	const streamMaxLength = 256 << 10
+	_, c := newFakeClamd(t, "tcp", 5*time.Second, func(f *fakeClamd, conn net.Conn, r *bufio.Reader, _ string) {
+		total := 0
+		for total <= streamMaxLength {
+			n, last, err := f.readChunk(r)
+			if err != nil || last {
+				return
+			}
+			total += n
+		}
+		conn.Write([]byte("INSTREAM size limit exceeded. ERROR\x00"))
+	})
+
+	// Far more than the socket buffers hold, so sending fails once clamd
+	// has hung up
+	_, err := c.Scan(context.Background(), io.LimitReader(zeroReader{}, 64<<20))
+	if err == nil || !strings.Contains(err.Error(), "size limit exceeded") {
+		t.Errorf("Scan() error = %v, want clamd's size limit reply", err)
+	}
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+// discard reads everything sent but never answers
+func discard(_ *fakeClamd, _ net.Conn, r *bufio.Reader, _ string) {
+	io.Copy(io.Discard, r)
+}
+
+func TestScanTimeout(t *testing.T) {
+	_, c := newFakeClamd(t, "tcp", 100*time.Millisecond, discard)
+
+	start := time.Now()
+	_, err := c.Scan(context.Background(), strings.NewReader("data"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Scan() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Scan() returned after %v, want about the 100ms timeout", elapsed)
+	}
+}
+
+func TestScanCanceled(t *testing.T) {
+	_, c := newFakeClamd(t, "tcp", time.Minute, discard)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if _, err := c.Scan(ctx, strings.NewReader("data")); !errors.Is(err, context.Canceled) {
+		t.Errorf("Scan() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,51 @@
+package scanner
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+)
+
+// Scanner checks uploaded content for malware
+type Scanner interface {
+	// Scan reads r to the end and reports whether it is infected. An error
+	// means no verdict was reached.
+	Scan(ctx context.Context, r io.Reader) (Result, error)
+	// Ping checks that the scanner is reachable
+	Ping(ctx context.Context) error
+}
+
+// Result is a scan verdict
+type Result struct {
+	Infected bool
+	// Threat names the signature that matched
+	Threat string
+}
+
+// Config locates a ClamAV daemon
+type Config struct {
+	// Address is tcp://host:port or unix:///path/to/clamd.sock. Scanning is
+	// disabled when it is empty.
+	Address string
+	Timeout time.Duration
+}
+
+// ConfigFromEnv builds a config from CLAMAV_ADDRESS and CLAMAV_TIMEOUT (a
+// duration, 30 seconds by default)
+func ConfigFromEnv() Config {
+	cfg := Config{Address: os.Getenv("CLAMAV_ADDRESS"), Timeout: 30 * time.Second}
+	if timeout, err := time.ParseDuration(os.Getenv("CLAMAV_TIMEOUT")); err == nil && timeout > 0 {
+		cfg.Timeout = timeout
+	}
+	return cfg
+}
+
+// New creates the scanner cfg describes, or returns nil when scanning is
+// disabled
+func New(cfg Config) (Scanner, error) {
+	if cfg.Address == "" {
+		return nil, nil
+	}
+	return NewClamAV(cfg.Address, cfg.Timeout)
+}
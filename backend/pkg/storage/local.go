@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalStorage stores objects as files under a root directory. Its signed
+// URLs point at DownloadURL and are checked with Verify by the handler that
+// serves them.
+type LocalStorage struct {
+	root        string
+	downloadURL string
+	secret      []byte
+}
+
+// NewLocalStorage creates a local backend rooted at root, creating the
+// directory if needed
+func NewLocalStorage(root, downloadURL string, secret []byte) (*LocalStorage, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("local storage requires a URL signing secret")
+	}
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &LocalStorage{root: root, downloadURL: strings.TrimSuffix(downloadURL, "/"), secret: secret}, nil
+}
+
+func (l *LocalStorage) path(key string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(l.root, filepath.FromSlash(key)), nil
+}
+
+// Put implements Storage. The object is written to a temporary file and
+// renamed into place so readers never see a partial write.
+func (l *LocalStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	dst, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, r)
+	if err == nil && written != size {
+		err = fmt.Errorf("wrote %d bytes, expected %d", written, size)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+// Open implements Storage
+func (l *LocalStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	return l.OpenFile(key)
+}
+
+// OpenFile opens the file stored under key, for serving with http.ServeContent
+func (l *LocalStorage) OpenFile(key string) (*os.File, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// Exists implements Storage
+func (l *LocalStorage) Exists(ctx context.Context, key string) (bool, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Delete implements Storage
+func (l *LocalStorage) Delete(ctx context.Context, key string) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// SignedURL implements Storage. The URL carries the expiry and response
+// headers, all covered by an HMAC of the key.
+func (l *LocalStorage) SignedURL(ctx context.Context, key string, expiry time.Duration, opts URLOptions) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+
+	q := url.Values{}
+	q.Set("expires", expires)
+	if opts.Filename != "" {
+		q.Set("name", opts.Filename)
+	}
+	if opts.ContentType != "" {
+		q.Set("type", opts.ContentType)
+	}
+	q.Set("signature", l.sign(key, expires, opts.Filename, opts.ContentType))
+	return l.downloadURL + "/" + key + "?" + q.Encode(), nil
+}
+
+// Verify checks a signed URL's key and query, returning the response
+// headers it was signed with
+func (l *LocalStorage) Verify(key string, q url.Values) (URLOptions, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return URLOptions{}, err
+	}
+	opts := URLOptions{Filename: q.Get("name"), ContentType: q.Get("type")}
+
+	expires := q.Get("expires")
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return URLOptions{}, ErrInvalidSignature
+	}
+	expected := l.sign(key, expires, opts.Filename, opts.ContentType)
+	if !hmac.Equal([]byte(expected), []byte(q.Get("signature"))) {
+		return URLOptions{}, ErrInvalidSignature
+	}
+	return opts, nil
+}
+
+func (l *LocalStorage) sign(key, expires, filename, contentType string) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(strings.Join([]string{key, expires, filename, contentType}, "\n")))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when no object is stored under a key
+var ErrNotFound = errors.New("object not found")
+
+// ErrInvalidKey is returned for keys that are empty or escape the store
+var ErrInvalidKey = errors.New("invalid object key")
+
+// ErrInvalidSignature is returned when a signed URL is forged or has expired
+var ErrInvalidSignature = errors.New("invalid or expired signature")
+
+// Storage stores uploaded file contents under slash-separated keys
+type Storage interface {
+	// Put stores size bytes read from r under key, replacing any existing object
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open returns the object stored under key, or ErrNotFound
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Exists reports whether an object is stored under key
+	Exists(ctx context.Context, key string) (bool, error)
+	// Delete removes the object under key. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL that downloads the object under key until expiry
+	SignedURL(ctx context.Context, key string, expiry time.Duration, opts URLOptions) (string, error)
+}
+
+// URLOptions sets the response headers of a signed download
+type URLOptions struct {
+	// Filename suggested to the browser, sent as an attachment
+	Filename    string
+	ContentType string
+}
+
+// ContentDisposition returns an attachment Content-Disposition header for
+// Filename, or "" when there is none
+func (o URLOptions) ContentDisposition() string {
+	if o.Filename == "" {
+		return ""
+	}
+	return fmt.Sprintf("attachment; filename=%q", strings.Map(func(r rune) rune {
+		if r < 0x20 || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, o.Filename))
+}
+
+// cleanKey validates key and returns it in canonical form
+func cleanKey(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+	cleaned := path.Clean(key)
+	if cleaned != key || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", ErrInvalidKey
+	}
+	return cleaned, nil
+}
+
+// Config selects and configures a storage backend
+type Config struct {
+	// Backend is "local" or "s3"
+	Backend string
+
+	// LocalPath is the root directory of the local backend
+	LocalPath string
+	// DownloadURL is where the local backend's signed URLs are served
+	DownloadURL string
+	// URLSecret signs the local backend's download URLs
+	URLSecret string
+
+	S3 S3Config
+}
+
+// ConfigFromEnv builds a config from STORAGE_BACKEND, FILE_STORAGE_PATH,
+// FILE_DOWNLOAD_URL, FILE_URL_SECRET (falling back to JWT_SECRET) and, for
+// S3, S3_ENDPOINT, S3_REGION, S3_BUCKET, S3_ACCESS_KEY_ID,
+// S3_SECRET_ACCESS_KEY and S3_USE_PATH_STYLE
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Backend:     os.Getenv("STORAGE_BACKEND"),
+		LocalPath:   os.Getenv("FILE_STORAGE_PATH"),
+		DownloadURL: os.Getenv("FILE_DOWNLOAD_URL"),
+		URLSecret:   os.Getenv("FILE_URL_SECRET"),
+		S3: S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		},
+	}
+	if cfg.Backend == "" {
+		cfg.Backend = "local"
+	}
+	if cfg.LocalPath == "" {
+		cfg.LocalPath = "./uploads"
+	}
+	if cfg.DownloadURL == "" {
+		cfg.DownloadURL = "/api/v1/files/download"
+	}
+	if cfg.URLSecret == "" {
+		cfg.URLSecret = os.Getenv("JWT_SECRET")
+	}
+	cfg.S3.UsePathStyle, _ = strconv.ParseBool(os.Getenv("S3_USE_PATH_STYLE"))
+	return cfg
+}
+
+// New creates the backend selected by cfg
+func New(cfg Config) (Storage, error) {
+	switch cfg.Backend {
+	case "local":
+		return NewLocalStorage(cfg.LocalPath, cfg.DownloadURL, []byte(cfg.URLSecret))
+	case "s3":
+		return NewS3Storage(cfg.S3)
+	}
+	return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+}
@@ -0,0 +1,109 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Config configures trace export
+type Config struct {
+	ServiceName    string
+	ServiceVersion string
+	Environment    string
+	// Endpoint is the OTLP/HTTP collector address, e.g. "localhost:4318".
+	// Tracing is disabled when it is empty.
+	Endpoint string
+	Insecure bool
+	// SampleRatio is the fraction of new traces that are recorded. Requests
+	// arriving with a sampled parent are always recorded.
+	SampleRatio float64
+}
+
+// ConfigFromEnv reads the standard OTEL_* variables plus ENVIRONMENT
+func ConfigFromEnv() Config {
+	config := Config{
+		ServiceName:    os.Getenv("OTEL_SERVICE_NAME"),
+		ServiceVersion: os.Getenv("SERVICE_VERSION"),
+		Environment:    os.Getenv("ENVIRONMENT"),
+		Endpoint:       os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		SampleRatio:    1,
+	}
+	if config.ServiceName == "" {
+		config.ServiceName = "homegenie-backend"
+	}
+	if ratio, err := strconv.ParseFloat(os.Getenv("OTEL_TRACES_SAMPLER_ARG"), 64); err == nil {
+		config.SampleRatio = ratio
+	}
+	config.Insecure, _ = strconv.ParseBool(os.Getenv("OTEL_EXPORTER_OTLP_INSECURE"))
+
+	// Accept full URLs as well as host:port
+	if strings.HasPrefix(config.Endpoint, "http://") {
+		config.Endpoint = strings.TrimPrefix(config.Endpoint, "http://")
+		config.Insecure = true
+	}
+	config.Endpoint = strings.TrimPrefix(config.Endpoint, "https://")
+	return config
+}
+
+// Setup installs a global tracer provider exporting over OTLP/HTTP and W3C
+// trace context propagation. The returned function flushes and stops the
+// exporter and must be called on shutdown.
+func Setup(ctx context.Context, config Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if config.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(config.Endpoint)}
+	if config.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	provider, err := NewTracerProvider(config, sdktrace.WithBatcher(exporter))
+	if err != nil {
+		return nil, err
+	}
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// NewTracerProvider builds a provider with the service resource and sampler
+// from config. Exporters are attached through opts, e.g.
+// sdktrace.WithSyncer(tracetest.NewInMemoryExporter()) in tests.
+func NewTracerProvider(config Config, opts ...sdktrace.TracerProviderOption) (*sdktrace.TracerProvider, error) {
+	// Schemaless so merging never conflicts with the SDK's own schema version
+	attrs := resource.NewSchemaless(
+		semconv.ServiceName(config.ServiceName),
+		semconv.ServiceVersion(config.ServiceVersion),
+		semconv.DeploymentEnvironment(config.Environment),
+	)
+	res, err := resource.Merge(resource.Default(), attrs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	opts = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	}, opts...)
+	return sdktrace.NewTracerProvider(opts...), nil
+}
@@ -0,0 +1,227 @@
+package tracing_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+	"github.com/myideascope/HomeGenie/backend/pkg/middleware"
+	"github.com/myideascope/HomeGenie/backend/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fakeDriver records the SQL it receives and answers every query with a
+// single row holding 1
+type fakeDriver struct {
+	mu      sync.Mutex
+	queries []string
+}
+
+var recorder = &fakeDriver{}
+
+func init() {
+	sql.Register("tracing-fake", recorder)
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{d}, nil }
+
+func (d *fakeDriver) reset() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	queries := d.queries
+	d.queries = nil
+	return queries
+}
+
+type fakeConn struct{ d *fakeDriver }
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.d.mu.Lock()
+	c.d.queries = append(c.d.queries, query)
+	c.d.mu.Unlock()
+	return &fakeRows{}, nil
+}
+
+type fakeRows struct{ done bool }
+
+func (*fakeRows) Columns() []string { return []string{"n"} }
+func (*fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+// setup installs an in-memory exporter as the global tracer provider and
+// returns a router tracing requests that query db
+func setup(t *testing.T) (*gin.Engine, *tracetest.InMemoryExporter) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	shutdown, err := tracing.Setup(context.Background(), tracing.Config{})
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	t.Cleanup(func() { shutdown(context.Background()) })
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider, err := tracing.NewTracerProvider(tracing.Config{ServiceName: "test", SampleRatio: 1},
+		sdktrace.WithSyncer(exporter))
+	if err != nil {
+		t.Fatalf("NewTracerProvider() error = %v", err)
+	}
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	db, err := database.Open("tracing-fake", "")
+	if err != nil {
+		t.Fatalf("database.Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	recorder.reset()
+
+	router := gin.New()
+	router.Use(middleware.RequestID(), middleware.Tracing())
+	router.GET("/tasks/:id", func(c *gin.Context) {
+		var n int
+		if err := db.QueryRowContext(c.Request.Context(), "SELECT 1").Scan(&n); err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	return router, exporter
+}
+
+func attr(span tracetest.SpanStub, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestServerAndDatabaseSpans(t *testing.T) {
+	router, exporter := setup(t)
+
+	const (
+		traceID  = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentID = "00f067aa0ba902b7"
+	)
+	req := httptest.NewRequest(http.MethodGet, "/tasks/42", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+parentID+"-01")
+	req.Header.Set("X-Request-ID", "req-123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want 2: %+v", len(spans), spans)
+	}
+	// The database span ends first
+	dbSpan, server := spans[0], spans[1]
+
+	if server.Name != "GET /tasks/:id" {
+		t.Errorf("server span name = %q, want %q", server.Name, "GET /tasks/:id")
+	}
+	if server.SpanKind != trace.SpanKindServer {
+		t.Errorf("server span kind = %v, want server", server.SpanKind)
+	}
+	if v, ok := attr(server, "http.route"); !ok || v.AsString() != "/tasks/:id" {
+		t.Errorf("http.route = %q, want /tasks/:id", v.AsString())
+	}
+	if v, ok := attr(server, "http.response.status_code"); !ok || v.AsInt64() != http.StatusOK {
+		t.Errorf("http.response.status_code = %v, want 200", v.AsInt64())
+	}
+	if got := server.SpanContext.TraceID().String(); got != traceID {
+		t.Errorf("server trace ID = %s, want the traceparent's %s", got, traceID)
+	}
+	if got := server.Parent.SpanID().String(); got != parentID || !server.Parent.IsRemote() {
+		t.Errorf("server parent = %s (remote %v), want remote %s", got, server.Parent.IsRemote(), parentID)
+	}
+
+	if dbSpan.Name != "SELECT" || dbSpan.SpanKind != trace.SpanKindClient {
+		t.Errorf("database span = %q (%v), want client span SELECT", dbSpan.Name, dbSpan.SpanKind)
+	}
+	if dbSpan.Parent.SpanID() != server.SpanContext.SpanID() {
+		t.Errorf("database span parent = %s, want server span %s", dbSpan.Parent.SpanID(), server.SpanContext.SpanID())
+	}
+	if dbSpan.SpanContext.TraceID() != server.SpanContext.TraceID() {
+		t.Error("database span is in a different trace")
+	}
+	if v, _ := attr(dbSpan, "db.query.text"); v.AsString() != "SELECT 1" {
+		t.Errorf("db.query.text = %q, want %q", v.AsString(), "SELECT 1")
+	}
+
+	queries := recorder.reset()
+	want := "/* request_id=req-123 */ SELECT 1"
+	if len(queries) != 1 || queries[0] != want {
+		t.Errorf("driver received %q, want [%q]", queries, want)
+	}
+}
+
+func TestUnmatchedRouteSpan(t *testing.T) {
+	router, exporter := setup(t)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("exported %d spans, want 1", len(spans))
+	}
+	if spans[0].Name != "GET" {
+		t.Errorf("span name = %q, want the method alone", spans[0].Name)
+	}
+	if _, ok := attr(spans[0], "http.route"); ok {
+		t.Error("unmatched request has an http.route attribute")
+	}
+	if spans[0].Parent.IsValid() {
+		t.Error("request without traceparent has a parent span")
+	}
+}
+
+func TestQueriesOutsideSpansAreNotTraced(t *testing.T) {
+	_, exporter := setup(t)
+
+	db, err := database.Open("tracing-fake", "")
+	if err != nil {
+		t.Fatalf("database.Open() error = %v", err)
+	}
+	defer db.Close()
+
+	var n int
+	if err := db.QueryRowContext(context.Background(), "SELECT 1").Scan(&n); err != nil {
+		t.Fatalf("query error = %v", err)
+	}
+	if spans := exporter.GetSpans(); len(spans) != 0 {
+		t.Errorf("exported %d spans for a query without a parent span, want 0", len(spans))
+	}
+	if queries := recorder.reset(); len(queries) != 1 || queries[0] != "SELECT 1" {
+		t.Errorf("driver received %q, want the query without a comment", queries)
+	}
+}
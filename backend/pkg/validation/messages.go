@@ -0,0 +1,92 @@
+package validation
+
+import (
+	"strings"
+)
+
+// DefaultLocale is used when the client asks for no supported language
+const DefaultLocale = "en"
+
+// messages holds validation messages per locale and rule. {field} and
+// {param} are replaced with the field name and the rule's parameter.
+var messages = map[string]map[string]string{
+	"en": {
+		"invalid":        "Request validation failed",
+		"malformed":      "Request body is not valid JSON",
+		"required":       "{field} is required",
+		"email":          "{field} must be a valid email address",
+		"url":            "{field} must be a valid URL",
+		"oneof":          "{field} must be one of: {param}",
+		"min":            "{field} must be at least {param}",
+		"max":            "{field} must be at most {param}",
+		"len":            "{field} must be exactly {param}",
+		"min_length":     "{field} must be at least {param} characters long",
+		"max_length":     "{field} must be at most {param} characters long",
+		"len_length":     "{field} must be exactly {param} characters long",
+		"gt":             "{field} must be greater than {param}",
+		"gte":            "{field} must be greater than or equal to {param}",
+		"lt":             "{field} must be less than {param}",
+		"lte":            "{field} must be less than or equal to {param}",
+		"type":           "{field} must be of type {param}",
+		"datetime":       "{field} must match the format {param}",
+		"currency":       "{field} must be an ISO 4217 currency code; codes with three or four decimal places or no minor unit, such as KWD or XAU, are not supported",
+		"currency_units": "{field} must be a whole amount in a currency without decimal places, such as JPY",
+		"cost_total":     "{field} must equal the total of lineItems",
+		"default":        "{field} is invalid",
+	},
+	"es": {
+		"invalid":        "La validación de la solicitud falló",
+		"malformed":      "El cuerpo de la solicitud no es JSON válido",
+		"required":       "{field} es obligatorio",
+		"email":          "{field} debe ser un correo electrónico válido",
+		"url":            "{field} debe ser una URL válida",
+		"oneof":          "{field} debe ser uno de: {param}",
+		"min":            "{field} debe ser al menos {param}",
+		"max":            "{field} debe ser como máximo {param}",
+		"len":            "{field} debe ser exactamente {param}",
+		"min_length":     "{field} debe tener al menos {param} caracteres",
+		"max_length":     "{field} debe tener como máximo {param} caracteres",
+		"len_length":     "{field} debe tener exactamente {param} caracteres",
+		"gt":             "{field} debe ser mayor que {param}",
+		"gte":            "{field} debe ser mayor o igual que {param}",
+		"lt":             "{field} debe ser menor que {param}",
+		"lte":            "{field} debe ser menor o igual que {param}",
+		"type":           "{field} debe ser de tipo {param}",
+		"datetime":       "{field} debe tener el formato {param}",
+		"currency":       "{field} debe ser un código de moneda ISO 4217; no se admiten los códigos con tres o cuatro decimales o sin unidad menor, como KWD o XAU",
+		"currency_units": "{field} debe ser un importe entero en una moneda sin decimales, como JPY",
+		"cost_total":     "{field} debe ser igual al total de lineItems",
+		"default":        "{field} no es válido",
+	},
+}
+
+// Message returns the message for rule in locale, falling back to the
+// default locale and then to the generic message
+func Message(locale, rule, field, param string) string {
+	catalog, ok := messages[locale]
+	if !ok {
+		catalog = messages[DefaultLocale]
+	}
+	template, ok := catalog[rule]
+	if !ok {
+		template, ok = messages[DefaultLocale][rule]
+	}
+	if !ok {
+		template = catalog["default"]
+	}
+	return strings.NewReplacer("{field}", field, "{param}", param).Replace(template)
+}
+
+// Locale picks the first supported language from an Accept-Language header,
+// e.g. "es-MX,es;q=0.9,en;q=0.8" -> "es". Quality values are not weighed;
+// clients list languages in preference order in practice.
+func Locale(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		lang := strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+		if _, ok := messages[lang]; ok {
+			return lang
+		}
+	}
+	return DefaultLocale
+}
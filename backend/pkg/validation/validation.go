@@ -0,0 +1,203 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+)
+
+// FieldError describes one invalid field in a request
+type FieldError struct {
+	// Field is the JSON (or query) name, with a dotted path for nested fields
+	Field string `json:"field"`
+	// Rule is the binding tag that failed, e.g. "required" or "task_status"
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		if err := Register(v); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Register installs field naming and the enum, cost_total, currency and
+// currency_units validators on v. It is run against gin's validator when this package is
+// imported.
+func Register(v *validator.Validate) error {
+	v.RegisterTagNameFunc(fieldName)
+
+	for tag, values := range database.Enums {
+		allowed := values
+		err := v.RegisterValidation(tag, func(fl validator.FieldLevel) bool {
+			value := fl.Field().String()
+			for _, a := range allowed {
+				if value == a {
+					return true
+				}
+			}
+			return false
+		})
+		if err != nil {
+			return fmt.Errorf("failed to register %s validator: %w", tag, err)
+		}
+	}
+	if err := v.RegisterValidation("cost_total", costTotal); err != nil {
+		return fmt.Errorf("failed to register cost_total validator: %w", err)
+	}
+	err := v.RegisterValidation("currency", func(fl validator.FieldLevel) bool {
+		code := fl.Field().String()
+		return v.Var(code, "iso4217") == nil && database.SupportedCurrency(code)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register currency validator: %w", err)
+	}
+	if err := v.RegisterValidation("currency_units", currencyUnits); err != nil {
+		return fmt.Errorf("failed to register currency_units validator: %w", err)
+	}
+	return nil
+}
+
+// currencyUnits checks that an amount, or each of a list of line items, is
+// whole when its struct's Currency field names a currency without decimal
+// places
+func currencyUnits(fl validator.FieldLevel) bool {
+	parent := reflect.Indirect(fl.Parent())
+	if parent.Kind() != reflect.Struct {
+		return false
+	}
+	field := parent.FieldByName("Currency")
+	if !field.IsValid() {
+		return false
+	}
+	var code string
+	if field.Kind() == reflect.Pointer {
+		if !field.IsNil() {
+			code = field.Elem().String()
+		}
+	} else {
+		code = field.String()
+	}
+
+	switch value := fl.Field().Interface().(type) {
+	case database.Amount:
+		return value.FitsCurrency(code)
+	case []database.CostLineItemRequest:
+		for _, item := range value {
+			if !item.Amount.FitsCurrency(code) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// costTotal checks that a cost given alongside line items equals their
+// total. The cost's struct must have a LineItems field.
+func costTotal(fl validator.FieldLevel) bool {
+	parent := reflect.Indirect(fl.Parent())
+	if parent.Kind() != reflect.Struct {
+		return false
+	}
+	field := parent.FieldByName("LineItems")
+	if !field.IsValid() {
+		return false
+	}
+	items, _ := field.Interface().([]database.CostLineItemRequest)
+	if len(items) == 0 {
+		return true
+	}
+	var total database.Amount
+	for _, item := range items {
+		total += item.Amount
+	}
+	return fl.Field().Int() == int64(total)
+}
+
+// fieldName reports fields by their json tag, falling back to the form tag
+// used by query filters, so errors name fields the way clients send them
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form"} {
+		name := strings.SplitN(field.Tag.Get(key), ",", 2)[0]
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// Translate turns a binding error into per-field errors with messages in
+// locale. It reports false when err is not about specific fields, e.g.
+// malformed JSON.
+func Translate(err error, locale string) ([]FieldError, bool) {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, translateFieldError(fe, locale))
+		}
+		return fields, true
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		param := typeErr.Type.Kind().String()
+		return []FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Param:   param,
+			Message: Message(locale, "type", typeErr.Field, param),
+		}}, true
+	}
+
+	return nil, false
+}
+
+func translateFieldError(fe validator.FieldError, locale string) FieldError {
+	field := fe.Namespace()
+	// Drop the top-level struct name: "CreateTaskRequest.title" -> "title"
+	if i := strings.Index(field, "."); i >= 0 {
+		field = field[i+1:]
+	}
+
+	rule := fe.Tag()
+	param := fe.Param()
+	if values, ok := database.Enums[rule]; ok {
+		param = strings.Join(values, " ")
+	}
+
+	return FieldError{
+		Field:   field,
+		Rule:    rule,
+		Param:   param,
+		Message: fieldMessage(locale, fe, field, param),
+	}
+}
+
+// fieldMessage picks the message for fe, distinguishing length limits on
+// strings and slices from value limits on numbers
+func fieldMessage(locale string, fe validator.FieldError, field, param string) string {
+	rule := fe.Tag()
+	if _, ok := database.Enums[rule]; ok {
+		rule = "oneof"
+	}
+
+	switch rule {
+	case "min", "max", "len":
+		switch fe.Kind() {
+		case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+			rule += "_length"
+		}
+	}
+	return Message(locale, rule, field, param)
+}
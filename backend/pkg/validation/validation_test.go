@@ -0,0 +1,113 @@
+package validation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/myideascope/HomeGenie/backend/pkg/database"
+)
+
+func TestCurrency(t *testing.T) {
+	v := validator.New()
+	v.SetTagName("binding")
+	if err := Register(v); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"", true},
+		{"USD", true},
+		{"EUR", true},
+		{"MXN", true},
+		{"JPY", true},
+		{"KRW", true},
+		{"VND", true},
+		{"KWD", false},
+		{"CLF", false},
+		{"XAU", false},
+		{"usd", false},
+		{"ZZZ", false},
+	}
+	for _, tt := range tests {
+		budget := database.CreateBudgetRequest{
+			Year: 2026, Category: database.CostCategoryOther, Amount: 100, Currency: tt.code,
+		}
+		if err := v.Struct(budget); (err == nil) != tt.want {
+			t.Errorf("currency %q: error = %v, want valid %v", tt.code, err, tt.want)
+		}
+
+		// omitempty skips only a nil pointer, so a pointer to "" is checked
+		code := tt.code
+		if err := v.Struct(database.UpdateBudgetRequest{Currency: &code}); (err == nil) != (tt.want && code != "") {
+			t.Errorf("currency %q by pointer: error = %v, want valid %v", tt.code, err, tt.want && code != "")
+		}
+	}
+}
+
+func TestCurrencyUnits(t *testing.T) {
+	v := validator.New()
+	v.SetTagName("binding")
+	if err := Register(v); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	amount := func(a database.Amount) *database.Amount { return &a }
+	code := func(c string) *string { return &c }
+	maintenance := func(currency string, cost database.Amount, items ...database.Amount) database.CreateMaintenanceRecordRequest {
+		req := database.CreateMaintenanceRecordRequest{
+			Title: "Boiler service", Description: "Annual service", CompletedDate: time.Now(),
+			Cost: &cost, Currency: currency,
+		}
+		for _, a := range items {
+			req.LineItems = append(req.LineItems, database.CostLineItemRequest{Category: database.CostCategoryLabor, Amount: a})
+		}
+		return req
+	}
+
+	tests := []struct {
+		name  string
+		req   interface{}
+		field string
+	}{
+		{"whole yen budget", database.CreateBudgetRequest{
+			Year: 2026, Category: database.CostCategoryOther, Amount: 150000, Currency: "JPY"}, ""},
+		{"fractional yen budget", database.CreateBudgetRequest{
+			Year: 2026, Category: database.CostCategoryOther, Amount: 150050, Currency: "JPY"}, "amount"},
+		{"fractional dollar budget", database.CreateBudgetRequest{
+			Year: 2026, Category: database.CostCategoryOther, Amount: 150050}, ""},
+		{"fractional won update", database.UpdateBudgetRequest{Amount: amount(1050), Currency: code("KRW")}, "amount"},
+		{"fractional update without currency", database.UpdateBudgetRequest{Amount: amount(1050)}, ""},
+		{"whole yen cost", maintenance("JPY", 500000), ""},
+		{"fractional yen cost", maintenance("JPY", 500050), "cost"},
+		{"whole yen line items", maintenance("JPY", 500000, 300000, 200000), ""},
+		{"fractional yen line items", maintenance("JPY", 100, 50, 50), "lineItems"},
+		{"fractional euro line items", maintenance("EUR", 100, 50, 50), ""},
+	}
+	for _, tt := range tests {
+		err := v.Struct(tt.req)
+		if tt.field == "" {
+			if err != nil {
+				t.Errorf("%s: error = %v, want valid", tt.name, err)
+			}
+			continue
+		}
+		fields, _ := Translate(err, "en")
+		if len(fields) != 1 || fields[0].Field != tt.field || fields[0].Rule != "currency_units" {
+			t.Errorf("%s: errors = %+v, want currency_units on %s", tt.name, fields, tt.field)
+		}
+	}
+}
+
+func TestCurrencyMessage(t *testing.T) {
+	for _, locale := range []string{"en", "es"} {
+		for _, rule := range []string{"currency", "currency_units"} {
+			if msg := Message(locale, rule, "currency", ""); msg == Message(locale, "default", "currency", "") {
+				t.Errorf("no %s message for the %s rule", locale, rule)
+			}
+		}
+	}
+}
@@ -558,6 +558,7 @@ export class AnalyticsService {
     maintenanceCosts: Array<{
       propertyId: number;
       propertyName: string;
+      currency: string;
       totalCost: number;
       averageCost: number;
     }>;
@@ -643,6 +644,8 @@ export class ErrorHandler {
           return 'This action conflicts with existing data.';
         case 'RATE_LIMITED':
           return 'Too many requests. Please wait a moment and try again.';
+        case 'SERVICE_UNAVAILABLE':
+          return 'The service is temporarily unavailable. Please try again shortly.';
         default:
           return error.message;
       }